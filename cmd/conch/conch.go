@@ -9,6 +9,7 @@ package main
 import (
 	"os"
 
+	"github.com/jawher/mow.cli"
 	"github.com/joyent/conch-shell/pkg/cmd/conch1"
 	"github.com/joyent/conch-shell/pkg/commands/admin"
 	"github.com/joyent/conch-shell/pkg/commands/api"
@@ -19,6 +20,7 @@ import (
 	"github.com/joyent/conch-shell/pkg/commands/profile"
 	"github.com/joyent/conch-shell/pkg/commands/rack"
 	"github.com/joyent/conch-shell/pkg/commands/relay"
+	"github.com/joyent/conch-shell/pkg/commands/shell"
 	"github.com/joyent/conch-shell/pkg/commands/update"
 	"github.com/joyent/conch-shell/pkg/commands/user"
 	"github.com/joyent/conch-shell/pkg/commands/validation"
@@ -26,6 +28,17 @@ import (
 )
 
 func main() {
+	if (len(os.Args) > 1) && (os.Args[1] == shell.CompleteCommand) {
+		shell.Complete(newApp, os.Args[2:])
+		return
+	}
+
+	_ = newApp().Run(os.Args)
+}
+
+// newApp builds the app with all of its commands. The shell builds a fresh
+// one for each command it runs.
+func newApp() *cli.Cli {
 	app := conch1.Init()
 
 	api.Init(app)
@@ -41,6 +54,7 @@ func main() {
 	workspaces.Init(app)
 	validation.Init(app)
 	update.Init(app)
+	shell.Init(app, newApp)
 
-	_ = app.Run(os.Args)
+	return app
 }
@@ -8,8 +8,10 @@ package conch1
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/jawher/mow.cli"
 	"github.com/joyent/conch-shell/pkg/conch"
@@ -24,6 +26,12 @@ func Init() *cli.Cli {
 
 	app := cli.App("conch", "Command line interface for Conch")
 
+	// In the shell, a typo is no reason to quit. Nor is it for completion,
+	// which is bound to run into half-typed options.
+	if util.Interactive || util.Completing {
+		app.ErrorHandling = flag.ContinueOnError
+	}
+
 	app.Version("version", util.Version)
 
 	app.Command(
@@ -35,11 +43,13 @@ func Init() *cli.Cli {
 				fmt.Printf(
 					"Conch Shell v%s\n"+
 						"  Git Revision: %s\n"+
-						"  Requires API version: >= %s and < %s\n",
+						"  Requires API version: >= %s and < %s\n"+
+						"  Fully supports API version: >= %s\n",
 					util.Version,
 					util.GitRev,
-					conch.MinimumAPIVersion,
+					conch.OldestAPIVersion,
 					conch.BreakingAPIVersion,
+					conch.MinimumAPIVersion,
 				)
 				if util.DisableApiVersionCheck() {
 					fmt.Println("\n** API version checking is disabled. Functionality cannot be guaranteed **")
@@ -70,22 +80,80 @@ func Init() *cli.Cli {
 			EnvVar: "CONCH_URL",
 		})
 
-		useJSON         = app.BoolOpt("json j", false, "Output JSON")
+		outputFormat = app.String(cli.StringOpt{
+			Name:   "output o",
+			Value:  util.OutputTable,
+			Desc:   "Output format: " + strings.Join(util.OutputFormats, ", ") + ", template=TEMPLATE, or jsonpath=EXPRESSION",
+			EnvVar: "CONCH_OUTPUT",
+		})
+
+		tokenExpiryWarning = app.Int(cli.IntOpt{
+			Name:   "token-expiry-warning",
+			Value:  util.DefaultTokenExpiryWarning,
+			Desc:   "Warn when the profile's API token expires within this many days. 0 turns the warning off",
+			EnvVar: "CONCH_TOKEN_EXPIRY_WARNING",
+		})
+
+		useJSON         = app.BoolOpt("json j", false, "Output JSON. Same as --output json")
 		configFile      = app.StringOpt("config c", "~/.conch.json", "Path to config file")
 		noVersion       = app.BoolOpt("no-version-check", false, "Does nothing. Included for backwards compatibility.") // TODO(sungo): remove back compat
 		profileOverride = app.StringOpt("profile p", "", "Override the active profile")
 		debugMode       = app.BoolOpt("debug", false, "Debug mode")
 		traceMode       = app.BoolOpt("trace", false, "Trace http requests. Warning: this is super loud")
+		noCache         = app.BoolOpt("no-cache", false, "Do not use or update the cache of API responses")
+		columns         = app.StringOpt("columns", "", "Comma separated columns to list, like 'id,asset_tag,health'. Any field of the items listed works, with dots for nested ones")
+		sortBy          = app.StringOpt("sort-by", "", "Column or field to sort lists by")
+		sortDesc        = app.BoolOpt("desc", false, "Sort lists in descending order")
+		where           = app.StringsOpt("where", nil, "Only list items for which a condition like 'health=FAIL' holds. Operators are =, !=, <, <=, >, >=, and ~ for a regular expression. Can be repeated")
+		recordFile      = app.StringOpt("record", "", "Record every API request and response, with credentials redacted, to this file. Implies --no-cache")
+		replayFile      = app.StringOpt("replay", "", "Answer API requests from a file written by --record instead of the API. Implies --no-cache")
+
+		connectTimeout  = app.StringOpt("connect-timeout", "", "How long to wait for a connection to the API, like '10s'. Overrides the profile")
+		tlsTimeout      = app.StringOpt("tls-timeout", "", "How long to wait for the TLS handshake. Overrides the profile")
+		responseTimeout = app.StringOpt("response-timeout", "", "How long to wait for the API to start answering. Overrides the profile")
+		timeout         = app.StringOpt("timeout", "", "How long any one request may take, start to finish. Overrides the profile")
+		proxy           = app.StringOpt("proxy", "", "Proxy URL to use instead of HTTP_PROXY and HTTPS_PROXY. Overrides the profile")
+		noProxy         = app.StringsOpt("no-proxy", nil, "Host, domain, IP, or CIDR block to reach without the proxy. Can be repeated. Overrides the profile")
+		maxConns        = app.IntOpt("max-conns", 0, "Maximum number of connections to the API. Overrides the profile")
 	)
 
 	app.Before = func() {
+		format := *outputFormat
+		if *useJSON {
+			format = util.OutputJSON
+		}
+		if err := util.SetOutput(format); err != nil {
+			util.Bail(err)
+		}
+		if err := util.SetListOptions(*columns, *sortBy, *sortDesc, *where); err != nil {
+			util.Bail(err)
+		}
+
+		// The shell already did the rest when it started, and keeps it
+		// from one command to the next
+		if util.Interactive {
+			return
+		}
+
 		util.Debug = *debugMode
 		util.Trace = *traceMode
+		util.NoCache = *noCache
+		util.RecordPath = *recordFile
+		util.ReplayPath = *replayFile
+		util.TokenExpiryWarning = *tokenExpiryWarning
+
+		util.TransportFlags = config.TransportSettings{
+			ConnectTimeout:  *connectTimeout,
+			TLSTimeout:      *tlsTimeout,
+			ResponseTimeout: *responseTimeout,
+			Timeout:         *timeout,
+			Proxy:           *proxy,
+			NoProxy:         *noProxy,
+			MaxConnsPerHost: *maxConns,
+		}
 
-		if *useJSON {
-			util.JSON = true
-		} else {
-			util.JSON = false
+		if (util.RecordPath != "") && (util.ReplayPath != "") {
+			util.Bail(errors.New("--record and --replay cannot be used together"))
 		}
 
 		if *noVersion {
@@ -120,27 +188,11 @@ func Init() *cli.Cli {
 
 		cfg, _ := config.NewFromJSONFile(expandedPath)
 		cfg.Path = expandedPath
+		cfg.Secrets = util.SecretStores(expandedPath)
 		util.Config = cfg
 
-		for _, prof := range cfg.Profiles {
-			if *profileOverride != "" {
-				if prof.Name == *profileOverride {
-					util.ActiveProfile = prof
-					if prof.Token != "" {
-						util.Token = string(prof.Token)
-					}
-
-					break
-				}
-			} else if prof.Active {
-				util.ActiveProfile = prof
-				if prof.Token != "" {
-					util.Token = string(prof.Token)
-				}
-
-				break
-			}
-		}
+		util.ProfileOverride = *profileOverride
+		util.SelectProfile(*profileOverride)
 
 		if !util.IgnoreConfig {
 			if (*profileOverride != "") && (util.ActiveProfile == nil) {
@@ -151,7 +203,9 @@ func Init() *cli.Cli {
 		// There is no way to avoid the version check, save piping stderr to
 		// /dev/null.  The API is changing too much and introducing too much
 		// breakage on the regular for users to stick using old versions.
-		util.GithubReleaseCheck()
+		if !util.Completing {
+			util.GithubReleaseCheck()
+		}
 	}
 
 	return app
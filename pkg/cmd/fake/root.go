@@ -0,0 +1,124 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package fake
+
+import (
+	"fmt"
+
+	"github.com/joyent/conch-shell/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var (
+	rootCmd = &cobra.Command{
+		Use:     "conch-fake",
+		Version: util.Version,
+		Short:   "conch-fake serves a fake Conch API for local development",
+		Long: `
+conch-fake serves an in-memory fake of the Conch API on localhost. It keeps
+state, so racks, devices, and so on created through the API stick around
+until the process exits or the state is reset.
+
+The app looks for the config file 'conch_fake.yml' in /etc, /usr/local/etc,
+and '.'. Options can be provided via the environment (eg 'CONCH_FAKE_LISTEN')
+or on the command line.
+
+* The state can be seeded from a directory of JSON files: --fixtures. See the
+  docs for conchtest.Server.LoadFixtures for what goes in it.
+
+* POST /_fake/reload throws away the current state and loads the fixtures
+  again, so every run can start from the same place.
+
+* The server starts with an admin user. Point the shell at it with:
+
+    conch --env development --url http://127.0.0.1:5001 --token <token>
+
+  The token is printed at startup.
+
+`,
+		Run: serve,
+	}
+)
+
+// Root returns the root command
+func Root() *cobra.Command {
+	return rootCmd
+}
+
+// Execute gets this party started
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func init() {
+	initFlags()
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "version",
+		Short: "Display version information",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf(
+				"Conch %s - Fake API\n"+
+					"  Git Revision: %s\n",
+				util.Version,
+				util.GitRev,
+			)
+		},
+	})
+}
+
+func initFlags() {
+	flag.String(
+		"listen",
+		"127.0.0.1:5001",
+		"Address to listen on",
+	)
+
+	flag.String(
+		"fixtures",
+		"",
+		"A directory of JSON files to seed the fake with",
+	)
+
+	flag.Bool(
+		"debug",
+		false,
+		"Debug mode",
+	)
+
+	flag.Bool(
+		"verbose",
+		false,
+		"Verbose logging. Logs every request.",
+	)
+
+	viper.SetConfigName("conch_fake")
+	viper.AddConfigPath("/etc")
+	viper.AddConfigPath("/usr/local/etc")
+	viper.AddConfigPath(".")
+
+	viper.SetEnvPrefix("conch_fake")
+	viper.AutomaticEnv()
+
+	viper.BindPFlags(flag.CommandLine)
+	flag.Parse()
+
+	viper.ReadInConfig()
+
+	if viper.GetBool("debug") {
+		log.SetLevel(log.DebugLevel)
+	} else if viper.GetBool("verbose") {
+		log.SetLevel(log.InfoLevel)
+	} else {
+		log.SetLevel(log.WarnLevel)
+	}
+}
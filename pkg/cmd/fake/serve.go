@@ -0,0 +1,118 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package fake
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch/conchtest"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ReloadPath resets the fake and loads the fixtures again
+const ReloadPath = "/_fake/reload"
+
+func serve(cmd *cobra.Command, args []string) {
+	srv := conchtest.NewUnstartedServer()
+	if err := load(srv); err != nil {
+		log.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", viper.GetString("listen"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv.Listener.Close()
+	srv.Listener = l
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ReloadPath, reload(srv))
+	mux.Handle("/", logRequests(srv.Config.Handler))
+	srv.Config.Handler = mux
+
+	srv.Start()
+	defer srv.Close()
+
+	fmt.Printf(
+		"Fake Conch API %s listening on %s\n"+
+			"  Admin: %s / %s\n"+
+			"  Token: %s\n",
+		conchtest.Version,
+		srv.URL,
+		conchtest.AdminEmail,
+		conchtest.AdminPassword,
+		conchtest.AdminToken,
+	)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+	log.Info("Shutting down")
+}
+
+// load seeds the fake from the fixture directory, if there is one
+func load(srv *conchtest.Server) error {
+	dir := viper.GetString("fixtures")
+	if dir == "" {
+		return nil
+	}
+	log.Debug("Loading fixtures from '" + dir + "'")
+	return srv.LoadFixtures(dir)
+}
+
+func reload(srv *conchtest.Server) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		srv.Reset()
+		if err := load(srv); err != nil {
+			log.Warn(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Info("Reloaded fixtures")
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// statusRecorder remembers the status code a handler sent, for logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		log.WithFields(log.Fields{
+			"method":   r.Method,
+			"path":     r.URL.RequestURI(),
+			"status":   rec.status,
+			"duration": time.Since(start),
+		}).Info("request")
+	})
+}
@@ -97,7 +97,11 @@ func init() {
 }
 
 func buildAPI() {
-	API = &conch.Conch{BaseURL: viper.GetString("conch_api")}
+	API = conch.New(
+		conch.WithBaseURL(viper.GetString("conch_api")),
+		conch.WithDebug(viper.GetBool("debug")),
+		conch.WithTrace(viper.GetBool("trace")),
+	)
 	err := API.Login(
 		viper.GetString("conch_user"),
 		viper.GetString("conch_password"),
@@ -106,9 +110,6 @@ func buildAPI() {
 	if err != nil {
 		log.Fatalf("error logging into %s : %s", viper.GetString("conch_api"), err)
 	}
-	API.Debug = viper.GetBool("debug")
-	API.Trace = viper.GetBool("trace")
-
 }
 
 func initFlags() {
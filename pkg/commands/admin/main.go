@@ -26,13 +26,13 @@ func listAllUsers(app *cli.Cmd) {
 			util.Bail(err)
 		}
 		if util.JSON {
-			util.JSONOut(users)
+			util.Print(users)
 			return
 		}
 
 		sort.Sort(users)
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Name",
@@ -54,7 +54,7 @@ func listAllUsers(app *cli.Cmd) {
 				isAdmin = "X"
 			}
 
-			table.Append([]string{
+			table.AppendItem(u, []string{
 				u.ID.String(),
 				u.Name,
 				u.Email,
@@ -124,13 +124,13 @@ func listTokens(app *cli.Cmd) {
 			util.Bail(err)
 		}
 		if util.JSON {
-			util.JSONOut(tokens)
+			util.Print(tokens)
 			return
 		}
 
 		sort.Sort(tokens)
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{"Name", "Created", "Last Used"})
 
 		for _, t := range tokens {
@@ -139,7 +139,7 @@ func listTokens(app *cli.Cmd) {
 				timeStr = util.TimeStr(t.LastUsed)
 			}
 
-			table.Append([]string{
+			table.AppendItem(t, []string{
 				t.Name,
 				util.TimeStr(t.Created),
 				timeStr,
@@ -163,7 +163,7 @@ func getToken(cmd *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(token)
+			util.Print(token)
 			return
 		}
 
@@ -287,7 +287,7 @@ func getUser(app *cli.Cmd) {
 			util.Bail(err)
 		}
 		if util.JSON {
-			util.JSONOut(user)
+			util.Print(user)
 			return
 		}
 
@@ -7,9 +7,11 @@
 package devices
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -104,7 +106,7 @@ func getOne(app *cli.Cmd) {
 				if err != nil {
 					util.Bail(err)
 				}
-				util.JSONOut(d)
+				util.Print(d)
 				return
 			}
 
@@ -112,7 +114,7 @@ func getOne(app *cli.Cmd) {
 			if err != nil {
 				util.Bail(err)
 			}
-			util.JSONOut(d)
+			util.Print(d)
 			return
 		}
 
@@ -140,7 +142,7 @@ func getLocation(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(location)
+			util.Print(location)
 			return
 		}
 
@@ -189,7 +191,7 @@ func getSettings(app *cli.Cmd) {
 
 		if *keysOnly {
 			if util.JSON {
-				util.JSONOut(keys)
+				util.Print(keys)
 				return
 			}
 
@@ -200,7 +202,7 @@ func getSettings(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(settings)
+			util.Print(settings)
 			return
 		}
 
@@ -219,7 +221,7 @@ func getIPMI(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(map[string]string{"ipmi": ipmi})
+			util.Print(map[string]string{"ipmi": ipmi})
 		} else {
 			fmt.Println(ipmi)
 		}
@@ -235,7 +237,7 @@ func getSetting(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(map[string]string{DeviceSettingName: setting})
+			util.Print(map[string]string{DeviceSettingName: setting})
 		} else {
 			fmt.Println(setting)
 		}
@@ -366,7 +368,7 @@ func getTags(app *cli.Cmd) {
 
 		if *keysOnly {
 			if util.JSON {
-				util.JSONOut(keys)
+				util.Print(keys)
 				return
 			}
 
@@ -377,7 +379,7 @@ func getTags(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(settings)
+			util.Print(settings)
 			return
 		}
 
@@ -398,7 +400,7 @@ func getTag(app *cli.Cmd) {
 		tag := strings.TrimPrefix(DeviceTagName, "tag.")
 
 		if util.JSON {
-			util.JSONOut(map[string]string{tag: setting})
+			util.Print(map[string]string{tag: setting})
 		} else {
 			fmt.Println(setting)
 		}
@@ -441,7 +443,7 @@ func outputDevices(devices conch.Devices, idsOnly bool, fullOutput bool) {
 			for _, d := range devices {
 				ids = append(ids, d.ID)
 			}
-			util.JSONOut(ids)
+			util.Print(ids)
 			return
 		}
 		for _, d := range devices {
@@ -451,7 +453,23 @@ func outputDevices(devices conch.Devices, idsOnly bool, fullOutput bool) {
 	}
 
 	if fullOutput {
-		locs := make(map[uuid.UUID]conch.DeviceLocation)
+		// Devices in the same rack share a location, give or take the
+		// slot, so only one device per rack is looked up
+		rackDevice := make(map[uuid.UUID]string)
+		ids := make([]string, 0)
+
+		for _, d := range devices {
+			if uuid.Equal(d.RackID, uuid.UUID{}) {
+				continue
+			}
+			if _, ok := rackDevice[d.RackID]; !ok {
+				rackDevice[d.RackID] = d.ID
+				ids = append(ids, d.ID)
+			}
+		}
+
+		// Failed lookups just leave the location blank, as before
+		locs, _ := util.API.GetDeviceLocations(ids)
 
 		dLocs := make([]conch.Device, 0)
 
@@ -459,14 +477,9 @@ func outputDevices(devices conch.Devices, idsOnly bool, fullOutput bool) {
 			if uuid.Equal(d.RackID, uuid.UUID{}) {
 				continue
 			}
-			if loc, ok := locs[d.RackID]; ok {
+			if loc, ok := locs[rackDevice[d.RackID]]; ok {
+				loc.TargetHardwareProduct = conch.HardwareProductTarget{}
 				d.Location = loc
-			} else {
-				if loc, err := util.API.GetDeviceLocation(d.ID); err == nil {
-					loc.TargetHardwareProduct = conch.HardwareProductTarget{}
-					locs[loc.Rack.ID] = loc
-					d.Location = loc
-				}
 			}
 
 			dLocs = append(dLocs, d)
@@ -480,6 +493,104 @@ func outputDevices(devices conch.Devices, idsOnly bool, fullOutput bool) {
 
 }
 
+// searchDevices combines any number of filters into one search, e.g.
+// 'devices search --tag role=storage --health fail --phase production'
+func searchDevices(app *cli.Cmd) {
+	var (
+		hostname  = app.StringOpt("hostname", "", "Exact hostname")
+		mac       = app.StringOpt("mac", "", "MAC address of one of the device's NICs")
+		ipaddr    = app.StringOpt("ipaddr", "", "IP address of one of the device's NICs")
+		settings  = app.StringsOpt("setting", nil, "Exact setting value, as KEY=VALUE. Can be repeated")
+		tags      = app.StringsOpt("tag", nil, "Exact tag value, as KEY=VALUE. Can be repeated")
+		phase     = app.StringOpt("phase", "", "Device phase, like 'production'")
+		health    = app.StringOpt("health", "", "Device health, like 'fail'")
+		graduated = app.StringOpt("graduated", "", "'true' or 'false', for devices that have graduated, or have not")
+		validated = app.StringOpt("validated", "", "'true' or 'false', for devices that have been validated, or have not")
+		workspace = app.StringOpt("workspace ws", "", "Only search this workspace, by name or ID")
+		rack      = app.StringOpt("rack", "", "Only search this rack, by name or ID")
+		product   = app.StringOpt("hardware-product hp", "", "Only search devices of this hardware product, by name, alias, or ID")
+
+		idsOnly    = app.BoolOpt("ids-only", false, "Only retrieve device IDs")
+		fullOutput = app.BoolOpt("full", false, "When --ids-only is *not* used, provide additional data about the devices rather than normal truncated data. Note: this slows things down immensely")
+	)
+
+	app.Action = func() {
+		q := conch.NewDeviceQuery().
+			Hostname(*hostname).
+			MAC(*mac).
+			IPAddr(*ipaddr).
+			Phase(*phase).
+			Health(*health)
+
+		for _, kv := range *settings {
+			key, value := splitKeyValue("setting", kv)
+			q.Setting(key, value)
+		}
+		for _, kv := range *tags {
+			key, value := splitKeyValue("tag", kv)
+			q.Tag(key, value)
+		}
+
+		if *graduated != "" {
+			q.Graduated(parseBoolOpt("graduated", *graduated))
+		}
+		if *validated != "" {
+			q.Validated(parseBoolOpt("validated", *validated))
+		}
+
+		if *workspace != "" {
+			id, err := util.MagicWorkspaceID(*workspace)
+			if err != nil {
+				util.Bail(err)
+			}
+			q.Workspace(id)
+		}
+		if *rack != "" {
+			id, err := util.MagicRackID(*rack)
+			if err != nil {
+				util.Bail(err)
+			}
+			q.Rack(id)
+		}
+		if *product != "" {
+			id, err := util.MagicProductID(*product)
+			if err != nil {
+				util.Bail(err)
+			}
+			q.HardwareProduct(id)
+		}
+
+		if *idsOnly {
+			q.IDsOnly()
+		}
+
+		devices, err := util.API.FindDevices(q)
+		if errors.Is(err, conch.ErrBadInput) {
+			util.Bail(errors.New("a hostname, MAC, IP address, setting, tag, or workspace is required. See 'devices search --help'"))
+		}
+		if err != nil {
+			util.Bail(err)
+		}
+		outputDevices(devices, *idsOnly, *fullOutput)
+	}
+}
+
+func splitKeyValue(opt string, kv string) (string, string) {
+	bits := strings.SplitN(kv, "=", 2)
+	if (len(bits) != 2) || (bits[0] == "") {
+		util.Bail(fmt.Errorf("--%s must look like KEY=VALUE, got '%s'", opt, kv))
+	}
+	return bits[0], bits[1]
+}
+
+func parseBoolOpt(opt string, value string) bool {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		util.Bail(fmt.Errorf("--%s must be 'true' or 'false', got '%s'", opt, value))
+	}
+	return b
+}
+
 func searchBySetting(app *cli.Cmd) {
 	var (
 		keyOpt   = app.StringArg("KEY", "", "Setting name")
@@ -492,9 +603,8 @@ func searchBySetting(app *cli.Cmd) {
 	app.Spec = "KEY VALUE [OPTIONS]"
 
 	app.Action = func() {
-		devices, err := util.API.GetDevicesBySetting(
-			*keyOpt,
-			*valueOpt,
+		devices, err := util.API.FindDevices(
+			conch.NewDeviceQuery().Setting(*keyOpt, *valueOpt),
 		)
 		if err != nil {
 			util.Bail(err)
@@ -515,9 +625,8 @@ func searchByTag(app *cli.Cmd) {
 	app.Spec = "KEY VALUE [OPTIONS]"
 
 	app.Action = func() {
-		devices, err := util.API.GetDevicesByTag(
-			*keyOpt,
-			*valueOpt,
+		devices, err := util.API.FindDevices(
+			conch.NewDeviceQuery().Tag(*keyOpt, *valueOpt),
 		)
 		if err != nil {
 			util.Bail(err)
@@ -537,9 +646,8 @@ func searchByHostname(app *cli.Cmd) {
 	app.Spec = "HOSTNAME [OPTIONS]"
 
 	app.Action = func() {
-		devices, err := util.API.GetDevicesByField(
-			"hostname",
-			*valueOpt,
+		devices, err := util.API.FindDevices(
+			conch.NewDeviceQuery().Hostname(*valueOpt),
 		)
 		if err != nil {
 			util.Bail(err)
@@ -32,8 +32,10 @@ func Init(app *cli.Cli) {
 
 			cmd.Command(
 				"search s",
-				"Search for devices",
+				"Search for devices. Combine any of the options to narrow the search",
 				func(cmd *cli.Cmd) {
+					searchDevices(cmd)
+
 					cmd.Command(
 						"setting",
 						"Search for devices by exact setting value",
@@ -24,10 +24,10 @@ func dcGetAll(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(d)
+			util.Print(d)
 			return
 		}
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Region",
@@ -37,7 +37,7 @@ func dcGetAll(app *cli.Cmd) {
 		})
 
 		for _, dc := range d {
-			table.Append([]string{
+			table.AppendItem(dc, []string{
 				dc.ID.String(),
 				dc.Region,
 				dc.Vendor,
@@ -58,7 +58,7 @@ func dcGet(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(d)
+			util.Print(d)
 			return
 		}
 
@@ -107,7 +107,7 @@ func dcCreate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(d)
+			util.Print(d)
 			return
 		}
 
@@ -174,7 +174,7 @@ func dcUpdate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(d)
+			util.Print(d)
 			return
 		}
 
@@ -215,11 +215,11 @@ func dcGetAllRooms(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rs)
+			util.Print(rs)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"AZ",
@@ -228,7 +228,7 @@ func dcGetAllRooms(app *cli.Cmd) {
 		})
 
 		for _, r := range rs {
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				r.AZ,
 				r.Alias,
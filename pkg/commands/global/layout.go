@@ -24,7 +24,7 @@ func layoutGet(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -78,7 +78,7 @@ func layoutCreate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -140,7 +140,7 @@ func layoutUpdate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -59,10 +59,10 @@ func rackGetAll(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rs)
+			util.Print(rs)
 			return
 		}
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Datacenter Room ID",
@@ -73,7 +73,7 @@ func rackGetAll(app *cli.Cmd) {
 		})
 
 		for _, r := range rs {
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				r.DatacenterRoomID.String(),
 				r.Name,
@@ -96,7 +96,7 @@ func rackGet(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -137,7 +137,7 @@ func rackCreate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -192,7 +192,7 @@ func rackUpdate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 		displayOneRack(r)
@@ -219,11 +219,11 @@ func rackLayout(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rs)
+			util.Print(rs)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Product",
@@ -237,7 +237,7 @@ func rackLayout(app *cli.Cmd) {
 			if err != nil {
 				util.Bail(err)
 			}
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				prod.Name,
 				strconv.Itoa(r.RUStart),
@@ -25,10 +25,10 @@ func roleGetAll(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rs)
+			util.Print(rs)
 			return
 		}
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Name",
@@ -36,7 +36,7 @@ func roleGetAll(app *cli.Cmd) {
 		})
 
 		for _, r := range rs {
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				r.Name,
 				strconv.Itoa(r.RackSize),
@@ -56,7 +56,7 @@ func roleGet(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -97,7 +97,7 @@ func roleCreate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -144,7 +144,7 @@ func roleUpdate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
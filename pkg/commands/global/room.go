@@ -25,10 +25,10 @@ func roomGetAll(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rs)
+			util.Print(rs)
 			return
 		}
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Datacenter ID",
@@ -38,7 +38,7 @@ func roomGetAll(app *cli.Cmd) {
 		})
 
 		for _, r := range rs {
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				r.DatacenterID.String(),
 				r.AZ,
@@ -60,7 +60,7 @@ func roomGet(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -113,7 +113,7 @@ func roomCreate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -178,7 +178,7 @@ func roomUpdate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -225,10 +225,10 @@ func roomGetAllRacks(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rs)
+			util.Print(rs)
 			return
 		}
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Name",
@@ -241,7 +241,7 @@ func roomGetAllRacks(app *cli.Cmd) {
 				util.Bail(err)
 			}
 
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				r.Name,
 				fmt.Sprintf("%s (%s)", role.Name, r.RoleID.String()),
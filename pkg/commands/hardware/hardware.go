@@ -144,7 +144,7 @@ func getOne(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(ret)
+			util.Print(ret)
 			return
 		}
 		var vendor_name string
@@ -214,7 +214,7 @@ func getAll(app *cli.Cmd) {
 				ids = append(ids, r.ID.String())
 			}
 			if util.JSON {
-				util.JSONOut(ids)
+				util.Print(ids)
 			} else {
 				for _, id := range ids {
 					fmt.Println(id)
@@ -225,7 +225,7 @@ func getAll(app *cli.Cmd) {
 
 		if *fullOutput {
 			if util.JSON {
-				util.JSONOut(ret)
+				util.Print(ret)
 				return
 			}
 			t, err := template.New("hw").Parse(singleHWPTemplate)
@@ -272,15 +272,15 @@ func getAll(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rows)
+			util.Print(rows)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{"ID", "SKU", "Name", "Alias", "Prefix", "Vendor", "Purpose"})
 
 		for _, r := range rows {
-			table.Append([]string{r.ID, r.SKU, r.Name, r.Alias, r.Prefix, r.Vendor, r.Purpose})
+			table.AppendItem(r, []string{r.ID, r.SKU, r.Name, r.Alias, r.Prefix, r.Vendor, r.Purpose})
 		}
 
 		table.Render()
@@ -344,7 +344,7 @@ func createOne(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(ret)
+			util.Print(ret)
 			return
 		}
 		fmt.Println(ret.ID)
@@ -440,7 +440,7 @@ func updateOne(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(ret)
+			util.Print(ret)
 			return
 		}
 	}
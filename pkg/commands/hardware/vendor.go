@@ -34,7 +34,7 @@ func getOneVendor(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(ret)
+			util.Print(ret)
 			return
 		}
 
@@ -52,11 +52,11 @@ func getAllVendors(app *cli.Cmd) {
 			util.Bail(err)
 		}
 		if util.JSON {
-			util.JSONOut(ret)
+			util.Print(ret)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Name",
@@ -64,7 +64,7 @@ func getAllVendors(app *cli.Cmd) {
 			"Updated",
 		})
 		for _, v := range ret {
-			table.Append([]string{
+			table.AppendItem(v, []string{
 				v.ID.String(),
 				v.Name,
 				util.TimeStr(v.Created),
@@ -85,7 +85,7 @@ func createOneVendor(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(v)
+			util.Print(v)
 			return
 		}
 		displayHardwareVendor(v)
@@ -65,6 +65,24 @@ func Init(app *cli.Cli) {
 						"Change the API token for the active profile. This will convert the profile to token auth if it was previously using login auth",
 						setToken,
 					)
+
+					cmd.Command(
+						"tls",
+						"Replace the TLS settings for the active profile. Any option left out is cleared",
+						setTLS,
+					)
+
+					cmd.Command(
+						"transport",
+						"Replace the timeout, proxy, and connection settings for the active profile. Any option left out is cleared",
+						setTransport,
+					)
+
+					cmd.Command(
+						"credential-helper",
+						"Have an external command keep the API token and login for the active profile. See docs/tokens.md",
+						setCredentialHelper,
+					)
 				},
 			)
 
@@ -74,6 +92,18 @@ func Init(app *cli.Cli) {
 				upgradeToToken,
 			)
 
+			cmd.Command(
+				"rotate-token",
+				"Replace the API token of the active profile with a new one, then delete the old one",
+				rotateToken,
+			)
+
+			cmd.Command(
+				"migrate-secrets",
+				"Move the API tokens and logins of every profile out of the config file and into a secret store, or back",
+				migrateSecrets,
+			)
+
 			cmd.Command(
 				"relogin",
 				"Log in again, preserving all other profile data",
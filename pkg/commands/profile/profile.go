@@ -6,10 +6,8 @@
 package profile
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/Bowery/prompt"
@@ -32,6 +30,8 @@ func newProfile(app *cli.Cmd) {
 
 		envOpt = app.StringOpt("environment env", "production", "Specify the environment: production, staging, development (provide URL in the --url parameter)")
 		urlOpt = app.StringOpt("url", "", "If the environment is 'development', this defines the API URL. Ignored otherwise")
+
+		tls = tlsFlags(app)
 	)
 
 	app.Spec = "--name [OPTIONS]"
@@ -50,6 +50,7 @@ func newProfile(app *cli.Cmd) {
 			}
 
 			p = util.Config.Profiles[*nameOpt]
+			util.Config.ForgetSecrets(p)
 		} else {
 			p = &config.ConchProfile{}
 			p.Name = *nameOpt
@@ -69,21 +70,29 @@ func newProfile(app *cli.Cmd) {
 			p.BaseURL = config.ProductionURL
 		}
 
+		if err := tls.apply(p); err != nil {
+			util.Bail(err)
+		}
+
 		/***/
 
-		util.API = &conch.Conch{
-			BaseURL: p.BaseURL,
-		}
+		opts := []conch.Option{conch.WithBaseURL(p.BaseURL)}
+		opts = append(opts, util.ConnectionOptions(p)...)
 
 		if util.UserAgent != "" {
-			util.API.UA = util.UserAgent
+			opts = append(opts, conch.WithUserAgent(util.UserAgent))
 		}
 
+		if *tokenOpt != "" {
+			opts = append(opts, conch.WithToken(*tokenOpt))
+		}
+
+		util.API = conch.New(opts...)
+
 		/***/
 
 		if *tokenOpt != "" {
 			p.Token = config.Token(*tokenOpt)
-			util.API.Token = *tokenOpt
 
 			if ok, err := util.API.VerifyToken(); !ok {
 				util.Bail(err)
@@ -106,14 +115,14 @@ func newProfile(app *cli.Cmd) {
 			}
 
 			if err := util.API.Login(p.User, password); err != nil {
-				if util.JSON || err != conch.ErrMustChangePassword {
+				if util.JSON || !errors.Is(err, conch.ErrMustChangePassword) {
 					util.Bail(err)
 				}
 				util.ActiveProfile = p
 				util.InteractiveForcePasswordChange()
 			}
 
-			p.JWT = util.API.JWT
+			p.JWT = util.API.CurrentJWT()
 			p.Expires = p.JWT.Expires
 		}
 
@@ -155,7 +164,7 @@ func deleteProfile(app *cli.Cmd) {
 	app.Spec = "NAME"
 
 	app.Action = func() {
-		delete(util.Config.Profiles, *nameArg)
+		util.Config.DeleteProfile(*nameArg)
 		switch len(util.Config.Profiles) {
 		case 0:
 			fmt.Println("WARNING: No profiles remain")
@@ -178,14 +187,10 @@ func deleteProfile(app *cli.Cmd) {
 
 func listProfiles(app *cli.Cmd) {
 	app.Action = func() {
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 
 		if util.JSON {
-			j, err := json.Marshal(util.Config.Profiles)
-			if err != nil {
-				util.Bail(err)
-			}
-			fmt.Println(string(j))
+			util.Print(util.Config.Profiles)
 			return
 		}
 
@@ -219,7 +224,7 @@ func listProfiles(app *cli.Cmd) {
 				expires = util.TimeStr(prof.JWT.Expires)
 			}
 
-			table.Append([]string{
+			table.AppendItem(prof, []string{
 				active,
 				prof.Name,
 				prof.User,
@@ -385,15 +390,17 @@ func relogin(app *cli.Cmd) {
 
 		err := util.API.Login(util.ActiveProfile.User, password)
 		if err != nil {
-			if util.JSON || err != conch.ErrMustChangePassword {
+			if util.JSON || !errors.Is(err, conch.ErrMustChangePassword) {
 				util.Bail(err)
 			}
 			util.InteractiveForcePasswordChange()
 		}
 
-		util.ActiveProfile.JWT = util.API.JWT
-		util.ActiveProfile.Expires = util.API.JWT.Expires
+		util.ActiveProfile.JWT = util.API.CurrentJWT()
+		util.ActiveProfile.Expires = util.ActiveProfile.JWT.Expires
 		util.ActiveProfile.Token = ""
+		util.ActiveProfile.TokenName = ""
+		util.ActiveProfile.TokenExpires = time.Time{}
 		util.Token = ""
 		util.WriteConfigForce()
 
@@ -440,6 +447,8 @@ func setToken(cmd *cli.Cmd) {
 		}
 
 		util.ActiveProfile.Token = config.Token(*tokenArg)
+		util.ActiveProfile.TokenName = ""
+		util.ActiveProfile.TokenExpires = time.Time{}
 		util.Token = *tokenArg
 
 		util.ActiveProfile.JWT = conch.ConchJWT{}
@@ -457,33 +466,19 @@ func upgradeToToken(cmd *cli.Cmd) {
 			return
 		}
 
-		hostname, err := os.Hostname()
-
-		if err != nil {
-			hostname = "unknown"
-		}
-
-		uid := os.Getuid()
-
-		epoch := time.Now().Unix()
-		id := fmt.Sprintf("%d@%s || %d", uid, hostname, epoch)
-
 		util.BuildAPI()
 
-		token, err := util.API.CreateMyToken(id)
+		token, err := util.API.CreateMyToken(util.NewTokenName())
 		if err != nil {
 			util.Bail(err)
 		}
 
-		util.ActiveProfile.Token = config.Token(token.Token)
-		util.Token = token.Token
-
-		util.ActiveProfile.JWT = conch.ConchJWT{}
+		useToken(util.ActiveProfile, token)
 
 		util.WriteConfigForce()
 
 		if !util.JSON {
-			fmt.Printf("Created a token named '%s' and will now use it for this profile\n", id)
+			fmt.Printf("Created a token named '%s' and will now use it for this profile\n", token.Name)
 
 		}
 	}
@@ -0,0 +1,101 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/config/secrets"
+	"github.com/joyent/conch-shell/pkg/util"
+)
+
+// storeConfig names the config file itself, for moving secrets back
+const storeConfig = "config"
+
+func migrateSecrets(app *cli.Cmd) {
+	var (
+		toOpt = app.StringOpt(
+			"to",
+			secrets.Auto,
+			"Where to keep secrets: 'secret-service' (GNOME Keyring, KWallet), 'file' (encrypted with a passphrase), 'auto' for the former if available and the latter otherwise, or 'config' to move them back into the config file",
+		)
+	)
+
+	app.Action = func() {
+		store := ""
+		switch *toOpt {
+		case storeConfig:
+		case secrets.Auto, secrets.SecretService, secrets.File:
+			store = util.Config.Secrets.Resolve(*toOpt)
+			if _, err := util.Config.Secrets.Open(store); err != nil {
+				util.Bail(err)
+			}
+		default:
+			util.Bail(fmt.Errorf("unknown secret store '%s'", *toOpt))
+		}
+
+		names := make([]string, 0, len(util.Config.Profiles))
+		for name := range util.Config.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		failed := 0
+		for _, name := range names {
+			if err := util.Config.MoveSecrets(util.Config.Profiles[name], store); err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: %s\n", err)
+				failed++
+			}
+		}
+
+		// From now on, new profiles and logins go to the same place
+		util.Config.SecretStore = store
+		util.WriteConfigForce()
+
+		if util.JSON {
+			return
+		}
+		if store == "" {
+			store = "the config file"
+		}
+		fmt.Printf(
+			"Moved the secrets of %d profile(s) to %s. Config written to %s\n",
+			len(names)-failed,
+			store,
+			util.Config.Path,
+		)
+	}
+}
+
+func setCredentialHelper(app *cli.Cmd) {
+	var commandArg = app.StringArg(
+		"COMMAND",
+		"",
+		"Command line that keeps the token, like 'pass show conch/prod'. Leave empty to keep the token in the secret store or config file again",
+	)
+	app.Spec = "[COMMAND]"
+
+	app.Action = func() {
+		if util.ActiveProfile == nil {
+			util.Bail(errors.New("there is no active profile. Please use 'profile set active' to mark a profile as active"))
+		}
+
+		if err := util.Config.UseCredentialHelper(util.ActiveProfile, *commandArg); err != nil {
+			util.Bail(err)
+		}
+		util.Token = string(util.ActiveProfile.Token)
+
+		util.WriteConfigForce()
+		if !util.JSON {
+			fmt.Printf("Done. Config written to %s\n", util.Config.Path)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/config"
+	"github.com/joyent/conch-shell/pkg/util"
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+const insecureWarning = `
+WARNING: TLS certificate verification is disabled for profile '%s'.
+Anyone between you and %s can read and alter your traffic,
+including your credentials. Only ever do this for local development.
+
+`
+
+// tlsOpts are the TLS options shared by 'profile new' and 'profile set tls'
+type tlsOpts struct {
+	caFile     *string
+	clientCert *string
+	clientKey  *string
+	minVersion *string
+	serverName *string
+	insecure   *bool
+}
+
+func tlsFlags(app *cli.Cmd) tlsOpts {
+	return tlsOpts{
+		caFile:     app.StringOpt("ca-file", "", "PEM bundle of certificate authorities to trust, in addition to the system's"),
+		clientCert: app.StringOpt("client-cert", "", "PEM client certificate, for APIs that require mutual TLS"),
+		clientKey:  app.StringOpt("client-key", "", "PEM key for --client-cert"),
+		minVersion: app.StringOpt("tls-min-version", "", "Lowest TLS version to accept: 1.0, 1.1, 1.2, or 1.3"),
+		serverName: app.StringOpt("tls-server-name", "", "Server name to send via SNI and expect in the certificate, if not the API's host"),
+		insecure:   app.BoolOpt("insecure-skip-verify", false, "Do not verify the API's certificate. DANGEROUS. Only for local development"),
+	}
+}
+
+// apply copies the options onto the profile, expanding file paths so the
+// profile works from any directory, and checks that they make sense
+func (o tlsOpts) apply(p *config.ConchProfile) error {
+	expand := func(path string) (string, error) {
+		if path == "" {
+			return "", nil
+		}
+		return homedir.Expand(path)
+	}
+
+	var err error
+	if p.CAFile, err = expand(*o.caFile); err != nil {
+		return err
+	}
+	if p.ClientCert, err = expand(*o.clientCert); err != nil {
+		return err
+	}
+	if p.ClientKey, err = expand(*o.clientKey); err != nil {
+		return err
+	}
+	p.TLSMinVersion = *o.minVersion
+	p.TLSServerName = *o.serverName
+	p.InsecureSkipVerify = *o.insecure
+
+	if _, err := p.TLSOptions().Config(); err != nil {
+		return err
+	}
+
+	if p.InsecureSkipVerify {
+		fmt.Fprintf(os.Stderr, insecureWarning, p.Name, p.BaseURL)
+	}
+	return nil
+}
+
+func setTLS(app *cli.Cmd) {
+	opts := tlsFlags(app)
+
+	app.Action = func() {
+		if util.ActiveProfile == nil {
+			util.Bail(errors.New("there is no active profile. Please use 'profile set active' to mark a profile as active"))
+		}
+
+		if err := opts.apply(util.ActiveProfile); err != nil {
+			util.Bail(err)
+		}
+
+		util.WriteConfigForce()
+		if !util.JSON {
+			fmt.Printf("Done. Config written to %s\n", util.Config.Path)
+		}
+	}
+}
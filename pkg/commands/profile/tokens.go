@@ -0,0 +1,190 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/config"
+	"github.com/joyent/conch-shell/pkg/util"
+)
+
+// rotatedToken is the JSON output of 'profile rotate-token'. The token
+// string itself is never shown.
+type rotatedToken struct {
+	Token    conch.UserToken `json:"token"`
+	Replaced string          `json:"replaced,omitempty"`
+	Deleted  bool            `json:"deleted"`
+}
+
+// useToken switches the profile over to a token the shell just created
+func useToken(p *config.ConchProfile, token conch.NewUserToken) {
+	p.Token = config.Token(token.Token)
+	p.TokenName = token.Name
+	p.TokenExpires = token.Expires
+	p.JWT = conch.ConchJWT{}
+
+	util.Token = token.Token
+}
+
+// replaceToken creates a token to take the place of the one api uses, and
+// makes sure it works with a client of its own, built from opts. Only then
+// is the token handed to save. If the token does not work, or save fails,
+// the token is deleted again and the old one stays in use. The client of
+// the new token is returned.
+func replaceToken(
+	api *conch.Conch,
+	opts []conch.Option,
+	save func(conch.NewUserToken) error,
+) (conch.NewUserToken, *conch.Conch, error) {
+	token, err := api.CreateMyToken(util.NewTokenName())
+	if err != nil {
+		return token, nil, err
+	}
+
+	verified := conch.New(append(opts, conch.WithToken(token.Token))...)
+	if ok, err := verified.VerifyToken(); !ok {
+		_ = api.DeleteMyToken(token.Name)
+		return token, nil, fmt.Errorf("the new token does not work, so the old one is still in use: %s", err)
+	}
+
+	if err := save(token); err != nil {
+		_ = api.DeleteMyToken(token.Name)
+		return token, nil, err
+	}
+	return token, verified, nil
+}
+
+// saveToken returns a save func for replaceToken, which switches the profile
+// over to the new token and writes the config. The config is written to a
+// temporary file and moved into place, so the profile either has the new
+// token or still has the old one.
+func saveToken(c *config.ConchConfig, p *config.ConchProfile) func(conch.NewUserToken) error {
+	return func(token conch.NewUserToken) error {
+		old := *p
+		useToken(p, token)
+		if err := c.SerializeToFile(c.Path); err != nil {
+			*p = old
+			util.Token = string(old.Token)
+			return err
+		}
+		return nil
+	}
+}
+
+// checkToken reads the config back from disk, and the profile's secrets
+// from wherever it keeps them, to make sure the new token was kept. A
+// credential helper that ignores what it's given looks just like one that
+// stored it, until it's asked for the token again.
+func checkToken(c *config.ConchConfig, name string, token conch.NewUserToken) error {
+	saved, err := config.NewFromJSONFile(c.Path)
+	if err != nil {
+		return err
+	}
+	saved.Secrets = c.Secrets
+
+	p, ok := saved.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile '%s' is missing from %s", name, c.Path)
+	}
+	if err := saved.LoadSecrets(p); err != nil {
+		return err
+	}
+	if string(p.Token) != token.Token {
+		return fmt.Errorf("profile '%s' still has a different token", name)
+	}
+	return nil
+}
+
+func rotateToken(app *cli.Cmd) {
+	var (
+		oldOpt  = app.StringOpt("old", "", "Name of the token being replaced, if the shell did not create it")
+		keepOpt = app.BoolOpt("keep-old", false, "Leave the old token in place instead of deleting it")
+	)
+
+	app.Action = func() {
+		if util.IgnoreConfig {
+			util.Bail(errors.New("a token given on the command line cannot be rotated"))
+		}
+		if util.ActiveProfile == nil {
+			util.Bail(errors.New("there is no active profile. Please use 'profile set active' to mark a profile as active"))
+		}
+
+		p := util.ActiveProfile
+		if p.Token == "" {
+			util.Bail(errors.New("this profile does not use an API token. Please use 'profile upgrade' instead"))
+		}
+
+		oldName := *oldOpt
+		if oldName == "" {
+			oldName = p.TokenName
+		}
+
+		util.BuildAPI()
+
+		// The new token is checked with a client of its own, reaching the
+		// API the same way
+		opts := append([]conch.Option{
+			conch.WithBaseURL(util.API.BaseURL),
+			conch.WithUserAgent(util.API.UA),
+			conch.WithDebug(util.Debug),
+			conch.WithTrace(util.Trace),
+		}, util.ConnectionOptions(p)...)
+
+		token, api, err := replaceToken(util.API, opts, saveToken(util.Config, p))
+		if err != nil {
+			util.Bail(err)
+		}
+
+		// The old token is only deleted once the new one is known to be
+		// where the profile looks for it
+		if err := checkToken(util.Config, p.Name, token); err != nil {
+			util.Bail(fmt.Errorf(
+				"the new token '%s' works, but was not kept: %s. The old token was not deleted. Please store the new token by hand: %s",
+				token.Name,
+				err,
+				token.Token,
+			))
+		}
+
+		out := rotatedToken{Token: token.UserToken, Replaced: oldName}
+		switch {
+		case *keepOpt:
+		case oldName == "":
+			fmt.Fprintln(
+				os.Stderr,
+				"WARNING: the old token was not deleted, as its name is unknown. Please use 'user tokens' to find it and 'user token rm' to delete it",
+			)
+		default:
+			if err := api.DeleteMyToken(oldName); err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: could not delete the old token '%s': %s\n", oldName, err)
+			} else {
+				out.Deleted = true
+			}
+		}
+
+		if util.JSON {
+			util.Print(out)
+			return
+		}
+
+		fmt.Printf(
+			"Replaced the API token of profile '%s' with '%s', which expires on %s. Config written to %s\n",
+			p.Name,
+			token.Name,
+			util.TimeStr(token.Expires),
+			util.Config.Path,
+		)
+		if out.Deleted {
+			fmt.Printf("Deleted the old token '%s'\n", oldName)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package profile
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/conchtest"
+	"github.com/joyent/conch-shell/pkg/config"
+	"github.com/joyent/conch-shell/pkg/config/secrets"
+	"github.com/nbio/st"
+)
+
+func tokenNames(t *testing.T, api *conch.Conch) []string {
+	tokens, err := api.GetMyTokens()
+	st.Expect(t, err, nil)
+
+	names := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		names = append(names, token.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestReplaceToken(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+
+	user := srv.AddUser("rotate@conch.test", "pw", "Rotate", false)
+	oldToken, err := srv.AddToken(user.ID, "old")
+	st.Expect(t, err, nil)
+	api := srv.Client(conch.WithToken(oldToken))
+
+	opts := []conch.Option{
+		conch.WithBaseURL(srv.URL),
+		conch.WithRetryPolicy(conch.NoRetries),
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		var saved conch.NewUserToken
+		token, verified, err := replaceToken(api, opts, func(token conch.NewUserToken) error {
+			saved = token
+			return nil
+		})
+		st.Expect(t, err, nil)
+		st.Expect(t, saved, token)
+		st.Reject(t, token.Token, oldToken)
+		st.Expect(t, verified.Token, token.Token)
+		st.Expect(t, api.Token, oldToken)
+
+		st.Expect(t, verified.DeleteMyToken(token.Name), nil)
+	})
+
+	t.Run("VerifyFails", func(t *testing.T) {
+		// The new token is unknown to another server
+		other := conchtest.NewServer()
+		defer other.Close()
+
+		saved := false
+		_, verified, err := replaceToken(
+			api,
+			[]conch.Option{conch.WithBaseURL(other.URL), conch.WithRetryPolicy(conch.NoRetries)},
+			func(conch.NewUserToken) error {
+				saved = true
+				return nil
+			},
+		)
+		st.Reject(t, err, nil)
+		st.Expect(t, saved, false)
+		st.Expect(t, verified == nil, true)
+		st.Expect(t, api.Token, oldToken)
+		st.Expect(t, tokenNames(t, api), []string{"old"})
+	})
+
+	t.Run("SaveFails", func(t *testing.T) {
+		_, _, err := replaceToken(api, opts, func(conch.NewUserToken) error {
+			return errors.New("disk full")
+		})
+		st.Expect(t, err.Error(), "disk full")
+		st.Expect(t, tokenNames(t, api), []string{"old"})
+	})
+}
+
+func TestRotateKeepsToken(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "conch-rotate")
+	st.Expect(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	opts := []conch.Option{
+		conch.WithBaseURL(srv.URL),
+		conch.WithRetryPolicy(conch.NoRetries),
+	}
+
+	tests := []struct {
+		name   string
+		helper string
+		kept   bool
+		err    error
+	}{
+		{name: "ConfigFile", kept: true},
+		{
+			// Keeps the request, which get prints back as JSON, and
+			// starts out with the old token
+			name: "Helper",
+			helper: `f="` + filepath.Join(dir, "creds") + `"
+				case "$CONCH_CREDENTIAL_ACTION" in
+				get) cat "$f" 2> /dev/null || echo "$OLD_TOKEN" ;;
+				store) cat > "$f" ;;
+				esac`,
+			kept: true,
+		},
+		{
+			// Like a bare "pass show", which ignores the action
+			name:   "ReadOnlyHelper",
+			helper: `cat > /dev/null; echo "$OLD_TOKEN"`,
+		},
+		{
+			name: "UnsupportedHelper",
+			helper: `[ "$CONCH_CREDENTIAL_ACTION" = get ] || { echo unsupported >&2; exit 1; }
+				echo "$OLD_TOKEN"`,
+			err: secrets.ErrUnsupported,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := srv.AddUser(test.name+"@conch.test", "pw", test.name, false)
+			oldToken, err := srv.AddToken(user.ID, "old")
+			st.Expect(t, err, nil)
+			api := srv.Client(conch.WithToken(oldToken))
+			os.Setenv("OLD_TOKEN", oldToken)
+			defer os.Unsetenv("OLD_TOKEN")
+
+			cfg := config.New()
+			cfg.Path = filepath.Join(dir, test.name+".json")
+			p := &config.ConchProfile{
+				Name:             "prod",
+				BaseURL:          srv.URL,
+				Token:            config.Token(oldToken),
+				TokenName:        "old",
+				CredentialHelper: test.helper,
+				Active:           true,
+			}
+			cfg.Profiles[p.Name] = p
+			st.Expect(t, cfg.LoadSecrets(p), nil)
+
+			token, _, err := replaceToken(api, opts, saveToken(cfg, p))
+			if test.err != nil {
+				st.Expect(t, errors.Is(err, test.err), true)
+				st.Expect(t, string(p.Token), oldToken)
+				st.Expect(t, tokenNames(t, api), []string{"old"})
+				return
+			}
+			st.Expect(t, err, nil)
+
+			err = checkToken(cfg, p.Name, token)
+			st.Expect(t, err == nil, test.kept)
+
+			// Whether or not it was kept, both tokens still exist until
+			// rotate-token deletes the old one
+			st.Expect(t, tokenNames(t, api), []string{token.Name, "old"})
+		})
+	}
+}
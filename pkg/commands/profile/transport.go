@@ -0,0 +1,60 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package profile
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/config"
+	"github.com/joyent/conch-shell/pkg/util"
+)
+
+func setTransport(app *cli.Cmd) {
+	var (
+		connectTimeout  = app.StringOpt("connect-timeout", "", "How long to wait for a connection to the API, like '10s'")
+		keepAlive       = app.StringOpt("keepalive", "", "Interval between TCP keep-alive probes")
+		tlsTimeout      = app.StringOpt("tls-timeout", "", "How long to wait for the TLS handshake")
+		responseTimeout = app.StringOpt("response-timeout", "", "How long to wait for the API to start answering")
+		timeout         = app.StringOpt("timeout", "", "How long any one request may take, start to finish")
+		proxy           = app.StringOpt("proxy", "", "Proxy URL to use instead of HTTP_PROXY and HTTPS_PROXY")
+		noProxy         = app.StringsOpt("no-proxy", nil, "Host, domain, IP, or CIDR block to reach without the proxy. Can be repeated")
+		maxIdle         = app.IntOpt("max-idle-conns", 0, "Maximum number of idle connections kept, across all hosts")
+		maxConns        = app.IntOpt("max-conns", 0, "Maximum number of connections to the API")
+		maxIdlePerHost  = app.IntOpt("max-idle-conns-per-host", 0, "Maximum number of idle connections kept for the API. Defaults to --max-conns")
+	)
+
+	app.Action = func() {
+		if util.ActiveProfile == nil {
+			util.Bail(errors.New("there is no active profile. Please use 'profile set active' to mark a profile as active"))
+		}
+
+		settings := config.TransportSettings{
+			ConnectTimeout:  *connectTimeout,
+			KeepAlive:       *keepAlive,
+			TLSTimeout:      *tlsTimeout,
+			ResponseTimeout: *responseTimeout,
+			Timeout:         *timeout,
+			Proxy:           *proxy,
+			NoProxy:         *noProxy,
+			MaxIdleConns:    *maxIdle,
+			MaxConnsPerHost: *maxConns,
+			MaxIdlePerHost:  *maxIdlePerHost,
+		}
+		if _, err := settings.TransportOptions(); err != nil {
+			util.Bail(err)
+		}
+
+		util.ActiveProfile.TransportSettings = settings
+
+		util.WriteConfigForce()
+		if !util.JSON {
+			fmt.Printf("Done. Config written to %s\n", util.Config.Path)
+		}
+	}
+}
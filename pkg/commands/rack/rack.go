@@ -56,10 +56,10 @@ func rackGetAll(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rs)
+			util.Print(rs)
 			return
 		}
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Datacenter Room ID",
@@ -70,7 +70,7 @@ func rackGetAll(app *cli.Cmd) {
 		})
 
 		for _, r := range rs {
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				r.DatacenterRoomID.String(),
 				r.Name,
@@ -93,7 +93,7 @@ func rackGet(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -134,7 +134,7 @@ func rackCreate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 
@@ -189,7 +189,7 @@ func rackUpdate(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(r)
+			util.Print(r)
 			return
 		}
 		displayOneRack(r)
@@ -216,11 +216,11 @@ func rackLayout(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rs)
+			util.Print(rs)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Product",
@@ -234,7 +234,7 @@ func rackLayout(app *cli.Cmd) {
 			if err != nil {
 				util.Bail(err)
 			}
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				prod.Name,
 				strconv.Itoa(r.RUStart),
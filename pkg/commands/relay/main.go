@@ -49,11 +49,11 @@ func getAllRelays(app *cli.Cmd) {
 			util.Bail(err)
 		}
 		if util.JSON {
-			util.JSONOut(relays)
+			util.Print(relays)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Alias",
@@ -66,7 +66,7 @@ func getAllRelays(app *cli.Cmd) {
 
 		sort.Sort(sortRelaysByUpdated(relays))
 		for _, r := range relays {
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID,
 				r.Alias,
 				r.IPAddr,
@@ -165,11 +165,11 @@ For instance:
 		sort.Sort(keepers)
 
 		if util.JSON {
-			util.JSONOut(keepers)
+			util.Print(keepers)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Alias",
@@ -181,7 +181,7 @@ For instance:
 		})
 
 		for _, r := range keepers {
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID,
 				r.Alias,
 				r.IPAddr,
@@ -0,0 +1,463 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package shell
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/joyent/conch-shell/pkg/util"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// idSource lists the IDs, or names, that an argument of a command takes
+type idSource func(s *session, words []string) ([]string, error)
+
+// idSources are the ID arguments that can be completed, by the path of the
+// command
+var idSources = map[string]idSource{
+	"workspace":             workspaceNames,
+	"workspace rack":        workspaceRackNames,
+	"workspace relay":       workspaceRelayIDs,
+	"profile set workspace": workspaceNames,
+	"rack":                  rackIDs,
+	"global rack":           rackIDs,
+	"device":                deviceIDs,
+	"datacenter":            datacenterIDs,
+	"global datacenter":     datacenterIDs,
+	"global room":           roomIDs,
+	"global role":           rackRoleNames,
+	"hardware product":      productNames,
+	"hardware vendor":       vendorNames,
+	"validation":            validationIDs,
+	"validation-plan":       validationPlanIDs,
+	"relay":                 relayIDs,
+}
+
+// localSources are like idSources, but need no API to list what they offer
+var localSources = map[string]idSource{
+	"profile set active": profileNames,
+	"profile delete":     profileNames,
+	"completion":         shellNames,
+}
+
+// useSources are what "use" can pick from
+var useSources = map[string]idSource{
+	"workspace": workspaceNames,
+	"rack":      workspaceRackNames,
+	"device":    deviceIDs,
+}
+
+// autoComplete completes the word under the cursor when tab is pressed. If
+// there's more than one way to do so, and they have nothing more in common,
+// they're listed. On keyInterrupt, from Ctrl-C, it clears the line.
+func (s *session) autoComplete(t *terminal.Terminal) func(string, int, rune) (string, int, bool) {
+	return func(line string, pos int, key rune) (string, int, bool) {
+		if key == keyInterrupt {
+			return "", 0, true
+		}
+		if key != '\t' {
+			return "", 0, false
+		}
+
+		head, tail := line[:pos], line[pos:]
+		start := strings.LastIndexAny(head, " \t") + 1
+		words, err := splitWords(head[:start])
+		if err != nil {
+			return "", 0, false
+		}
+		prefix := head[start:]
+
+		choices := s.complete(words, prefix)
+		if len(choices) == 0 {
+			return "", 0, false
+		}
+
+		if len(choices) == 1 {
+			newHead := head[:start] + quoteWord(choices[0]) + " "
+			return newHead + tail, len(newHead), true
+		}
+
+		if common := commonPrefix(choices); len(common) > len(prefix) {
+			newHead := head[:start] + common
+			return newHead + tail, len(newHead), true
+		}
+
+		fmt.Fprintln(t, strings.Join(choices, "  "))
+		return "", 0, false
+	}
+}
+
+// complete lists the words that may come after words, starting with
+// prefix
+func (s *session) complete(words []string, prefix string) (choices []string) {
+	// Completion is a nicety. Nothing that goes wrong with it, like a
+	// failed lookup, is worth interrupting the shell for. The completion
+	// scripts can log it, though.
+	defer func() {
+		if r := recover(); r != nil {
+			if s.completing {
+				fmt.Fprintf(os.Stderr, "panic: %v\n\n%s\n", r, debug.Stack())
+			}
+			choices = nil
+		}
+	}()
+
+	if (len(words) > 0) && (words[0] == "conch") {
+		words = words[1:]
+	}
+
+	if s.completing {
+		return s.completeCommand(words, prefix)
+	}
+
+	if len(words) == 0 {
+		if strings.HasPrefix(prefix, "-") {
+			return s.completeCommand(words, prefix)
+		}
+		return matching(append(s.tree.subNames(prefix), builtins...), prefix)
+	}
+
+	switch words[0] {
+	case "use":
+		switch len(words) {
+		case 1:
+			return matching(append([]string{"none"}, contexts...), prefix)
+		case 2:
+			if source, ok := useSources[words[1]]; ok {
+				return matching(s.lookupIDs(source, nil), prefix)
+			}
+		}
+		return nil
+
+	case "help":
+		words = words[1:]
+
+	case "exit", "quit":
+		return nil
+	}
+
+	// With something in use, its ID is usually left out, so it isn't
+	// offered either. A partial word might still be one, though.
+	if prefix == "" {
+		words = s.expand(words)
+	}
+
+	return s.completeCommand(words, prefix)
+}
+
+// completeCommand lists the words that may come after words in a conch
+// command, starting with prefix
+func (s *session) completeCommand(words []string, prefix string) (choices []string) {
+	cmd, path, args, expectsValue := s.tree.walk(words)
+
+	if expectsValue {
+		switch words[len(words)-1] {
+		case "-o", "--output":
+			return matching(util.OutputFormats, prefix)
+		case "-p", "--profile":
+			names, _ := profileNames(s, words)
+			return matching(names, prefix)
+		}
+		return nil
+	}
+
+	if strings.HasPrefix(prefix, "-") {
+		choices = make([]string, 0)
+		for _, o := range cmd.options {
+			choices = append(choices, o.names...)
+		}
+		return matching(choices, prefix)
+	}
+
+	choices = cmd.subNames(prefix)
+	if args < len(cmd.args) {
+		p := strings.Join(path, " ")
+		if source, ok := localSources[p]; ok {
+			names, _ := source(s, words)
+			choices = append(choices, names...)
+		} else if source, ok := idSources[p]; ok {
+			choices = append(choices, s.lookupIDs(source, words)...)
+		}
+	}
+	return matching(choices, prefix)
+}
+
+// lookupIDs runs an idSource, if there's a connection to the API to do so
+func (s *session) lookupIDs(source idSource, words []string) []string {
+	if (util.API == nil) && s.completing && !s.triedAPI {
+		s.triedAPI = true
+		if s.guard(util.BuildAPI) != 0 {
+			util.API = nil
+		}
+	}
+	if util.API == nil {
+		return nil
+	}
+	ids, err := source(s, words)
+	if err != nil {
+		if s.completing {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return nil
+	}
+	return ids
+}
+
+// subNames lists the names of the subcommands of c that start with prefix.
+// Aliases are only listed if no main name fits.
+func (c *command) subNames(prefix string) []string {
+	names := make([]string, 0)
+	for _, sub := range c.subs {
+		if strings.HasPrefix(sub.name(), prefix) {
+			names = append(names, sub.name())
+		}
+	}
+	if len(names) > 0 {
+		return names
+	}
+
+	for _, sub := range c.subs {
+		for _, alias := range sub.names {
+			if strings.HasPrefix(alias, prefix) {
+				names = append(names, alias)
+			}
+		}
+	}
+	return names
+}
+
+// matching returns the words that start with prefix, sorted and without
+// duplicates
+func matching(words []string, prefix string) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0)
+	for _, w := range words {
+		if (w != "") && strings.HasPrefix(w, prefix) && !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func commonPrefix(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// quoteWord quotes a word for splitWords, if it needs to be
+func quoteWord(word string) string {
+	if !strings.ContainsAny(word, " \t'\"\\") {
+		return word
+	}
+	return "'" + strings.Replace(word, "'", `'\''`, -1) + "'"
+}
+
+func workspaceNames(s *session, words []string) ([]string, error) {
+	workspaces, err := util.API.GetWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(workspaces))
+	for _, w := range workspaces {
+		names = append(names, w.Name)
+	}
+	return names, nil
+}
+
+func workspaceRackNames(s *session, words []string) ([]string, error) {
+	ws, err := s.workspace(words)
+	if err != nil {
+		return nil, err
+	}
+	racks, err := util.API.GetWorkspaceRacks(ws)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(racks))
+	for _, r := range racks {
+		names = append(names, r.Name)
+	}
+	return names, nil
+}
+
+func workspaceRelayIDs(s *session, words []string) ([]string, error) {
+	ws, err := s.workspace(words)
+	if err != nil {
+		return nil, err
+	}
+	relays, err := util.API.GetWorkspaceRelays(ws)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(relays))
+	for _, r := range relays {
+		ids = append(ids, r.ID)
+	}
+	return ids, nil
+}
+
+func deviceIDs(s *session, words []string) ([]string, error) {
+	ws, err := s.workspace(words)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := util.API.GetWorkspaceDevices(ws, true, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(devices))
+	for _, d := range devices {
+		ids = append(ids, d.ID)
+	}
+	return ids, nil
+}
+
+func profileNames(s *session, words []string) ([]string, error) {
+	names := make([]string, 0)
+	if util.Config != nil {
+		for name := range util.Config.Profiles {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func shellNames(s *session, words []string) ([]string, error) {
+	names := make([]string, 0, len(completionScripts))
+	for name := range completionScripts {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func rackIDs(s *session, words []string) ([]string, error) {
+	racks, err := util.API.GetRacks()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(racks))
+	for _, r := range racks {
+		ids = append(ids, r.ID.String())
+	}
+	return ids, nil
+}
+
+func datacenterIDs(s *session, words []string) ([]string, error) {
+	datacenters, err := util.API.GetDatacenters()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(datacenters))
+	for _, d := range datacenters {
+		ids = append(ids, d.ID.String())
+	}
+	return ids, nil
+}
+
+func roomIDs(s *session, words []string) ([]string, error) {
+	rooms, err := util.API.GetRooms()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(rooms))
+	for _, r := range rooms {
+		ids = append(ids, r.ID.String())
+	}
+	return ids, nil
+}
+
+func rackRoleNames(s *session, words []string) ([]string, error) {
+	roles, err := util.API.GetRackRoles()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		names = append(names, r.Name)
+	}
+	return names, nil
+}
+
+// productNames lists SKUs where products have them, as product names tend
+// to have spaces in them
+func productNames(s *session, words []string) ([]string, error) {
+	products, err := util.API.GetHardwareProducts()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(products))
+	for _, p := range products {
+		if p.SKU != "" {
+			names = append(names, p.SKU)
+		} else {
+			names = append(names, p.Name)
+		}
+	}
+	return names, nil
+}
+
+func vendorNames(s *session, words []string) ([]string, error) {
+	vendors, err := util.API.GetHardwareVendors()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(vendors))
+	for _, v := range vendors {
+		names = append(names, v.Name)
+	}
+	return names, nil
+}
+
+func validationIDs(s *session, words []string) ([]string, error) {
+	validations, err := util.API.GetValidations()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(validations))
+	for _, v := range validations {
+		ids = append(ids, v.ID.String())
+	}
+	return ids, nil
+}
+
+func validationPlanIDs(s *session, words []string) ([]string, error) {
+	plans, err := util.API.GetValidationPlans()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(plans))
+	for _, p := range plans {
+		ids = append(ids, p.ID.String())
+	}
+	return ids, nil
+}
+
+func relayIDs(s *session, words []string) ([]string, error) {
+	relays, err := util.API.GetAllRelays()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(relays))
+	for _, r := range relays {
+		ids = append(ids, r.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,183 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package shell
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/util"
+)
+
+// CompleteCommand is what the completion scripts run conch with to
+// complete a command line. It's no command of the app, so as to stay out of
+// its help.
+const CompleteCommand = "__complete"
+
+// completionScripts are printed by "conch completion SHELL". Each runs
+// "conch __complete" with the words of the command line up to the cursor,
+// and appends what it says on stderr to CONCH_COMPLETION_LOG, if that's
+// set.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for conch. Load it with:
+#   source <(conch completion bash)
+
+# __conch_words puts back together the words of the command line, up to the
+# cursor, that bash broke up at characters like = and :, as in
+# "--where health=FAIL". Quotes are left as they are.
+__conch_words() {
+	local line=${COMP_LINE:0:COMP_POINT} i word
+	words=()
+	cword=-1
+	for ((i = 0; i <= COMP_CWORD; i++)); do
+		word=${COMP_WORDS[i]}
+		if [[ $cword -ge 0 && $line != [[:space:]]* ]]; then
+			words[cword]+=$word
+		else
+			line=${line#"${line%%[![:space:]]*}"}
+			words[++cword]=$word
+		fi
+		line=${line#"$word"}
+	done
+}
+
+_conch() {
+	local IFS=$'\n' words cword word keep
+	__conch_words
+
+	# Completions are of the whole word, but replace only what bash thinks
+	# is the word under the cursor
+	keep=${words[cword]%"${COMP_WORDS[COMP_CWORD]}"}
+
+	COMPREPLY=()
+	for word in $("${words[0]}" __complete "${words[@]:1:cword}" 2>>"${CONCH_COMPLETION_LOG:-/dev/null}"); do
+		COMPREPLY+=("$(printf '%q' "${word#"$keep"}")")
+	done
+}
+
+complete -F _conch conch
+`,
+
+	"zsh": `#compdef conch
+# zsh completion for conch. Load it with:
+#   source <(conch completion zsh)
+# or save it as _conch in a directory of $fpath
+
+_conch() {
+	local -a choices
+	choices=(${(f)"$(${words[1]} __complete "${(@Q)words[2,CURRENT]}" 2>>"${CONCH_COMPLETION_LOG:-/dev/null}")"})
+	compadd -a choices
+}
+
+if [ "$funcstack[1]" = "_conch" ]; then
+	_conch "$@"
+else
+	compdef _conch conch
+fi
+`,
+
+	"fish": `# fish completion for conch. Load it with:
+#   conch completion fish | source
+# or save it as ~/.config/fish/completions/conch.fish
+
+function __conch_complete
+	set -l words (commandline -opc)
+	set -l conch $words[1]
+	set -e words[1]
+	set -l log /dev/null
+	set -q CONCH_COMPLETION_LOG; and set log $CONCH_COMPLETION_LOG
+	command $conch __complete $words (commandline -ct) 2>>$log
+end
+
+complete -c conch -f -a '(__conch_complete)'
+`,
+}
+
+// helpOptions would have the app print something other than completions
+var helpOptions = map[string]bool{
+	"-h":        true,
+	"--help":    true,
+	"-v":        true,
+	"--version": true,
+}
+
+func completionCmd(cmd *cli.Cmd) {
+	shells := make([]string, 0, len(completionScripts))
+	for name := range completionScripts {
+		shells = append(shells, name)
+	}
+	sort.Strings(shells)
+
+	var shellArg = cmd.StringArg("SHELL", "", "The shell: "+strings.Join(shells, ", "))
+	cmd.Spec = "SHELL"
+
+	cmd.Action = func() {
+		script, ok := completionScripts[*shellArg]
+		if !ok {
+			util.Bail(fmt.Errorf(
+				"cannot complete for '%s'. Try one of: %s",
+				*shellArg,
+				strings.Join(shells, ", "),
+			))
+		}
+		fmt.Print(script)
+	}
+}
+
+// Complete prints the ways to complete a command line, one per line, for
+// the completion scripts. args are the words of the command line after
+// "conch", up to the cursor. The last of them is the word under it, and
+// may be empty.
+//
+// Names and IDs are looked up with the profile, and the global options, of
+// the command line. Those lookups are cached for a short while, so that
+// pressing tab again is quick.
+func Complete(build func() *cli.Cli, args []string) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, prefix := args[:len(args)-1], args[len(args)-1]
+
+	// Nothing but completions may be printed. Anything else goes to stderr,
+	// which the completion scripts drop, or log to CONCH_COMPLETION_LOG.
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+
+	util.Completing = true
+	s := &session{
+		build:      build,
+		tree:       commandTree(build()),
+		inUse:      make(map[string]inUse),
+		completing: true,
+	}
+
+	// Running the app with just the global options sets up the config and
+	// the profile, the same way as for any command
+	_, i := s.globalOptions(words)
+	setup := []string{"conch"}
+	for _, word := range words[:i] {
+		if !helpOptions[word] {
+			setup = append(setup, word)
+		}
+	}
+	if _, _, _, expectsValue := s.tree.walk(setup[1:]); expectsValue {
+		setup = setup[:len(setup)-1]
+	}
+	s.guard(func() {
+		_ = build().Run(setup)
+	})
+
+	choices := s.complete(words, prefix)
+
+	os.Stdout = stdout
+	for _, c := range choices {
+		fmt.Println(c)
+	}
+}
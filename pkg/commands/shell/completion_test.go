@@ -0,0 +1,190 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package shell
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/conchtest"
+	"github.com/joyent/conch-shell/pkg/util"
+	"github.com/nbio/st"
+)
+
+// runComplete returns what "conch __complete args..." prints
+func runComplete(t *testing.T, args ...string) []string {
+	defer func() { util.Completing = false }()
+
+	r, w, err := os.Pipe()
+	st.Expect(t, err, nil)
+
+	out := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	Complete(testApp, args)
+	w.Close()
+	return strings.Fields(<-out)
+}
+
+func TestCompleteLines(t *testing.T) {
+	tests := []struct {
+		args    []string
+		choices []string
+	}{
+		{nil, []string{"completion", "device", "rack", "workspace", "workspaces"}},
+		{[]string{""}, []string{"completion", "device", "rack", "workspace", "workspaces"}},
+		{[]string{"wor"}, []string{"workspace", "workspaces"}},
+		{[]string{"--j"}, []string{"--json"}},
+		{[]string{"-o", "ya"}, []string{"yaml"}},
+		{[]string{"--output", "json", "d"}, []string{"device"}},
+		{[]string{"workspace", "GLOBAL", ""}, []string{"devices", "rack", "racks"}},
+		{[]string{"workspace", "--"}, []string{"--full"}},
+		{[]string{"completion", ""}, []string{"bash", "fish", "zsh"}},
+		{[]string{"--help", "dev"}, []string{"device"}},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			st.Expect(t, runComplete(t, test.args...), test.choices)
+		})
+	}
+
+	t.Run("IDs", func(t *testing.T) {
+		srv := conchtest.NewServer()
+		defer srv.Close()
+		seedRack(srv)
+		srv.AddDevice(conch.Device{ID: "SERIAL1"})
+
+		util.API = srv.Client()
+		defer func() { util.API = nil }()
+
+		st.Expect(t, runComplete(t, "workspace", "G"), []string{"GLOBAL"})
+		st.Expect(t, runComplete(t, "workspace", "GLOBAL", "rack", "A"), []string{"A01"})
+	})
+}
+
+func TestBashCompletion(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	log, err := ioutil.TempFile("", "conch-completion")
+	st.Expect(t, err, nil)
+	log.Close()
+	defer os.Remove(log.Name())
+
+	// COMP_WORDS are as bash would break up the line. conch is stood in for
+	// by a function, which logs its arguments and offers what it's told to.
+	tests := []struct {
+		name    string
+		line    string
+		words   string
+		cword   int
+		offer   string
+		args    string
+		choices string
+	}{
+		{
+			name:    "Command",
+			line:    "conch wor",
+			words:   "conch wor",
+			cword:   1,
+			offer:   "workspace workspaces",
+			args:    "wor",
+			choices: "workspace|workspaces",
+		},
+		{
+			name:    "NextWord",
+			line:    "conch device ",
+			words:   "conch device ''",
+			cword:   2,
+			offer:   "settings tags",
+			args:    "device|",
+			choices: "settings|tags",
+		},
+		{
+			name:    "AfterEquals",
+			line:    "conch --where health=FA",
+			words:   "conch --where health = FA",
+			cword:   4,
+			offer:   "health=FAIL",
+			args:    "--where|health=FA",
+			choices: "FAIL",
+		},
+		{
+			name:    "AtEquals",
+			line:    "conch --where health=",
+			words:   "conch --where health =",
+			cword:   3,
+			offer:   "health=FAIL health=pass",
+			args:    "--where|health=",
+			choices: "=FAIL|=pass",
+		},
+		{
+			name:    "AfterColon",
+			line:    "conch relay 00:11",
+			words:   "conch relay 00 : 11",
+			cword:   4,
+			offer:   "00:11:22",
+			args:    "relay|00:11",
+			choices: "11:22",
+		},
+		{
+			name:    "Spaces",
+			line:    "conch hardware product ",
+			words:   "conch hardware product ''",
+			cword:   3,
+			offer:   "'Big Box'",
+			args:    "hardware|product|",
+			choices: `Big\ Box`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st.Expect(t, ioutil.WriteFile(log.Name(), nil, 0600), nil)
+
+			script := completionScripts["bash"] + `
+conch() {
+	shift
+	local IFS='|'
+	echo "$*" >&2
+	printf '%s\n' ` + test.offer + `
+}
+COMP_LINE='` + test.line + `'
+COMP_POINT=${#COMP_LINE}
+COMP_WORDS=(` + test.words + `)
+COMP_CWORD=` + strconv.Itoa(test.cword) + `
+_conch
+IFS='|'
+echo "${COMPREPLY[*]}"
+`
+			cmd := exec.Command(bash, "-c", script)
+			cmd.Env = append(os.Environ(), "CONCH_COMPLETION_LOG="+log.Name())
+			out, err := cmd.Output()
+			st.Expect(t, err, nil)
+			st.Expect(t, strings.TrimSuffix(string(out), "\n"), test.choices)
+
+			args, err := ioutil.ReadFile(log.Name())
+			st.Expect(t, err, nil)
+			st.Expect(t, strings.TrimSuffix(string(args), "\n"), test.args)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package shell contains the interactive shell, which runs conch commands
+// one after another over a single connection to the API, and tab completion
+// for the shells conch itself runs in
+package shell
+
+import (
+	"github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/util"
+)
+
+// Init adds the shell and completion commands, and --interactive, to the
+// app. build makes a fresh copy of the whole app, which the shell runs each
+// of its commands with.
+func Init(app *cli.Cli, build func() *cli.Cli) {
+	interactive := app.BoolOpt("interactive i", false, "Start the interactive shell. Same as the 'shell' command")
+
+	app.Action = func() {
+		// Complete runs the app without a command, only to set it up
+		if util.Completing {
+			return
+		}
+		if !*interactive {
+			app.PrintHelp()
+			util.Exit(2)
+		}
+		start(build)
+	}
+
+	app.Command(
+		"shell sh",
+		"Start an interactive shell, with history, tab completion, and a single login for all of its commands",
+		func(cmd *cli.Cmd) {
+			cmd.Action = func() {
+				start(build)
+			}
+		},
+	)
+
+	app.Command(
+		"completion",
+		"Print a script that sets up tab completion of commands, names, and IDs for bash, zsh, or fish",
+		completionCmd,
+	)
+}
@@ -0,0 +1,568 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+	"github.com/joyent/conch-shell/pkg/util"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const help = `Besides the commands of conch itself, the shell knows:
+
+  use                  Show what is in use
+  use workspace NAME   Use a workspace, by name or ID
+  use rack NAME        Use a rack, by name or ID, in the workspace in use
+  use device ID        Use a device
+  use TYPE             Stop using a workspace, rack, or device
+  use none             Stop using anything
+  help [COMMAND...]    Show this, or the help of a command
+  exit [CODE], quit    Leave the shell. So does Ctrl-D
+
+Commands that take the ID of a workspace, rack, or device get the one in use
+when they are given none, so 'workspace devices' lists the devices of the
+workspace in use. Tab completes commands, options, and IDs.
+
+Options that pick the profile, or shape the connection to the API, go before
+'shell' when starting it. Output options, like -o and --columns, work for
+each command.
+`
+
+// builtins are the commands of the shell itself
+var builtins = []string{"exit", "help", "quit", "use"}
+
+// contexts are the kinds of things "use" can pick, in the order the prompt
+// shows them
+var contexts = []string{"workspace", "rack", "device"}
+
+// contextArgs says which commands get the ID of what is in use, if they're
+// given none, by the path of the command
+var contextArgs = map[string]string{
+	"workspace":      "workspace",
+	"workspace rack": "rack",
+	"rack":           "rack",
+	"device":         "device",
+}
+
+// lineOptions are the global options that apply to a single command. The
+// rest set up the connection to the API, which the shell does once.
+var lineOptions = map[string]bool{
+	"--output":  true,
+	"--json":    true,
+	"--columns": true,
+	"--sort-by": true,
+	"--desc":    true,
+	"--where":   true,
+}
+
+// inUse is something picked with "use"
+type inUse struct {
+	ID   string
+	Name string
+}
+
+// session is a run of the shell
+type session struct {
+	build  func() *cli.Cli
+	tree   *command
+	inUse  map[string]inUse
+	status int
+
+	// completing is set when completing for another shell, rather than
+	// running this one. There are no built-ins then, and the API is only
+	// connected to once a lookup needs it. triedAPI is set once it was.
+	completing bool
+	triedAPI   bool
+}
+
+// start runs the shell until it is told to exit, or runs out of input, and
+// exits with the status of the last command
+func start(build func() *cli.Cli) {
+	if util.Interactive {
+		util.Bail(errors.New("this is the shell already"))
+	}
+
+	util.Interactive = true
+	s := &session{
+		build: build,
+		tree:  commandTree(build()),
+		inUse: make(map[string]inUse),
+	}
+
+	// Logging in up front shows a bad token right away, rather than at the
+	// first command. It's no reason to stop, the profile commands can fix
+	// it.
+	if util.IgnoreConfig || (util.ActiveProfile != nil) {
+		s.guard(util.BuildAPIAndVerifyLogin)
+	}
+
+	var err error
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		err = s.interact(fd)
+	} else {
+		s.script(os.Stdin)
+	}
+
+	util.Interactive = false
+	if err != nil {
+		util.Bail(err)
+	}
+	if s.status != 0 {
+		util.Exit(s.status)
+	}
+}
+
+// keyInterrupt is what Ctrl-C is passed on to the terminal as. The terminal
+// takes Ctrl-C itself to be the end of input, so it's swapped for a key it
+// leaves to autoComplete, which clears the line.
+const keyInterrupt = '\x1c'
+
+// interruptReader swaps Ctrl-C for keyInterrupt
+type interruptReader struct {
+	io.Reader
+}
+
+func (r interruptReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == '\x03' {
+			p[i] = keyInterrupt
+		}
+	}
+	return n, err
+}
+
+// interact reads commands from the terminal, with line editing, history,
+// and completion, until Ctrl-D or exit. Ctrl-C clears the line.
+func (s *session) interact(fd int) error {
+	fmt.Printf(
+		"Conch Shell v%s. Type 'help' for help, and 'exit' or Ctrl-D to leave.\n",
+		util.Version,
+	)
+
+	t := terminal.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{interruptReader{os.Stdin}, os.Stdout}, "")
+	t.AutoCompleteCallback = s.autoComplete(t)
+
+	for {
+		t.SetPrompt(s.prompt())
+		if width, height, err := terminal.GetSize(fd); (err == nil) && (width > 0) {
+			_ = t.SetSize(width, height)
+		}
+
+		// Commands run with the terminal as it was, so that their output
+		// and prompts work as usual
+		state, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		line, err := t.ReadLine()
+		_ = terminal.Restore(fd, state)
+
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !s.exec(line) {
+			return nil
+		}
+	}
+}
+
+// script runs commands from a file or pipe, one per line
+func (s *session) script(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if !s.exec(scanner.Text()) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		s.status = 1
+	}
+}
+
+func (s *session) prompt() string {
+	p := "conch"
+	if util.ActiveProfile != nil {
+		p += " (" + util.ActiveProfile.Name + ")"
+	}
+	for _, kind := range contexts {
+		if item, ok := s.inUse[kind]; ok {
+			p += " " + kind + ":" + item.Name
+		}
+	}
+	return p + "> "
+}
+
+// exec runs a line of input. It returns false once the shell should exit.
+func (s *session) exec(line string) bool {
+	words, err := splitWords(line)
+	if err != nil {
+		fmt.Println(err)
+		s.status = 2
+		return true
+	}
+	if (len(words) > 0) && (words[0] == "conch") {
+		words = words[1:]
+	}
+	if (len(words) == 0) || strings.HasPrefix(words[0], "#") {
+		return true
+	}
+
+	// Output options go with the command they were given to
+	_ = util.SetOutput(util.OutputTable)
+	util.List = util.ListOptions{}
+
+	switch words[0] {
+	case "exit", "quit":
+		s.status = 0
+		if len(words) > 1 {
+			code, err := strconv.Atoi(words[1])
+			if err != nil {
+				fmt.Printf("'%s' is not an exit code\n", words[1])
+				s.status = 2
+				return true
+			}
+			s.status = code
+		}
+		return false
+
+	case "help":
+		if len(words) == 1 {
+			fmt.Print(help)
+			return true
+		}
+		s.status = s.runCommand(append(words[1:], "--help"))
+
+	case "use":
+		s.status = s.use(words[1:])
+
+	default:
+		s.status = s.run(words)
+	}
+
+	return true
+}
+
+// run runs a conch command
+func (s *session) run(words []string) int {
+	opts, i := s.globalOptions(words)
+	if (i < len(words)) && (s.tree.sub(words[i]) == nil) {
+		fmt.Printf("Unknown command '%s'. Type 'help' for help\n", words[i])
+		return 2
+	}
+
+	for _, word := range opts {
+		name := strings.SplitN(word, "=", 2)[0]
+		o, ok := s.tree.option(name)
+		if !ok {
+			continue
+		}
+
+		perLine := false
+		for _, n := range o.names {
+			perLine = perLine || lineOptions[n]
+		}
+		if !perLine {
+			fmt.Printf("%s can only be given when starting the shell\n", name)
+			return 2
+		}
+	}
+
+	code := s.runCommand(s.expand(words))
+
+	// Profile commands may change which profile is active, or its
+	// credentials, so the next command starts afresh
+	if _, path, _, _ := s.tree.walk(words); (len(path) > 0) && (path[0] == "profile") {
+		util.ForgetAPI()
+	}
+
+	return code
+}
+
+// runCommand runs the words as a conch command, on a fresh copy of the app
+func (s *session) runCommand(words []string) int {
+	return s.guard(func() {
+		if err := s.build().Run(append([]string{"conch"}, words...)); err != nil {
+			util.Exit(2)
+		}
+	})
+}
+
+// guard runs f, and returns the exit code it gave to util.Exit, if any. A
+// command that panics gets reported, rather than taking the shell down
+// with it.
+func (s *session) guard(f func()) (code int) {
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case util.ExitCode:
+			code = int(r)
+		default:
+			fmt.Fprintf(os.Stderr, "panic: %v\n\n%s\n", r, debug.Stack())
+			code = 2
+		}
+	}()
+
+	f()
+	return 0
+}
+
+// globalOptions returns the options that come before the command, and
+// where the command starts
+func (s *session) globalOptions(words []string) ([]string, int) {
+	opts := make([]string, 0)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") {
+			return opts, i
+		}
+
+		opts = append(opts, word)
+		if o, ok := s.tree.option(word); ok && !o.bool {
+			i++
+		}
+	}
+	return opts, len(words)
+}
+
+// expand adds the IDs of what is in use to the commands that were given
+// none
+func (s *session) expand(words []string) []string {
+	out := make([]string, 0, len(words)+2)
+	cmd := s.tree
+	path := make([]string, 0)
+
+	for i, word := range words {
+		out = append(out, word)
+
+		sub := cmd.sub(word)
+		if sub == nil {
+			continue
+		}
+		cmd = sub
+		path = append(path, sub.name())
+
+		kind, ok := contextArgs[strings.Join(path, " ")]
+		if !ok {
+			continue
+		}
+		item, ok := s.inUse[kind]
+		if !ok {
+			continue
+		}
+		if (i+1 == len(words)) || (cmd.sub(words[i+1]) != nil) {
+			out = append(out, item.ID)
+		}
+	}
+
+	return out
+}
+
+// use picks, shows, or drops what commands work on
+func (s *session) use(args []string) int {
+	if len(args) == 0 {
+		if len(s.inUse) == 0 {
+			fmt.Println("Nothing is in use. Try 'use workspace NAME'")
+			return 0
+		}
+		for _, kind := range contexts {
+			if item, ok := s.inUse[kind]; ok {
+				fmt.Printf("%s: %s (%s)\n", kind, item.Name, item.ID)
+			}
+		}
+		return 0
+	}
+
+	kind := args[0]
+	if kind == "none" {
+		s.inUse = make(map[string]inUse)
+		return 0
+	}
+
+	known := false
+	for _, k := range contexts {
+		known = known || (k == kind)
+	}
+	if !known {
+		fmt.Printf(
+			"Cannot use a '%s'. Try one of: %s, or none\n",
+			kind,
+			strings.Join(contexts, ", "),
+		)
+		return 2
+	}
+
+	if len(args) == 1 {
+		delete(s.inUse, kind)
+		if kind == "workspace" {
+			delete(s.inUse, "rack")
+		}
+		return 0
+	}
+
+	name := args[1]
+	var item inUse
+
+	code := s.guard(func() {
+		util.BuildAPIAndVerifyLogin()
+
+		var err error
+		item, err = s.lookup(kind, name)
+		if err != nil {
+			util.Bail(err)
+		}
+	})
+	if code != 0 {
+		return code
+	}
+
+	s.inUse[kind] = item
+	if kind == "workspace" {
+		// Racks belong to a workspace
+		delete(s.inUse, "rack")
+	}
+	return 0
+}
+
+// lookup finds the ID of the named workspace, rack, or device
+func (s *session) lookup(kind string, name string) (inUse, error) {
+	item := inUse{Name: name}
+
+	switch kind {
+	case "workspace":
+		id, err := util.MagicWorkspaceID(name)
+		if err != nil {
+			return item, err
+		}
+		item.ID = id.String()
+
+	case "rack":
+		var id uuid.UUID
+		ws, err := s.workspace(nil)
+		if err == nil {
+			id, err = util.MagicWorkspaceRackID(ws, name)
+		} else {
+			id, err = util.MagicRackID(name)
+		}
+		if err != nil {
+			return item, err
+		}
+		item.ID = id.String()
+
+	case "device":
+		d, err := util.API.GetDevice(name)
+		if err != nil {
+			return item, err
+		}
+		item.ID = d.ID
+	}
+
+	return item, nil
+}
+
+// workspace finds the workspace words are about: the one given to the
+// workspace command, or else the one in use, or else the one of the profile
+func (s *session) workspace(words []string) (uuid.UUID, error) {
+	for i, word := range words {
+		cmd := s.tree.sub(word)
+		if cmd == nil {
+			continue
+		}
+		if (cmd.name() == "workspace") && (i+1 < len(words)) &&
+			(cmd.sub(words[i+1]) == nil) && !strings.HasPrefix(words[i+1], "-") {
+			return util.MagicWorkspaceID(words[i+1])
+		}
+		break
+	}
+
+	if item, ok := s.inUse["workspace"]; ok {
+		return uuid.FromString(item.ID)
+	}
+	if (util.ActiveProfile != nil) && !uuid.Equal(util.ActiveProfile.WorkspaceUUID, uuid.UUID{}) {
+		return util.ActiveProfile.WorkspaceUUID, nil
+	}
+	return uuid.UUID{}, errors.New("no workspace is in use")
+}
+
+// splitWords splits a line into words the way sh would, minus the
+// expansions: words are separated by whitespace, and quotes and
+// backslashes keep it from doing so
+func splitWords(line string) ([]string, error) {
+	words := make([]string, 0)
+
+	var (
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+
+		case (r == '\\') && (quote != '\''):
+			escaped = true
+			inWord = true
+
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+
+		case (r == '\'') || (r == '"'):
+			quote = r
+			inWord = true
+
+		case (r == ' ') || (r == '\t'):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return words, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return words, errors.New("nothing to escape at the end of the line")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
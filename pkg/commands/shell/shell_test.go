@@ -0,0 +1,289 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package shell
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/conchtest"
+	"github.com/joyent/conch-shell/pkg/util"
+	"github.com/nbio/st"
+)
+
+// testApp has the shape of the parts of the real app that the shell cares
+// about
+func testApp() *cli.Cli {
+	app := cli.App("conch", "test")
+	app.ErrorHandling = flag.ContinueOnError
+	app.Action = func() {}
+	app.StringOpt("output o", "", "Output format")
+	app.BoolOpt("json j", false, "JSON output")
+	app.StringOpt("profile p", "", "Profile")
+
+	withID := func(subs ...string) cli.CmdInitializer {
+		return func(cmd *cli.Cmd) {
+			cmd.StringArg("ID", "", "ID")
+			cmd.Spec = "[ID]"
+			for _, sub := range subs {
+				cmd.Command(sub, sub, func(*cli.Cmd) {})
+			}
+		}
+	}
+
+	app.Command("workspace ws", "A workspace", func(cmd *cli.Cmd) {
+		cmd.StringArg("ID", "", "ID")
+		cmd.BoolOpt("full", false, "More")
+		cmd.Spec = "[ID]"
+		cmd.Command("devices", "Devices", func(*cli.Cmd) {})
+		cmd.Command("racks", "Racks", func(*cli.Cmd) {})
+		cmd.Command("rack r", "A rack", withID("layout"))
+	})
+	app.Command("workspaces wss", "Workspaces", func(*cli.Cmd) {})
+	app.Command("rack", "A rack", withID("layout"))
+	app.Command("device d", "A device", withID("settings", "tags"))
+	app.Command("completion", "Completion", func(cmd *cli.Cmd) {
+		cmd.StringArg("SHELL", "", "Shell")
+	})
+	return app
+}
+
+func testSession() *session {
+	return &session{
+		build: testApp,
+		tree:  commandTree(testApp()),
+		inUse: make(map[string]inUse),
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		line  string
+		words []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"workspace devices", []string{"workspace", "devices"}},
+		{"  workspace \t devices  ", []string{"workspace", "devices"}},
+		{`device settings set 'a b' "c d"`, []string{"device", "settings", "set", "a b", "c d"}},
+		{`a\ b`, []string{"a b"}},
+		{`'it'\''s'`, []string{"it's"}},
+		{`"say \"hi\""`, []string{`say "hi"`}},
+		{`'no \escape'`, []string{`no \escape`}},
+		{`'a'"b"c`, []string{"abc"}},
+		{`'' ""`, []string{"", ""}},
+		{"--where 'health=FAIL'", []string{"--where", "health=FAIL"}},
+		{"# a comment", []string{"#", "a", "comment"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			words, err := splitWords(test.line)
+			st.Expect(t, err, nil)
+			st.Expect(t, words, test.words)
+		})
+	}
+
+	for _, bad := range []string{`'open`, `"open`, `trailing\`} {
+		t.Run(bad, func(t *testing.T) {
+			_, err := splitWords(bad)
+			st.Reject(t, err, nil)
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	s := testSession()
+
+	expand := func(line string) string {
+		words, err := splitWords(line)
+		st.Expect(t, err, nil)
+		return strings.Join(s.expand(words), " ")
+	}
+
+	// Nothing in use leaves everything alone
+	st.Expect(t, expand("workspace devices"), "workspace devices")
+
+	s.inUse["workspace"] = inUse{ID: "WS", Name: "GLOBAL"}
+	s.inUse["rack"] = inUse{ID: "RACK", Name: "A01"}
+	s.inUse["device"] = inUse{ID: "DEV", Name: "DEV"}
+
+	tests := map[string]string{
+		"workspace":                  "workspace WS",
+		"workspace devices":          "workspace WS devices",
+		"ws devices":                 "ws WS devices",
+		"workspace other devices":    "workspace other devices",
+		"workspace rack layout":      "workspace WS rack RACK layout",
+		"workspace rack B02 layout":  "workspace WS rack B02 layout",
+		"workspace other rack":       "workspace other rack RACK",
+		"rack layout":                "rack RACK layout",
+		"rack B02 layout":            "rack B02 layout",
+		"device settings":            "device DEV settings",
+		"d tags":                     "d DEV tags",
+		"workspaces":                 "workspaces",
+		"-o json workspace devices":  "-o json workspace WS devices",
+		"unknown workspace devices":  "unknown workspace WS devices",
+		"completion bash":            "completion bash",
+		"workspace --full devices":   "workspace --full devices",
+		"workspace devices --full":   "workspace WS devices --full",
+		"device settings extra args": "device DEV settings extra args",
+	}
+	for line, want := range tests {
+		st.Expect(t, expand(line), want)
+	}
+}
+
+func TestUse(t *testing.T) {
+	s := testSession()
+	s.inUse["workspace"] = inUse{ID: "WS", Name: "GLOBAL"}
+	s.inUse["rack"] = inUse{ID: "RACK", Name: "A01"}
+	s.inUse["device"] = inUse{ID: "DEV", Name: "DEV"}
+
+	st.Expect(t, s.use([]string{"bogus"}), 2)
+	st.Expect(t, len(s.inUse), 3)
+
+	st.Expect(t, s.use([]string{"device"}), 0)
+	_, ok := s.inUse["device"]
+	st.Expect(t, ok, false)
+
+	// Racks belong to a workspace
+	st.Expect(t, s.use([]string{"workspace"}), 0)
+	st.Expect(t, len(s.inUse), 0)
+
+	s.inUse["workspace"] = inUse{ID: "WS", Name: "GLOBAL"}
+	st.Expect(t, s.use([]string{"none"}), 0)
+	st.Expect(t, len(s.inUse), 0)
+}
+
+func TestLookup(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+	rack := seedRack(srv)
+	srv.AddDevice(conch.Device{ID: "SERIAL1"})
+
+	util.API = srv.Client()
+	defer func() { util.API = nil }()
+
+	s := testSession()
+
+	_, err := s.workspace(nil)
+	st.Reject(t, err, nil)
+
+	ws, err := s.lookup("workspace", "GLOBAL")
+	st.Expect(t, err, nil)
+	st.Expect(t, ws, inUse{ID: srv.GlobalWorkspace.ID.String(), Name: "GLOBAL"})
+	s.inUse["workspace"] = ws
+
+	id, err := s.workspace(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, id, srv.GlobalWorkspace.ID)
+
+	// The workspace on the command line wins over the one in use
+	other := conch.Workspace{ID: rack.ID}
+	id, err = s.workspace([]string{"workspace", other.ID.String(), "racks"})
+	st.Expect(t, err, nil)
+	st.Expect(t, id, other.ID)
+
+	r, err := s.lookup("rack", "A01")
+	st.Expect(t, err, nil)
+	st.Expect(t, r, inUse{ID: rack.ID.String(), Name: "A01"})
+
+	_, err = s.lookup("rack", "Z99")
+	st.Reject(t, err, nil)
+
+	d, err := s.lookup("device", "SERIAL1")
+	st.Expect(t, err, nil)
+	st.Expect(t, d, inUse{ID: "SERIAL1", Name: "SERIAL1"})
+
+	_, err = s.lookup("device", "NOPE")
+	st.Reject(t, err, nil)
+}
+
+func TestComplete(t *testing.T) {
+	s := testSession()
+
+	complete := func(line string, prefix string) []string {
+		words, err := splitWords(line)
+		st.Expect(t, err, nil)
+		return s.complete(words, prefix)
+	}
+
+	t.Run("Commands", func(t *testing.T) {
+		st.Expect(t, complete("", ""), []string{
+			"completion", "device", "exit", "help", "quit", "rack", "use",
+			"workspace", "workspaces",
+		})
+		st.Expect(t, complete("", "work"), []string{"workspace", "workspaces"})
+		st.Expect(t, complete("conch", "de"), []string{"device"})
+		st.Expect(t, complete("", "d"), []string{"device"})
+		st.Expect(t, complete("", "nope"), []string{})
+		st.Expect(t, complete("help", "ra"), []string{"rack"})
+		st.Expect(t, len(complete("exit", "")), 0)
+	})
+
+	t.Run("Subcommands", func(t *testing.T) {
+		st.Expect(t, complete("workspace GLOBAL", ""), []string{"devices", "rack", "racks"})
+		st.Expect(t, complete("workspace GLOBAL", "r"), []string{"rack", "racks"})
+		st.Expect(t, complete("workspace GLOBAL rack A01", ""), []string{"layout"})
+		st.Expect(t, complete("device SERIAL1", "s"), []string{"settings"})
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		st.Expect(t, complete("", "--j"), []string{"--json"})
+		st.Expect(t, complete("", "-"), []string{
+			"--json", "--output", "--profile", "-j", "-o", "-p",
+		})
+		st.Expect(t, complete("workspace", "--"), []string{"--full"})
+		st.Expect(t, complete("--output", ""), []string{
+			"csv", "json", "ndjson", "table", "tsv", "wide", "yaml",
+		})
+		st.Expect(t, complete("-o", "ya"), []string{"yaml"})
+		st.Expect(t, complete("-o yaml", "work"), []string{"workspace", "workspaces"})
+	})
+
+	t.Run("Local", func(t *testing.T) {
+		st.Expect(t, complete("completion", ""), []string{"bash", "fish", "zsh"})
+		st.Expect(t, complete("use", ""), []string{"device", "none", "rack", "workspace"})
+		st.Expect(t, complete("use", "w"), []string{"workspace"})
+		st.Expect(t, len(complete("use bogus", "")), 0)
+	})
+
+	t.Run("IDs", func(t *testing.T) {
+		srv := conchtest.NewServer()
+		defer srv.Close()
+		seedRack(srv)
+		srv.AddDevice(conch.Device{ID: "SERIAL1"})
+
+		util.API = srv.Client()
+		defer func() { util.API = nil }()
+
+		st.Expect(t, complete("use workspace", ""), []string{"GLOBAL"})
+		st.Expect(t, complete("workspace", ""), []string{"GLOBAL", "devices", "rack", "racks"})
+		st.Expect(t, complete("workspace", "G"), []string{"GLOBAL"})
+		st.Expect(t, complete("workspace GLOBAL rack", ""), []string{"A01", "layout"})
+		st.Expect(t, complete("use rack", ""), []string{})
+
+		// With a workspace in use, its ID is left out, so it isn't offered
+		s.inUse["workspace"] = inUse{ID: srv.GlobalWorkspace.ID.String(), Name: "GLOBAL"}
+		defer delete(s.inUse, "workspace")
+
+		st.Expect(t, complete("workspace", ""), []string{"devices", "rack", "racks"})
+		st.Expect(t, complete("workspace rack", ""), []string{"A01", "layout"})
+		st.Expect(t, complete("use rack", ""), []string{"A01"})
+	})
+}
+
+// seedRack adds a rack named A01, which the global workspace has
+func seedRack(srv *conchtest.Server) conch.Rack {
+	dc := srv.AddDatacenter(conch.Datacenter{Vendor: "Acme", Region: "us-east-1"})
+	room := srv.AddRoom(conch.Room{DatacenterID: dc.ID, AZ: "us-east-1a", Alias: "room1"})
+	role := srv.AddRackRole(conch.RackRole{Name: "compute", RackSize: 42})
+	return srv.AddRack(conch.Rack{DatacenterRoomID: room.ID, RoleID: role.ID, Name: "A01"})
+}
@@ -0,0 +1,155 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package shell
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/jawher/mow.cli"
+)
+
+// command is a node in the tree of commands, options, and arguments of the
+// app, as needed for completion
+type command struct {
+	names   []string
+	desc    string
+	options []option
+	args    []string
+	subs    []*command
+}
+
+type option struct {
+	names []string
+	bool  bool
+}
+
+// name is the main name of the command, like "workspace" for "workspace ws"
+func (c *command) name() string {
+	if len(c.names) == 0 {
+		return ""
+	}
+	return c.names[0]
+}
+
+// sub finds the subcommand with the given name or alias
+func (c *command) sub(name string) *command {
+	for _, sub := range c.subs {
+		for _, n := range sub.names {
+			if n == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+// option finds the option with the given name, like "--output" or "-o"
+func (c *command) option(name string) (option, bool) {
+	for _, o := range c.options {
+		for _, n := range o.names {
+			if n == name {
+				return o, true
+			}
+		}
+	}
+	return option{}, false
+}
+
+// commandTree reads the tree of commands out of an app. mow.cli keeps it to
+// itself, and only sets up a command when it runs it, so this digs through
+// the fields of the app and sets up every command itself. The app is no
+// good for running afterwards. Should mow.cli change enough for this to
+// fail, the tree comes back empty, and completion offers nothing.
+func commandTree(app *cli.Cli) (tree *command) {
+	defer func() {
+		if r := recover(); r != nil {
+			tree = &command{}
+		}
+	}()
+
+	return readCommand(reflect.ValueOf(app.Cmd).Elem(), false)
+}
+
+func readCommand(v reflect.Value, setup bool) *command {
+	if setup {
+		if initCmd := field(v, "init").Interface().(cli.CmdInitializer); initCmd != nil {
+			initCmd(v.Addr().Interface().(*cli.Cmd))
+		}
+	}
+
+	c := &command{
+		names: field(v, "aliases").Interface().([]string),
+		desc:  field(v, "desc").Interface().(string),
+	}
+
+	options := field(v, "options")
+	for i := 0; i < options.Len(); i++ {
+		opt := options.Index(i).Elem()
+
+		o := option{names: opt.FieldByName("Names").Interface().([]string)}
+		if value, ok := opt.FieldByName("Value").Interface().(flag.Value); ok {
+			if b, ok := value.(interface{ IsBoolFlag() bool }); ok {
+				o.bool = b.IsBoolFlag()
+			}
+		}
+		c.options = append(c.options, o)
+	}
+
+	args := field(v, "args")
+	for i := 0; i < args.Len(); i++ {
+		c.args = append(c.args, args.Index(i).Elem().FieldByName("Name").Interface().(string))
+	}
+
+	subs := field(v, "commands")
+	for i := 0; i < subs.Len(); i++ {
+		c.subs = append(c.subs, readCommand(subs.Index(i).Elem(), true))
+	}
+
+	return c
+}
+
+// field gets at a field of a struct, exported or not
+func field(v reflect.Value, name string) reflect.Value {
+	f := v.FieldByName(name)
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+}
+
+// walk follows words through the tree, the way mow.cli would: options and
+// arguments of a command come before its subcommands. It returns the
+// command the words end up in, its path, like "workspace rack", and how
+// many arguments it was given. expectsValue is set if the last word is an
+// option that needs a value.
+func (c *command) walk(words []string) (cmd *command, path []string, args int, expectsValue bool) {
+	cmd = c
+	for _, word := range words {
+		if expectsValue {
+			expectsValue = false
+			continue
+		}
+
+		if sub := cmd.sub(word); sub != nil {
+			cmd = sub
+			path = append(path, sub.name())
+			args = 0
+			continue
+		}
+
+		if strings.HasPrefix(word, "-") && (len(word) > 1) {
+			if !strings.Contains(word, "=") {
+				o, ok := cmd.option(word)
+				expectsValue = ok && !o.bool
+			}
+			continue
+		}
+
+		args++
+	}
+	return cmd, path, args, expectsValue
+}
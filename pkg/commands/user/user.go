@@ -41,7 +41,7 @@ func getProfile(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(profile)
+			util.Print(profile)
 			return
 		}
 
@@ -68,7 +68,7 @@ func getSettings(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(settings)
+			util.Print(settings)
 		} else {
 			if len(settings) > 0 {
 				for k, v := range settings {
@@ -98,7 +98,7 @@ func getSetting(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(value)
+			util.Print(value)
 		} else {
 			fmt.Println(value)
 		}
@@ -156,13 +156,13 @@ func listTokens(app *cli.Cmd) {
 			util.Bail(err)
 		}
 		if util.JSON {
-			util.JSONOut(tokens)
+			util.Print(tokens)
 			return
 		}
 
 		sort.Sort(tokens)
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{"Name", "Created", "Last Used"})
 
 		for _, t := range tokens {
@@ -171,7 +171,7 @@ func listTokens(app *cli.Cmd) {
 				timeStr = util.TimeStr(t.LastUsed)
 			}
 
-			table.Append([]string{
+			table.AppendItem(t, []string{
 				t.Name,
 				util.TimeStr(t.Created),
 				timeStr,
@@ -194,7 +194,7 @@ func createToken(app *cli.Cmd) {
 			util.Bail(err)
 		}
 		if util.JSON {
-			util.JSONOut(token)
+			util.Print(token)
 			return
 		}
 
@@ -237,7 +237,7 @@ func getToken(cmd *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(token)
+			util.Print(token)
 			return
 		}
 
@@ -20,11 +20,11 @@ import (
 type validationPlans []conch.ValidationPlan
 
 func (vps validationPlans) renderTable() {
-	table := util.GetMarkdownTable()
+	table := util.NewTable()
 	table.SetHeader([]string{"Id", "Name", "Description"})
 
 	for _, vp := range vps {
-		table.Append([]string{vp.ID.String(), vp.Name, vp.Description})
+		table.AppendItem(vp, []string{vp.ID.String(), vp.Name, vp.Description})
 	}
 
 	table.Render()
@@ -41,7 +41,7 @@ func getValidationPlans(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(validationPlans)
+			util.Print(validationPlans)
 			return
 		}
 		validationPlans.renderTable()
@@ -56,7 +56,7 @@ func getValidationPlan(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(validationPlan)
+			util.Print(validationPlan)
 			return
 		}
 		validationPlans := validationPlans{validationPlan}
@@ -84,7 +84,7 @@ func showValidationPlanValidations(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(validations)
+			util.Print(validations)
 			return
 		}
 
@@ -119,7 +119,7 @@ func testValidationPlan(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(validationResults)
+			util.Print(validationResults)
 			return
 		}
 		validationResults.renderTable()
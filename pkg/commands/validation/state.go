@@ -19,7 +19,7 @@ import (
 type validationStates []conch.ValidationState
 
 func (vs validationStates) renderTable(validationPlans []conch.ValidationPlan, validations []conch.Validation) {
-	table := util.GetMarkdownTable()
+	table := util.NewTable()
 
 	planNameMap := make(map[uuid.UUID]string)
 	for _, vp := range validationPlans {
@@ -61,7 +61,7 @@ func (vs validationStates) renderTable(validationPlans []conch.ValidationPlan, v
 		for vName, vResult := range resultGroup {
 			results = append(results, vName+": "+vResult)
 		}
-		table.Append([]string{
+		table.AppendItem(v, []string{
 			v.DeviceID,
 			v.Status,
 			v.Completed.String(),
@@ -86,7 +86,7 @@ func getDeviceValidationStates(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(validationStates)
+			util.Print(validationStates)
 			return
 		}
 		validationPlans, err := util.API.GetValidationPlans()
@@ -21,7 +21,7 @@ import (
 
 func renderTableValidations(vs conch.Validations, showDeactivated bool) {
 	sort.Sort(vs)
-	table := util.GetMarkdownTable()
+	table := util.NewTable()
 
 	if showDeactivated {
 		table.SetHeader([]string{"Id", "Name", "Version", "Active", "Description"})
@@ -35,7 +35,7 @@ func renderTableValidations(vs conch.Validations, showDeactivated bool) {
 			if v.Deactivated.IsZero() {
 				active = "X"
 			}
-			table.Append([]string{
+			table.AppendItem(v, []string{
 				v.ID.String(),
 				v.Name,
 				strconv.Itoa(v.Version),
@@ -43,7 +43,7 @@ func renderTableValidations(vs conch.Validations, showDeactivated bool) {
 				v.Description,
 			})
 		} else {
-			table.Append([]string{
+			table.AppendItem(v, []string{
 				v.ID.String(),
 				v.Name,
 				strconv.Itoa(v.Version),
@@ -58,12 +58,12 @@ func renderTableValidations(vs conch.Validations, showDeactivated bool) {
 type validationResults []conch.ValidationResult
 
 func (rs validationResults) renderTable() {
-	table := util.GetMarkdownTable()
+	table := util.NewTable()
 
 	table.SetHeader([]string{"Status", "Category", "Message", "Hint", "Component ID"})
 
 	for _, r := range rs {
-		table.Append([]string{r.Status, r.Category, r.Message, r.Hint, r.ComponentID})
+		table.AppendItem(r, []string{r.Status, r.Category, r.Message, r.Hint, r.ComponentID})
 	}
 
 	table.Render()
@@ -90,7 +90,7 @@ func getValidations(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(validations)
+			util.Print(validations)
 			return
 		}
 
@@ -125,7 +125,7 @@ func testValidation(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(validationResults)
+			util.Print(validationResults)
 			return
 		}
 		validationResults.renderTable()
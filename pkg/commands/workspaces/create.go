@@ -38,7 +38,7 @@ func createSubWorkspace(app *cli.Cmd) {
 			util.Bail(err)
 		}
 		if util.JSON {
-			util.JSONOut(ws)
+			util.Print(ws)
 		} else {
 			fmt.Printf("Workspace '%s' created with ID '%s'\n", ws.Name, ws.ID)
 		}
@@ -64,7 +64,7 @@ Those days are behind us. New users must now be created via the 'admin user' int
 			role,
 		)
 
-		if err == conch.ErrDataNotFound {
+		if errors.Is(err, conch.ErrDataNotFound) {
 			util.Bail(errors.New("data not found. Likely, the user does not exist. See --help for next steps"))
 		}
 
@@ -54,14 +54,14 @@ func getAll(app *cli.Cmd) {
 		}
 		sort.Sort(workspaces)
 		if util.JSON {
-			util.JSONOut(workspaces)
+			util.Print(workspaces)
 			return
 		}
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{"Role", "Id", "Name", "Description"})
 
 		for _, w := range workspaces {
-			table.Append([]string{w.Role, w.ID.String(), w.Name, w.Description})
+			table.AppendItem(w, []string{w.Role, w.ID.String(), w.Name, w.Description})
 		}
 
 		table.Render()
@@ -126,7 +126,7 @@ func getOne(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(workspace)
+			util.Print(workspace)
 			return
 		}
 
@@ -148,11 +148,11 @@ func getUsers(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(users)
+			util.Print(users)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{"Name", "Email", "Role", "Role Via"})
 
 		for _, u := range users {
@@ -164,7 +164,7 @@ func getUsers(app *cli.Cmd) {
 				}
 				roleVia = ws.Name
 			}
-			table.Append([]string{u.Name, u.Email, u.Role, roleVia})
+			table.AppendItem(u, []string{u.Name, u.Email, u.Role, roleVia})
 		}
 
 		table.Render()
@@ -201,7 +201,7 @@ func getDevices(app *cli.Cmd) {
 				for _, d := range devices {
 					ids = append(ids, d.ID)
 				}
-				util.JSONOut(ids)
+				util.Print(ids)
 				return
 			}
 			for _, d := range devices {
@@ -211,7 +211,23 @@ func getDevices(app *cli.Cmd) {
 		}
 
 		if *fullOutput {
-			locs := make(map[uuid.UUID]conch.DeviceLocation)
+			// Devices in the same rack share a location, give or take the
+			// slot, so only one device per rack is looked up
+			rackDevice := make(map[uuid.UUID]string)
+			ids := make([]string, 0)
+
+			for _, d := range devices {
+				if uuid.Equal(d.RackID, uuid.UUID{}) {
+					continue
+				}
+				if _, ok := rackDevice[d.RackID]; !ok {
+					rackDevice[d.RackID] = d.ID
+					ids = append(ids, d.ID)
+				}
+			}
+
+			// Failed lookups just leave the location blank, as before
+			locs, _ := util.API.GetDeviceLocations(ids)
 
 			dLocs := make([]conch.Device, 0)
 
@@ -219,14 +235,9 @@ func getDevices(app *cli.Cmd) {
 				if uuid.Equal(d.RackID, uuid.UUID{}) {
 					continue
 				}
-				if loc, ok := locs[d.RackID]; ok {
+				if loc, ok := locs[rackDevice[d.RackID]]; ok {
+					loc.TargetHardwareProduct = conch.HardwareProductTarget{}
 					d.Location = loc
-				} else {
-					if loc, err := util.API.GetDeviceLocation(d.ID); err == nil {
-						loc.TargetHardwareProduct = conch.HardwareProductTarget{}
-						locs[loc.Rack.ID] = loc
-						d.Location = loc
-					}
 				}
 
 				dLocs = append(dLocs, d)
@@ -248,11 +259,11 @@ func getRacks(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(racks)
+			util.Print(racks)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Datacenter",
@@ -262,7 +273,7 @@ func getRacks(app *cli.Cmd) {
 		})
 
 		for _, r := range racks {
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID.String(),
 				r.Datacenter,
 				r.Name,
@@ -285,7 +296,7 @@ func getRack(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(rack)
+			util.Print(rack)
 			return
 		}
 
@@ -315,7 +326,7 @@ Phase: %s
 
 		sort.Sort(rack.Slots)
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"RU",
 			"Occupied",
@@ -328,6 +339,18 @@ Phase: %s
 			"Phase",
 		})
 
+		occupants := make([]string, 0)
+		for _, slot := range rack.Slots {
+			if slot.Occupant.ID != "" {
+				occupants = append(occupants, slot.Occupant.ID)
+			}
+		}
+
+		states, err := util.API.DeviceValidationStatesBatch(occupants)
+		if err != nil {
+			util.Bail(err)
+		}
+
 		for _, slot := range rack.Slots {
 			occupied := "X"
 			validated := "?"
@@ -340,11 +363,7 @@ Phase: %s
 				occupantID = slot.Occupant.ID
 				occupantHealth = slot.Occupant.Health
 
-				vstates, err := util.API.DeviceValidationStates(slot.Occupant.ID)
-				if err != nil {
-					util.Bail(err)
-				}
-
+				vstates := states[slot.Occupant.ID]
 				if len(vstates) > 0 {
 					validated = "+"
 					for _, vstate := range vstates {
@@ -355,7 +374,7 @@ Phase: %s
 				}
 			}
 
-			table.Append([]string{
+			table.AppendItem(slot, []string{
 				strconv.Itoa(slot.RackUnitStart),
 				occupied,
 				validated,
@@ -397,7 +416,7 @@ func getRelays(app *cli.Cmd) {
 		}
 
 		if util.JSON && *fullOutput {
-			util.JSONOut(relays)
+			util.Print(relays)
 			return
 		}
 
@@ -428,11 +447,11 @@ func getRelays(app *cli.Cmd) {
 		}
 
 		if util.JSON {
-			util.JSONOut(results)
+			util.Print(results)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{
 			"ID",
 			"Alias",
@@ -450,7 +469,7 @@ func getRelays(app *cli.Cmd) {
 				updated = util.TimeStr(r.Updated)
 			}
 
-			table.Append([]string{
+			table.AppendItem(r, []string{
 				r.ID,
 				r.Alias,
 				util.TimeStr(r.Created),
@@ -475,15 +494,15 @@ func getSubs(app *cli.Cmd) {
 		sort.Sort(workspaces)
 
 		if util.JSON {
-			util.JSONOut(workspaces)
+			util.Print(workspaces)
 			return
 		}
 
-		table := util.GetMarkdownTable()
+		table := util.NewTable()
 		table.SetHeader([]string{"Role", "Id", "Name", "Description"})
 
 		for _, w := range workspaces {
-			table.Append([]string{w.Role, w.ID.String(), w.Name, w.Description})
+			table.AppendItem(w, []string{w.Role, w.ID.String(), w.Name, w.Description})
 		}
 		table.Render()
 	}
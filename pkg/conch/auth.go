@@ -7,6 +7,7 @@
 package conch
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
@@ -18,6 +19,11 @@ import (
 )
 
 func (c *Conch) RevokeUserTokensAndLogins(user string) error {
+	return c.RevokeUserTokensAndLoginsContext(context.Background(), user)
+}
+
+// RevokeUserTokensAndLoginsContext is the context.Context aware version of RevokeUserTokensAndLogins
+func (c *Conch) RevokeUserTokensAndLoginsContext(ctx context.Context, user string) error {
 	var uPart string
 	_, err := uuid.FromString(user)
 	if err == nil {
@@ -26,10 +32,15 @@ func (c *Conch) RevokeUserTokensAndLogins(user string) error {
 		uPart = "email=" + url.PathEscape(user)
 	}
 
-	return c.post("/user/"+uPart+"/revoke", nil, nil)
+	return c.post(ctx, "/user/"+uPart+"/revoke", nil, nil)
 }
 
 func (c *Conch) RevokeUserLogins(user string) error {
+	return c.RevokeUserLoginsContext(context.Background(), user)
+}
+
+// RevokeUserLoginsContext is the context.Context aware version of RevokeUserLogins
+func (c *Conch) RevokeUserLoginsContext(ctx context.Context, user string) error {
 	var uPart string
 	_, err := uuid.FromString(user)
 	if err == nil {
@@ -38,10 +49,15 @@ func (c *Conch) RevokeUserLogins(user string) error {
 		uPart = "email=" + url.PathEscape(user)
 	}
 
-	return c.post("/user/"+uPart+"/revoke?auth_only=1", nil, nil)
+	return c.post(ctx, "/user/"+uPart+"/revoke?auth_only=1", nil, nil)
 }
 
 func (c *Conch) RevokeUserTokens(user string) error {
+	return c.RevokeUserTokensContext(context.Background(), user)
+}
+
+// RevokeUserTokensContext is the context.Context aware version of RevokeUserTokens
+func (c *Conch) RevokeUserTokensContext(ctx context.Context, user string) error {
 	var uPart string
 	_, err := uuid.FromString(user)
 	if err == nil {
@@ -50,31 +66,62 @@ func (c *Conch) RevokeUserTokens(user string) error {
 		uPart = "email=" + url.PathEscape(user)
 	}
 
-	return c.post("/user/"+uPart+"/revoke?api_only=1", nil, nil)
+	return c.post(ctx, "/user/"+uPart+"/revoke?api_only=1", nil, nil)
 }
 
 func (c *Conch) GetUserToken(user string, name string) (u UserToken, err error) {
+	return c.GetUserTokenContext(context.Background(), user, name)
+}
+
+// GetUserTokenContext is the context.Context aware version of GetUserToken
+func (c *Conch) GetUserTokenContext(
+	ctx context.Context,
+	user string,
+	name string,
+) (u UserToken, err error) {
 	escapedName := url.PathEscape(name)
-	return u, c.get("/user/email="+user+"/token/"+escapedName, &u)
+	return u, c.get(ctx, "/user/email="+user+"/token/"+escapedName, &u)
 }
 
 func (c *Conch) GetUserTokens(user string) (UserTokens, error) {
+	return c.GetUserTokensContext(context.Background(), user)
+}
+
+// GetUserTokensContext is the context.Context aware version of GetUserTokens
+func (c *Conch) GetUserTokensContext(
+	ctx context.Context,
+	user string,
+) (UserTokens, error) {
 	u := make(UserTokens, 0)
 	escaped := url.PathEscape(user)
-	return u, c.get("/user/email="+escaped+"/token", &u)
+	return u, c.get(ctx, "/user/email="+escaped+"/token", &u)
 }
 
 func (c *Conch) DeleteUserToken(user string, name string) error {
+	return c.DeleteUserTokenContext(context.Background(), user, name)
+}
+
+// DeleteUserTokenContext is the context.Context aware version of DeleteUserToken
+func (c *Conch) DeleteUserTokenContext(
+	ctx context.Context,
+	user string,
+	name string,
+) error {
 	escapedName := url.PathEscape(name)
-	return c.httpDelete("/user/email=" + user + "/token/" + escapedName)
+	return c.httpDelete(ctx, "/user/email="+user+"/token/"+escapedName)
 }
 
 func (c *Conch) VerifyToken() (bool, error) {
+	return c.VerifyTokenContext(context.Background())
+}
+
+// VerifyTokenContext is the context.Context aware version of VerifyToken
+func (c *Conch) VerifyTokenContext(ctx context.Context) (bool, error) {
 	if c.Token == "" {
 		return false, ErrBadInput
 	}
 
-	_, err := c.GetUserSettings()
+	_, err := c.GetUserSettingsContext(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -90,14 +137,29 @@ func (c *Conch) VerifyToken() (bool, error) {
 //
 // If the second parameter is true, a JWT refresh is forced, regardless of any
 // other parameters.
-//
 func (c *Conch) VerifyJwtLogin(refreshTime int, forceJWT bool) error {
+	return c.VerifyJwtLoginContext(context.Background(), refreshTime, forceJWT)
+}
+
+// VerifyJwtLoginContext is the context.Context aware version of VerifyJwtLogin
+func (c *Conch) VerifyJwtLoginContext(
+	ctx context.Context,
+	refreshTime int,
+	forceJWT bool,
+) error {
+	c.setup()
 	u, _ := url.Parse(c.BaseURL)
 
+	// Only one refresh at a time. Anyone else waiting here will find the
+	// fresh token once it's their turn and skip the refresh.
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
 	if !forceJWT {
-		if (refreshTime > 0) && !c.JWT.Expires.IsZero() {
+		jwt := c.CurrentJWT()
+		if (refreshTime > 0) && !jwt.Expires.IsZero() {
 			now := time.Now()
-			if c.JWT.Expires.Sub(now).Seconds() > float64(refreshTime) {
+			if jwt.Expires.Sub(now).Seconds() > float64(refreshTime) {
 				return nil
 			}
 		}
@@ -107,7 +169,7 @@ func (c *Conch) VerifyJwtLogin(refreshTime int, forceJWT bool) error {
 		Token string `json:"jwt_token,omitempty"`
 	}{}
 
-	if err := c.post("/refresh_token", nil, &jwtAuth); err != nil {
+	if err := c.post(ctx, "/refresh_token", nil, &jwtAuth); err != nil {
 		return err
 	}
 
@@ -130,7 +192,7 @@ func (c *Conch) VerifyJwtLogin(refreshTime int, forceJWT bool) error {
 		return err
 	}
 
-	c.JWT = jwt
+	c.setJWT(jwt)
 
 	return nil
 }
@@ -139,6 +201,12 @@ func (c *Conch) VerifyJwtLogin(refreshTime int, forceJWT bool) error {
 // password to log into the Conch API and populate the JWT entry in the
 // Conch struct
 func (c *Conch) Login(user string, password string) error {
+	return c.LoginContext(context.Background(), user, password)
+}
+
+// LoginContext is the context.Context aware version of Login
+func (c *Conch) LoginContext(ctx context.Context, user string, password string) error {
+	c.setup()
 	u, _ := url.Parse(c.BaseURL)
 
 	payload := struct {
@@ -153,7 +221,7 @@ func (c *Conch) Login(user string, password string) error {
 		Token string `json:"jwt_token,omitempty"`
 	}{}
 
-	res, err := c.postNeedsResponse("/login", payload, &jwtAuth)
+	res, err := c.postNeedsResponse(ctx, "/login", payload, &jwtAuth)
 	if err != nil {
 		return err
 	}
@@ -173,7 +241,7 @@ func (c *Conch) Login(user string, password string) error {
 		return err
 	}
 
-	c.JWT = jwt
+	c.setJWT(jwt)
 
 	location, err := res.Location()
 
@@ -210,10 +278,6 @@ func (c *Conch) ParseJWT(token string, signature string) (ConchJWT, error) {
 	jwt.Token = token
 	jwt.Signature = signature
 
-	if c.Trace {
-		c.ddp(jwt)
-	}
-
 	bits := strings.Split(token, ".")
 	if len(bits) != 2 {
 		return jwt, ErrMalformedJWT
@@ -229,13 +293,12 @@ func (c *Conch) ParseJWT(token string, signature string) (ConchJWT, error) {
 		return jwt, err
 	}
 
-	if c.Trace {
-		c.ddp(jwt)
-	}
-
 	if val, ok := jwt.Claims["exp"]; ok {
 		jwt.Expires = time.Unix(int64(val.(float64)), 0)
 	}
 
+	// The token and signature are credentials and never make it to the log
+	c.traceLog("parsed JWT", "expires", jwt.Expires, "claims", jwt.Claims)
+
 	return jwt, nil
 }
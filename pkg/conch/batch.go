@@ -0,0 +1,167 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the number of requests the batch calls keep in
+// flight at once, unless the Conch struct's BatchConcurrency says otherwise
+const DefaultBatchConcurrency = 8
+
+// GetDevices fetches many devices at once. The result is keyed by device ID.
+// If any of the devices could not be fetched, the error is a BatchError and
+// the result holds the rest.
+func (c *Conch) GetDevices(ids []string) (map[string]Device, error) {
+	return c.GetDevicesContext(context.Background(), ids)
+}
+
+// GetDevicesContext is the context.Context aware version of GetDevices
+func (c *Conch) GetDevicesContext(
+	ctx context.Context,
+	ids []string,
+) (map[string]Device, error) {
+	var mu sync.Mutex
+	devices := make(map[string]Device)
+
+	err := c.batch(ctx, ids, func(ctx context.Context, id string) error {
+		d, err := c.GetDeviceContext(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		devices[id] = d
+		return nil
+	})
+
+	return devices, err
+}
+
+// DeviceValidationStatesBatch fetches the validation states of many devices at
+// once. The result is keyed by device ID. If any of the devices could not be
+// fetched, the error is a BatchError and the result holds the rest.
+func (c *Conch) DeviceValidationStatesBatch(
+	ids []string,
+) (map[string][]ValidationState, error) {
+	return c.DeviceValidationStatesBatchContext(context.Background(), ids)
+}
+
+// DeviceValidationStatesBatchContext is the context.Context aware version of DeviceValidationStatesBatch
+func (c *Conch) DeviceValidationStatesBatchContext(
+	ctx context.Context,
+	ids []string,
+) (map[string][]ValidationState, error) {
+	var mu sync.Mutex
+	states := make(map[string][]ValidationState)
+
+	err := c.batch(ctx, ids, func(ctx context.Context, id string) error {
+		s, err := c.DeviceValidationStatesContext(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		states[id] = s
+		return nil
+	})
+
+	return states, err
+}
+
+// GetDeviceLocations fetches the locations of many devices at once. The
+// result is keyed by device ID. If any of the devices could not be fetched,
+// the error is a BatchError and the result holds the rest.
+func (c *Conch) GetDeviceLocations(ids []string) (map[string]DeviceLocation, error) {
+	return c.GetDeviceLocationsContext(context.Background(), ids)
+}
+
+// GetDeviceLocationsContext is the context.Context aware version of GetDeviceLocations
+func (c *Conch) GetDeviceLocationsContext(
+	ctx context.Context,
+	ids []string,
+) (map[string]DeviceLocation, error) {
+	var mu sync.Mutex
+	locs := make(map[string]DeviceLocation)
+
+	err := c.batch(ctx, ids, func(ctx context.Context, id string) error {
+		loc, err := c.GetDeviceLocationContext(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		locs[id] = loc
+		return nil
+	})
+
+	return locs, err
+}
+
+// batch calls fetch for every unique ID, with at most BatchConcurrency calls
+// running at once. Failures are collected into a BatchError. If the context
+// ends early, the remaining IDs are not attempted and the context's error is
+// returned instead.
+func (c *Conch) batch(
+	ctx context.Context,
+	ids []string,
+	fetch func(context.Context, string) error,
+) error {
+	workers := c.BatchConcurrency
+	if workers < 1 {
+		workers = DefaultBatchConcurrency
+	}
+
+	queue := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make(BatchError)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				if err := fetch(ctx, id); err != nil {
+					mu.Lock()
+					errs[id] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	seen := make(map[string]bool)
+feed:
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		select {
+		case queue <- id:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
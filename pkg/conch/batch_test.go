@@ -0,0 +1,138 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestBatch(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	newAPI := func(opts ...conch.Option) *conch.Conch {
+		return conch.New(append([]conch.Option{
+			conch.WithBaseURL("http://localhost"),
+			conch.WithHTTPClient(&http.Client{}),
+			conch.WithRetryPolicy(conch.NoRetries),
+		}, opts...)...)
+	}
+
+	t.Run("GetDevices", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI()
+
+		gock.New(api.BaseURL).Get("/device/one").Reply(200).
+			JSON(map[string]string{"id": "one", "health": "pass"})
+		gock.New(api.BaseURL).Get("/device/two").Reply(200).
+			JSON(map[string]string{"id": "two", "health": "fail"})
+		gock.New(api.BaseURL).Get("/device/three").Reply(404).
+			JSON(map[string]string{"error": "Not Found"})
+
+		devices, err := api.GetDevices([]string{"one", "two", "three", "one"})
+
+		var batchErr conch.BatchError
+		st.Expect(t, errors.As(err, &batchErr), true)
+		st.Expect(t, len(batchErr), 1)
+		st.Expect(t, errors.Is(batchErr["three"], conch.ErrDataNotFound), true)
+
+		st.Expect(t, len(devices), 2)
+		st.Expect(t, devices["one"].Health, "pass")
+		st.Expect(t, devices["two"].Health, "fail")
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("DeviceValidationStatesBatch", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI()
+
+		for _, id := range []string{"one", "two"} {
+			gock.New(api.BaseURL).Get("/device/" + id + "/validation_state").
+				Reply(200).
+				JSON([]map[string]string{{"device_id": id, "status": "pass"}})
+		}
+
+		states, err := api.DeviceValidationStatesBatch([]string{"one", "two"})
+		st.Expect(t, err, nil)
+		st.Expect(t, len(states), 2)
+		st.Expect(t, states["two"][0].Status, "pass")
+	})
+
+	t.Run("GetDeviceLocations", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI()
+
+		gock.New(api.BaseURL).Get("/device/one/location").Reply(200).
+			JSON(map[string]interface{}{"rack_unit_start": 3})
+
+		locs, err := api.GetDeviceLocations([]string{"one"})
+		st.Expect(t, err, nil)
+		st.Expect(t, locs["one"].RackUnitStart, 3)
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		defer gock.Flush()
+
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		api := newAPI(
+			conch.WithBatchConcurrency(3),
+			conch.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+				return conch.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					mu.Lock()
+					inFlight++
+					if inFlight > maxInFlight {
+						maxInFlight = inFlight
+					}
+					mu.Unlock()
+
+					time.Sleep(10 * time.Millisecond)
+					res, err := next.RoundTrip(req)
+
+					mu.Lock()
+					inFlight--
+					mu.Unlock()
+					return res, err
+				})
+			}),
+		)
+
+		gock.New(api.BaseURL).Get("/device/").Persist().Reply(200).
+			JSON(map[string]string{"health": "pass"})
+
+		ids := make([]string, 0)
+		for i := 0; i < 12; i++ {
+			ids = append(ids, strconv.Itoa(i))
+		}
+
+		devices, err := api.GetDevices(ids)
+		st.Expect(t, err, nil)
+		st.Expect(t, len(devices), 12)
+		st.Expect(t, maxInFlight, 3)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		api := newAPI()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		devices, err := api.GetDevicesContext(ctx, []string{"one", "two"})
+		st.Expect(t, err, context.Canceled)
+		st.Expect(t, len(devices), 0)
+	})
+}
@@ -0,0 +1,311 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStatusHeader is set on every response served, in whole or in part,
+// from the cache. The value is "hit" for a fresh entry and "revalidated" if
+// the server confirmed a stale entry via ETag.
+const CacheStatusHeader = "X-Conch-Cache"
+
+// Cache stores GET responses for the client. Keys are opaque strings.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+	Delete(key string)
+}
+
+// CacheEntry is a response as kept in a Cache
+type CacheEntry struct {
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+	ETag    string      `json:"etag,omitempty"`
+	Expires time.Time   `json:"expires"`
+}
+
+// DefaultCacheTTLs lists how long responses are kept for each route, by
+// RouteTemplate. This covers reference data that rarely changes but is
+// looked up on nearly every command. Responses from any other route are only
+// kept if the server sends an ETag, and always revalidated before use.
+//
+// Workspaces, and anything in them, change in the course of a build, and
+// other clients' changes would go unseen until the entries expire, so they
+// are left out. So is the API version, which changes whenever the API is
+// upgraded.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"/hardware_product":               15 * time.Minute,
+	"/hardware_product/:id":           15 * time.Minute,
+	"/hardware_vendor":                15 * time.Minute,
+	"/hardware_vendor/:id":            15 * time.Minute,
+	"/validation":                     15 * time.Minute,
+	"/validation/:id":                 15 * time.Minute,
+	"/validation_plan":                15 * time.Minute,
+	"/validation_plan/:id":            15 * time.Minute,
+	"/validation_plan/:id/validation": 15 * time.Minute,
+	"/rack_role":                      15 * time.Minute,
+	"/rack_role/:id":                  15 * time.Minute,
+}
+
+// cachedHeaders are the only response headers kept in a Cache. Anything to
+// do with authentication stays out of it.
+var cachedHeaders = []string{"Content-Type", "Date", "ETag"}
+
+func (c *Conch) cacheTTL(route string) time.Duration {
+	ttls := c.CacheTTLs
+	if ttls == nil {
+		ttls = DefaultCacheTTLs
+	}
+	return ttls[route]
+}
+
+// cacheMiddleware answers GETs from the Cache when it can, and asks the
+// server to confirm stale entries using If-None-Match. Any other successful
+// request drops every cached entry of the resource it changed: all of
+// /hardware_product/..., say, for a change to a hardware product.
+func (c *Conch) cacheMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodGet {
+			res, err := next.RoundTrip(req)
+			if (err == nil) && (res.StatusCode < 400) {
+				c.invalidate(req.URL)
+			}
+			return res, err
+		}
+
+		key := c.cacheKey(req.URL)
+		route := RouteTemplate(req.URL.Path)
+		entry, ok := c.Cache.Get(key)
+
+		if ok && time.Now().Before(entry.Expires) {
+			c.debugLog("cache hit", "url", req.URL.String())
+			return entry.response(req, "hit"), nil
+		}
+
+		if ok && (entry.ETag != "") {
+			r := CloneRequest(req)
+			r.Header.Set("If-None-Match", entry.ETag)
+			req = r
+		}
+
+		res, err := next.RoundTrip(req)
+		if err != nil {
+			return res, err
+		}
+
+		if ok && (res.StatusCode == http.StatusNotModified) {
+			res.Body.Close()
+			c.debugLog("cache revalidated", "url", req.URL.String())
+
+			entry.Expires = time.Now().Add(c.cacheTTL(route))
+			c.Cache.Set(key, entry)
+			return entry.response(req, "revalidated"), nil
+		}
+
+		ttl := c.cacheTTL(route)
+		etag := res.Header.Get("ETag")
+		if (res.StatusCode != http.StatusOK) || ((ttl <= 0) && (etag == "")) {
+			return res, nil
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return res, err
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		header := make(http.Header)
+		for _, name := range cachedHeaders {
+			name = http.CanonicalHeaderKey(name)
+			if v, ok := res.Header[name]; ok {
+				header[name] = append([]string{}, v...)
+			}
+		}
+
+		c.Cache.Set(key, CacheEntry{
+			Header:  header,
+			Body:    body,
+			ETag:    etag,
+			Expires: time.Now().Add(ttl),
+		})
+
+		return res, nil
+	})
+}
+
+func (e CacheEntry) response(req *http.Request, status string) *http.Response {
+	header := make(http.Header, len(e.Header)+1)
+	for k, v := range e.Header {
+		header[k] = append([]string{}, v...)
+	}
+	header.Set(CacheStatusHeader, status)
+	header.Set("Content-Length", strconv.Itoa(len(e.Body)))
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func hashKey(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// resource is the collection a URL belongs to, like
+// "https://conch.example.com/workspace" for any URL under /workspace
+func resource(u *url.URL) string {
+	segment := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)[0]
+	return u.Scheme + "://" + u.Host + "/" + segment
+}
+
+// generationKey keys the entry holding the generation of a resource. A
+// Cache has no way to list or drop entries by prefix, so instead, every
+// entry of a resource is keyed with its generation, and a change to the
+// resource starts a new one. The old entries are never found again.
+func generationKey(u *url.URL) string {
+	return hashKey("generation " + resource(u))
+}
+
+// cacheKey keys the entry for a URL, in the current generation of its
+// resource
+func (c *Conch) cacheKey(u *url.URL) string {
+	generation, _ := c.Cache.Get(generationKey(u))
+	return hashKey(string(generation.Body) + " " + u.String())
+}
+
+// invalidate drops the entries of the resource a URL belongs to
+func (c *Conch) invalidate(u *url.URL) {
+	// The entries of this generation that are most likely there are dropped
+	// outright, so they don't pile up on disk
+	c.Cache.Delete(c.cacheKey(u))
+	parent := *u
+	parent.RawQuery = ""
+	parent.Path = path.Dir(parent.Path)
+	c.Cache.Delete(c.cacheKey(&parent))
+
+	c.Cache.Set(generationKey(u), CacheEntry{
+		Body: []byte(strconv.FormatInt(time.Now().UnixNano(), 36)),
+	})
+}
+
+// MemoryCache is a Cache that lives as long as the process does
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+// NewMemoryCache returns an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]CacheEntry)}
+}
+
+// Get returns the entry for the key, if there is one
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	return e, ok
+}
+
+// Set stores the entry under the key
+func (m *MemoryCache) Set(key string, entry CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = make(map[string]CacheEntry)
+	}
+	m.entries[key] = entry
+}
+
+// Delete removes the entry for the key, if there is one
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+// DiskCache is a Cache that keeps each entry in its own file, in Dir. The
+// directory is created as needed. Since entries can contain private data,
+// the directory and files are only readable by the current user.
+//
+// Failures to read or write are treated as cache misses.
+type DiskCache struct {
+	Dir string
+}
+
+// Get returns the entry for the key, if there is one
+func (d DiskCache) Get(key string) (CacheEntry, bool) {
+	var e CacheEntry
+
+	j, err := ioutil.ReadFile(filepath.Join(d.Dir, key))
+	if err != nil {
+		return e, false
+	}
+
+	if err := json.Unmarshal(j, &e); err != nil {
+		return e, false
+	}
+	return e, true
+}
+
+// Set stores the entry under the key
+func (d DiskCache) Set(key string, entry CacheEntry) {
+	j, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(d.Dir, 0700); err != nil {
+		return
+	}
+
+	tmp, err := ioutil.TempFile(d.Dir, "."+key)
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(j)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(d.Dir, key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete removes the entry for the key, if there is one
+func (d DiskCache) Delete(key string) {
+	os.Remove(filepath.Join(d.Dir, key))
+}
@@ -0,0 +1,227 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestCache(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	newAPI := func(cache conch.Cache) *conch.Conch {
+		return conch.New(
+			conch.WithBaseURL("http://localhost"),
+			conch.WithHTTPClient(&http.Client{}),
+			conch.WithRetryPolicy(conch.NoRetries),
+			conch.WithCache(cache),
+		)
+	}
+
+	roles := []map[string]interface{}{{"name": "test", "rack_size": 42}}
+
+	t.Run("TTL", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI(conch.NewMemoryCache())
+
+		gock.New(api.BaseURL).Get("/rack_role").Times(1).Reply(200).JSON(roles)
+
+		for i := 0; i < 3; i++ {
+			ret, err := api.GetRackRoles()
+			st.Expect(t, err, nil)
+			st.Expect(t, len(ret), 1)
+			st.Expect(t, ret[0].Name, "test")
+		}
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("WritesInvalidate", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI(conch.NewMemoryCache())
+
+		gock.New(api.BaseURL).Get("/rack_role").Times(2).Reply(200).JSON(roles)
+		gock.New(api.BaseURL).Post("/rack_role").Reply(303)
+
+		_, err := api.GetRackRoles()
+		st.Expect(t, err, nil)
+
+		_, err = api.RawPost("/rack_role", nil)
+		st.Expect(t, err, nil)
+
+		_, err = api.GetRackRoles()
+		st.Expect(t, err, nil)
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("WritesInvalidateTheWholeResource", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI(conch.NewMemoryCache())
+		id := uuid.NewV4()
+
+		gock.New(api.BaseURL).Get("/rack_role$").Times(2).Reply(200).JSON(roles)
+		gock.New(api.BaseURL).Get("/rack_role/" + id.String()).Times(2).Reply(200).
+			JSON(map[string]interface{}{"id": id.String(), "name": "test"})
+		gock.New(api.BaseURL).Post("/rack_role/" + id.String() + "/wat").Reply(204)
+
+		for i := 0; i < 2; i++ {
+			_, err := api.GetRackRoles()
+			st.Expect(t, err, nil)
+			_, err = api.GetRackRole(id)
+			st.Expect(t, err, nil)
+		}
+
+		// Neither the role nor the list of them is the path or the parent
+		// of what's written to
+		_, err := api.RawPost("/rack_role/"+id.String()+"/wat", nil)
+		st.Expect(t, err, nil)
+
+		_, err = api.GetRackRoles()
+		st.Expect(t, err, nil)
+		_, err = api.GetRackRole(id)
+		st.Expect(t, err, nil)
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("WorkspacesAreNotKept", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI(conch.NewMemoryCache())
+
+		gock.New(api.BaseURL).Get("/workspace").Times(2).Reply(200).
+			JSON([]map[string]string{{"name": "GLOBAL"}})
+
+		for i := 0; i < 2; i++ {
+			_, err := api.GetWorkspaces()
+			st.Expect(t, err, nil)
+		}
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("ETag", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI(conch.NewMemoryCache())
+
+		gock.New(api.BaseURL).Get("/device/test").Times(1).
+			Reply(200).
+			SetHeader("ETag", `"v1"`).
+			JSON(map[string]string{"id": "test", "health": "pass"})
+
+		gock.New(api.BaseURL).Get("/device/test").
+			MatchHeader("If-None-Match", `"v1"`).
+			Times(1).
+			Reply(304)
+
+		for i := 0; i < 2; i++ {
+			d, err := api.GetDevice("test")
+			st.Expect(t, err, nil)
+			st.Expect(t, d.Health, "pass")
+		}
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("NotCachedWithoutTTLOrETag", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI(conch.NewMemoryCache())
+
+		gock.New(api.BaseURL).Get("/device/test").Times(2).Reply(200).
+			JSON(map[string]string{"id": "test"})
+
+		for i := 0; i < 2; i++ {
+			_, err := api.GetDevice("test")
+			st.Expect(t, err, nil)
+		}
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("Disk", func(t *testing.T) {
+		defer gock.Flush()
+
+		dir, err := ioutil.TempDir("", "conch-cache")
+		st.Expect(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		gock.New("http://localhost").Get("/rack_role").Times(1).Reply(200).JSON(roles)
+
+		// A second client, as in a second CLI invocation, uses what the
+		// first one stored
+		for i := 0; i < 2; i++ {
+			api := newAPI(conch.DiskCache{Dir: dir})
+			ret, err := api.GetRackRoles()
+			st.Expect(t, err, nil)
+			st.Expect(t, ret[0].Name, "test")
+		}
+		st.Expect(t, gock.IsDone(), true)
+
+		files, err := ioutil.ReadDir(dir)
+		st.Expect(t, err, nil)
+		st.Expect(t, len(files), 1)
+		st.Expect(t, files[0].Mode().Perm(), os.FileMode(0600))
+	})
+
+	t.Run("DiskKeepsOnlySomeHeaders", func(t *testing.T) {
+		defer gock.Flush()
+
+		dir, err := ioutil.TempDir("", "conch-cache")
+		st.Expect(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		gock.New("http://localhost").Get("/rack_role").Times(1).Reply(200).
+			SetHeader("ETag", `"v1"`).
+			SetHeader("Set-Cookie", "conch=secret").
+			SetHeader("X-Auth-Token", "secret").
+			JSON(roles)
+
+		api := newAPI(conch.DiskCache{Dir: dir})
+		_, err = api.GetRackRoles()
+		st.Expect(t, err, nil)
+
+		files, err := ioutil.ReadDir(dir)
+		st.Expect(t, err, nil)
+		st.Expect(t, len(files), 1)
+
+		j, err := ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+		st.Expect(t, err, nil)
+		st.Expect(t, strings.Contains(string(j), "secret"), false)
+
+		entry, ok := conch.DiskCache{Dir: dir}.Get(files[0].Name())
+		st.Expect(t, ok, true)
+		st.Expect(t, entry.Header.Get("ETag"), `"v1"`)
+		st.Expect(t, entry.Header.Get("Content-Type"), "application/json")
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		defer gock.Flush()
+		api := conch.New(
+			conch.WithBaseURL("http://localhost"),
+			conch.WithHTTPClient(&http.Client{}),
+			conch.WithCache(conch.NewMemoryCache()),
+			conch.WithCacheTTLs(map[string]time.Duration{
+				"/rack_role": time.Millisecond,
+			}),
+		)
+
+		gock.New(api.BaseURL).Get("/rack_role").Times(2).Reply(200).JSON(roles)
+
+		_, err := api.GetRackRoles()
+		st.Expect(t, err, nil)
+		time.Sleep(5 * time.Millisecond)
+		_, err = api.GetRackRoles()
+		st.Expect(t, err, nil)
+		st.Expect(t, gock.IsDone(), true)
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// Capability names a feature of the API that not every supported server
+// version has
+type Capability string
+
+const (
+	CapRackAssignments Capability = "rack_assignments"
+	CapUserTokens      Capability = "user_tokens"
+	CapDevicePhases    Capability = "device_phases"
+	CapRackPhases      Capability = "rack_phases"
+)
+
+// Capabilities maps each Capability to the API versions that support it, in
+// the range syntax of github.com/blang/semver, like ">=2.27.0 <3.0.0".
+// Anything missing from the map is assumed to be supported everywhere.
+//
+// A minimum is the API release that added the routes the feature uses, as
+// listed in the API's changelog, https://github.com/joyent/conch/blob/master/Changes
+var Capabilities = map[Capability]string{
+	// GET, POST and DELETE /rack/:id/assignment
+	CapRackAssignments: ">=2.23.0",
+
+	// /user/me/token and /user/me/token/:name
+	CapUserTokens: ">=2.25.0",
+
+	// GET and POST /device/:id/phase, and ?phase= on device searches
+	CapDevicePhases: ">=2.27.0",
+
+	// GET and POST /rack/:id/phase
+	CapRackPhases: ">=2.27.0",
+}
+
+// ParseAPIVersion turns the version string of an API server, like
+// "v2.31.0-a1-5-gdeadbee", into a semver.Version. Git describe suffixes are
+// dropped since semver would read them as a prerelease of the version, which
+// sorts before it.
+func ParseAPIVersion(version string) (semver.Version, error) {
+	bits := strings.Split(strings.TrimLeft(version, "v"), "-")
+	return semver.Parse(bits[0])
+}
+
+// WithAPIVersion tells the client which API version the server runs,
+// instead of waiting for a call to GetVersion to find out. An unparsable
+// version is ignored.
+func WithAPIVersion(version string) Option {
+	return func(c *Conch) { c.setAPIVersion(version) }
+}
+
+// APIVersion returns the version of the API server, if known. The client
+// learns it from WithAPIVersion or from the latest successful GetVersion.
+func (c *Conch) APIVersion() (semver.Version, bool) {
+	c.versionMu.RLock()
+	defer c.versionMu.RUnlock()
+
+	if c.apiVersion == nil {
+		return semver.Version{}, false
+	}
+	return *c.apiVersion, true
+}
+
+func (c *Conch) setAPIVersion(version string) {
+	v, err := ParseAPIVersion(version)
+	if err != nil {
+		return
+	}
+
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	c.apiVersion = &v
+}
+
+// Supports reports whether the API server has the given Capability. While
+// the server's version is unknown, everything is assumed to be supported
+// and it is left to the server to refuse.
+func (c *Conch) Supports(capability Capability) bool {
+	return c.require(capability) == nil
+}
+
+// require returns a *CapabilityError if the API server is known not to
+// have the given Capability
+func (c *Conch) require(capability Capability) error {
+	supported, ok := Capabilities[capability]
+	if !ok {
+		return nil
+	}
+
+	v, ok := c.APIVersion()
+	if !ok {
+		return nil
+	}
+
+	r, err := semver.ParseRange(supported)
+	if err != nil || r(v) {
+		return nil
+	}
+
+	return &CapabilityError{
+		Capability: capability,
+		APIVersion: v.String(),
+		Supported:  supported,
+	}
+}
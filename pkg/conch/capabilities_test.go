@@ -0,0 +1,203 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestCapabilities(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	newAPI := func(opts ...conch.Option) *conch.Conch {
+		return conch.New(append([]conch.Option{
+			conch.WithBaseURL("http://localhost"),
+			conch.WithHTTPClient(http.DefaultClient),
+		}, opts...)...)
+	}
+
+	t.Run("ParseAPIVersion", func(t *testing.T) {
+		v, err := conch.ParseAPIVersion("v2.24.1-a3-5-gdeadbee")
+		st.Expect(t, err, nil)
+		st.Expect(t, v.String(), "2.24.1")
+
+		_, err = conch.ParseAPIVersion("wat")
+		st.Reject(t, err, nil)
+	})
+
+	t.Run("UnknownVersion", func(t *testing.T) {
+		api := newAPI()
+		_, ok := api.APIVersion()
+		st.Expect(t, ok, false)
+		st.Expect(t, api.Supports(conch.CapUserTokens), true)
+
+		gock.New(api.BaseURL).Get("/user/me/token").Reply(200).JSON([]conch.UserToken{})
+		_, err := api.GetMyTokens()
+		st.Expect(t, err, nil)
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("WithAPIVersion", func(t *testing.T) {
+		api := newAPI(conch.WithAPIVersion("v2.24.0"))
+		st.Expect(t, api.Supports(conch.CapRackAssignments), true)
+		st.Expect(t, api.Supports(conch.CapUserTokens), false)
+		st.Expect(t, api.Supports(conch.Capability("wat")), true)
+
+		_, err := api.GetMyTokens()
+		st.Expect(t, errors.Is(err, conch.ErrNotSupported), true)
+
+		var cerr *conch.CapabilityError
+		st.Expect(t, errors.As(err, &cerr), true)
+		st.Expect(t, cerr.Capability, conch.CapUserTokens)
+		st.Expect(t, cerr.APIVersion, "2.24.0")
+
+		id := uuid.NewV4()
+		gock.New(api.BaseURL).Get("/rack/" + id.String() + "/assignment").
+			Reply(200).JSON(conch.ResponseRackAssignments{})
+		_, err = api.GetRackAssignments(id)
+		st.Expect(t, err, nil)
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("GetVersion", func(t *testing.T) {
+		api := newAPI()
+
+		gock.New(api.BaseURL).Get("/version").Reply(200).JSON(map[string]string{"version": "v2.31.3"})
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+
+		v, ok := api.APIVersion()
+		st.Expect(t, ok, true)
+		st.Expect(t, v.String(), "2.31.3")
+		st.Expect(t, api.Supports(conch.CapUserTokens), true)
+	})
+
+	// MinimumAPIVersion is fully supported, so it must have every
+	// Capability, and OldestAPIVersion can't be any newer
+	t.Run("Versions", func(t *testing.T) {
+		minimum, err := semver.Parse(conch.MinimumAPIVersion)
+		st.Expect(t, err, nil)
+		oldest, err := semver.Parse(conch.OldestAPIVersion)
+		st.Expect(t, err, nil)
+		st.Expect(t, oldest.LTE(minimum), true)
+
+		for capability, supported := range conch.Capabilities {
+			r, err := semver.ParseRange(supported)
+			st.Expect(t, err, nil)
+			if !r(minimum) {
+				t.Errorf("%s (%s) is not supported by MinimumAPIVersion %s", capability, supported, minimum)
+			}
+		}
+	})
+
+	// A server older than MinimumAPIVersion, but not older than
+	// OldestAPIVersion, has some Capabilities and not others
+	t.Run("OlderServer", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI()
+
+		gock.New(api.BaseURL).Get("/version").Reply(200).JSON(map[string]string{"version": "v2.26.1"})
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+
+		id := uuid.NewV4()
+		gock.New(api.BaseURL).Get("/user/me/token").Reply(200).JSON([]conch.UserToken{})
+		gock.New(api.BaseURL).Get("/rack/" + id.String() + "/assignment").
+			Reply(200).JSON(conch.ResponseRackAssignments{})
+
+		_, err = api.GetMyTokens()
+		st.Expect(t, err, nil)
+		_, err = api.GetRackAssignments(id)
+		st.Expect(t, err, nil)
+
+		_, err = api.GetDevicePhase("AB123")
+		st.Expect(t, errors.Is(err, conch.ErrNotSupported), true)
+		err = api.SetRackPhase(id, "production", false)
+		st.Expect(t, errors.Is(err, conch.ErrNotSupported), true)
+
+		st.Expect(t, gock.IsDone(), true)
+		st.Expect(t, gock.HasUnmatchedRequest(), false)
+	})
+
+	// Every method that needs a Capability refuses, without a request, once
+	// the server is known to be too old for it
+	t.Run("OldServer", func(t *testing.T) {
+		defer gock.Flush()
+		api := newAPI()
+
+		gock.New(api.BaseURL).Get("/version").Reply(200).JSON(map[string]string{"version": "v2.22.9"})
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+
+		id := uuid.NewV4()
+		methods := map[string]func() error{
+			"GetMyTokens": func() error {
+				_, err := api.GetMyTokens()
+				return err
+			},
+			"GetMyToken": func() error {
+				_, err := api.GetMyToken("wat")
+				return err
+			},
+			"CreateMyToken": func() error {
+				_, err := api.CreateMyToken("wat")
+				return err
+			},
+			"DeleteMyToken": func() error {
+				return api.DeleteMyToken("wat")
+			},
+			"RevokeMyTokens": func() error {
+				return api.RevokeMyTokens()
+			},
+			"GetDevicePhase": func() error {
+				_, err := api.GetDevicePhase("AB123")
+				return err
+			},
+			"SetDevicePhase": func() error {
+				return api.SetDevicePhase("AB123", "production")
+			},
+			"FindDevicesByPhase": func() error {
+				_, err := api.FindDevices(conch.NewDeviceQuery().Phase("production"))
+				return err
+			},
+			"GetRackPhase": func() error {
+				_, err := api.GetRackPhase(id)
+				return err
+			},
+			"SetRackPhase": func() error {
+				return api.SetRackPhase(id, "production", false)
+			},
+			"GetRackAssignments": func() error {
+				_, err := api.GetRackAssignments(id)
+				return err
+			},
+			"AssignDevicesToRackSlots": func() error {
+				return api.AssignDevicesToRackSlots(id, conch.RequestRackAssignmentUpdates{})
+			},
+			"DeleteDevicesFromRackSlots": func() error {
+				return api.DeleteDevicesFromRackSlots(id, conch.RequestRackAssignmentDeletes{})
+			},
+		}
+
+		for name, method := range methods {
+			err := method()
+			if !errors.Is(err, conch.ErrNotSupported) {
+				t.Errorf("%s: want ErrNotSupported, have %v", name, err)
+			}
+		}
+		st.Expect(t, gock.HasUnmatchedRequest(), false)
+	})
+}
@@ -0,0 +1,256 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCassetteMiss is returned when a replayed request has no answer left in
+// the cassette
+var ErrCassetteMiss = errors.New("no recorded response for request")
+
+// Interaction is a request and the response to it, as kept in a cassette.
+// Credentials are redacted before an Interaction is written anywhere.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+	Duration time.Duration    `json:"duration"`
+}
+
+// RecordedRequest is the part of an Interaction that went to the server
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the part of an Interaction that came back
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder writes every request the client sends, and the response it gets,
+// to a cassette. A cassette is a series of Interactions as JSON, one per
+// line. Each line is written as soon as the response arrives, so a cassette
+// is usable even if the process dies partway through.
+type Recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder returns a Recorder that writes to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// Record redacts the Interaction and writes it to the cassette
+func (r *Recorder) Record(i Interaction) error {
+	i.Request.Header = redactHeaders(i.Request.Header)
+	i.Request.Body = string(redactBody([]byte(i.Request.Body)))
+	i.Response.Header = redactHeaders(i.Response.Header)
+	i.Response.Body = string(redactBody([]byte(i.Response.Body)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enc.Encode(i)
+}
+
+// recordMiddleware hands every request that makes it to the wire, along with
+// its response, to the Recorder. A request that fails outright has nothing
+// worth replaying and isn't recorded.
+func (c *Conch) recordMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		var reqBody []byte
+		if (req.Body != nil) && (req.GetBody != nil) {
+			if b, err := req.GetBody(); err == nil {
+				reqBody, _ = ioutil.ReadAll(b)
+				b.Close()
+			}
+		}
+
+		start := time.Now()
+		res, err := next.RoundTrip(req)
+		if err != nil {
+			return res, err
+		}
+
+		resBody, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+		if err != nil {
+			return res, err
+		}
+
+		err = c.Recorder.Record(Interaction{
+			Request: RecordedRequest{
+				Method: req.Method,
+				URL:    req.URL.String(),
+				Header: req.Header,
+				Body:   string(reqBody),
+			},
+			Response: RecordedResponse{
+				StatusCode: res.StatusCode,
+				Header:     res.Header,
+				Body:       string(resBody),
+			},
+			Duration: time.Since(start),
+		})
+		if err != nil {
+			c.warnLog("failed to record request", "url", req.URL.String(), "error", err)
+		}
+		return res, nil
+	})
+}
+
+/***/
+
+// Cassette answers requests from recorded Interactions instead of the
+// network. A request is answered by the first Interaction, not yet used,
+// with the same method, path, and query string. Each Interaction is used
+// once, so a cassette replays a conversation with the server in the order
+// it happened. Headers and bodies are not compared since the credentials in
+// them were redacted.
+type Cassette struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassette returns a Cassette that replays the given Interactions
+func NewCassette(interactions []Interaction) *Cassette {
+	return &Cassette{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}
+}
+
+// ReadCassette reads a cassette as written by a Recorder
+func ReadCassette(r io.Reader) (*Cassette, error) {
+	interactions := make([]Interaction, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var i Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("cassette line %d: %s", line, err)
+		}
+		interactions = append(interactions, i)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewCassette(interactions), nil
+}
+
+// LoadCassette reads a cassette from a file
+func LoadCassette(path string) (*Cassette, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCassette(f)
+}
+
+// Remaining returns the number of Interactions that haven't been replayed
+func (c *Cassette) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, used := range c.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// RoundTrip answers the request from the cassette. It never touches the
+// network.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(ioutil.Discard, req.Body)
+		req.Body.Close()
+	}
+
+	i, ok := c.next(req)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, req.Method, req.URL.RequestURI())
+	}
+
+	header := make(http.Header, len(i.Response.Header))
+	for k, v := range i.Response.Header {
+		header[k] = append([]string{}, v...)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+		StatusCode:    i.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(i.Response.Body)),
+		ContentLength: int64(len(i.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// next finds and uses up the Interaction that answers the request
+func (c *Cassette) next(req *http.Request) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	want := req.URL.RequestURI()
+	for idx, i := range c.interactions {
+		if c.used[idx] || (i.Request.Method != req.Method) {
+			continue
+		}
+		if !sameRequestURI(i.Request.URL, want) {
+			continue
+		}
+		c.used[idx] = true
+		return i, true
+	}
+	return Interaction{}, false
+}
+
+// sameRequestURI compares the path and query of a recorded URL to a request,
+// ignoring the host so that a cassette recorded against one instance can be
+// replayed with any base URL
+func sameRequestURI(recorded string, want string) bool {
+	u, err := url.Parse(recorded)
+	if err != nil {
+		return false
+	}
+	return u.RequestURI() == want
+}
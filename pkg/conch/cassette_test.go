@@ -0,0 +1,88 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestCassette(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	var tape bytes.Buffer
+
+	t.Run("Record", func(t *testing.T) {
+		defer gock.Flush()
+		api := conch.New(
+			conch.WithBaseURL("http://localhost"),
+			conch.WithHTTPClient(&http.Client{}),
+			conch.WithToken("sekrit-token"),
+			conch.WithRetryPolicy(conch.NoRetries),
+			conch.WithRecorder(conch.NewRecorder(&tape)),
+		)
+
+		gock.New(api.BaseURL).Post("/user/me/password").Reply(204)
+		gock.New(api.BaseURL).Get("/device/test").Reply(200).
+			JSON(map[string]string{"id": "test", "health": "pass"})
+		gock.New(api.BaseURL).Get("/device/test").Reply(200).
+			JSON(map[string]string{"id": "test", "health": "fail"})
+
+		_, err := api.RawPost("/user/me/password", strings.NewReader(`{"password":"hunter2"}`))
+		st.Expect(t, err, nil)
+
+		for i := 0; i < 2; i++ {
+			_, err = api.GetDevice("test")
+			st.Expect(t, err, nil)
+		}
+		st.Expect(t, gock.IsDone(), true)
+
+		st.Expect(t, strings.Count(tape.String(), "\n"), 3)
+		st.Expect(t, strings.Contains(tape.String(), "hunter2"), false)
+		st.Expect(t, strings.Contains(tape.String(), "sekrit-token"), false)
+		st.Expect(t, strings.Contains(tape.String(), conch.Redacted), true)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		cassette, err := conch.ReadCassette(bytes.NewReader(tape.Bytes()))
+		st.Expect(t, err, nil)
+		st.Expect(t, cassette.Remaining(), 3)
+
+		// The base URL doesn't need to match the recording and retries
+		// don't apply to a miss
+		api := conch.New(
+			conch.WithBaseURL("http://elsewhere"),
+			conch.WithToken("another-token"),
+			conch.WithCassette(cassette),
+		)
+
+		d, err := api.GetDevice("test")
+		st.Expect(t, err, nil)
+		st.Expect(t, d.Health, "pass")
+
+		d, err = api.GetDevice("test")
+		st.Expect(t, err, nil)
+		st.Expect(t, d.Health, "fail")
+		st.Expect(t, cassette.Remaining(), 1)
+
+		_, err = api.GetDevice("test")
+		st.Expect(t, errors.Is(err, conch.ErrCassetteMiss), true)
+	})
+
+	t.Run("BadCassette", func(t *testing.T) {
+		_, err := conch.ReadCassette(strings.NewReader("{}\nnope\n"))
+		st.Reject(t, err, nil)
+	})
+}
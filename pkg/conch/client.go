@@ -0,0 +1,224 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+)
+
+// Option configures a Conch built by New
+type Option func(*Conch)
+
+// New returns a fully initialized Conch, safe for concurrent use by multiple
+// goroutines. The configuration must not be changed afterwards. The JWT is
+// the only exception, as it is replaced by Login and VerifyJwtLogin under a
+// lock. Use CurrentJWT to read it.
+//
+// A Conch built as a struct literal works as well. It is initialized on
+// first use and becomes safe for concurrent use from then on.
+func New(opts ...Option) *Conch {
+	c := &Conch{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.setup()
+	return c
+}
+
+// WithBaseURL sets the URL of the API. Defaults to the production instance.
+func WithBaseURL(u string) Option {
+	return func(c *Conch) { c.BaseURL = u }
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(ua string) Option {
+	return func(c *Conch) { c.UA = ua }
+}
+
+// WithToken authenticates using an API token
+func WithToken(token string) Option {
+	return func(c *Conch) { c.Token = token }
+}
+
+// WithJWT authenticates using a JWT from a previous login
+func WithJWT(jwt ConchJWT) Option {
+	return func(c *Conch) { c.JWT = jwt }
+}
+
+// WithHTTPClient replaces the default http.Client. If the client has no
+// cookie jar, one is created since Login and VerifyJwtLogin depend on it.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Conch) { c.HTTPClient = client }
+}
+
+// WithTLSConfig sets the TLS configuration for the default transport. See
+// TLSOptions for building one.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Conch) { c.TLSConfig = cfg }
+}
+
+// WithTransportOptions tunes the default transport. See TransportOptions.
+func WithTransportOptions(o TransportOptions) Option {
+	return func(c *Conch) { c.TransportOptions = &o }
+}
+
+// WithDebug turns on debug logging
+func WithDebug(debug bool) Option {
+	return func(c *Conch) { c.Debug = debug }
+}
+
+// WithTrace turns on trace logging, which includes everything debug logging
+// does
+func WithTrace(trace bool) Option {
+	return func(c *Conch) { c.Trace = trace }
+}
+
+// WithLogger sends log output to the given Logger instead of stderr
+func WithLogger(l Logger) Option {
+	return func(c *Conch) { c.Logger = l }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Conch) { c.RetryPolicy = &p }
+}
+
+// WithStrictDecoding makes response decoding failures fatal. See
+// Conch.StrictDecoding.
+func WithStrictDecoding(strict bool) Option {
+	return func(c *Conch) { c.StrictDecoding = strict }
+}
+
+// WithWarnUnknownFields logs a warning for response fields the library does
+// not know about. See Conch.WarnUnknownFields.
+func WithWarnUnknownFields(warn bool) Option {
+	return func(c *Conch) { c.WarnUnknownFields = warn }
+}
+
+// WithMiddleware appends to the middleware chain
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Conch) { c.Middleware = append(c.Middleware, mw...) }
+}
+
+// WithMetrics reports every request to the given Metrics
+func WithMetrics(m Metrics) Option {
+	return func(c *Conch) { c.Metrics = m }
+}
+
+// WithBatchConcurrency caps the number of requests in flight for batch calls
+// like GetDevices
+func WithBatchConcurrency(n int) Option {
+	return func(c *Conch) { c.BatchConcurrency = n }
+}
+
+// WithCache keeps responses in the given Cache. See Conch.Cache.
+func WithCache(cache Cache) Option {
+	return func(c *Conch) { c.Cache = cache }
+}
+
+// WithCacheTTLs replaces DefaultCacheTTLs
+func WithCacheTTLs(ttls map[string]time.Duration) Option {
+	return func(c *Conch) { c.CacheTTLs = ttls }
+}
+
+// WithRecorder records every request and response. See Conch.Recorder.
+func WithRecorder(r *Recorder) Option {
+	return func(c *Conch) { c.Recorder = r }
+}
+
+// WithCassette replays responses from a cassette instead of talking to the
+// API. See Conch.Cassette.
+func WithCassette(cassette *Cassette) Option {
+	return func(c *Conch) { c.Cassette = cassette }
+}
+
+// setup fills in defaults for anything left unconfigured, and builds the
+// middleware chain. It only ever runs once, so that a Conch can be shared
+// between goroutines.
+func (c *Conch) setup() {
+	c.setupOnce.Do(func() {
+		if c.UA == "" {
+			c.UA = defaultUA
+		}
+
+		if c.BaseURL == "" {
+			c.BaseURL = defaultBaseURL
+		}
+
+		c.setupHTTPClient()
+
+		client := *c.HTTPClient
+		client.Transport = c.transport(client.Transport)
+		c.httpClient = &client
+	})
+}
+
+// setupHTTPClient makes sure there's an HTTPClient, with a cookie jar
+func (c *Conch) setupHTTPClient() {
+	if c.HTTPClient != nil {
+		if c.HTTPClient.Jar == nil {
+			if c.CookieJar == nil {
+				c.CookieJar, _ = cookiejar.New(nil)
+			}
+			client := *c.HTTPClient
+			client.Jar = c.CookieJar
+			c.HTTPClient = &client
+		}
+		return
+	}
+
+	if c.CookieJar == nil {
+		c.CookieJar, _ = cookiejar.New(nil)
+	}
+
+	var timeout time.Duration
+	if c.TransportOptions != nil {
+		timeout = c.TransportOptions.Timeout
+	}
+
+	if (c.TLSConfig != nil) && c.TLSConfig.InsecureSkipVerify {
+		c.warnLog(
+			"TLS certificate verification is DISABLED. Anyone between "+
+				"here and the API can read and alter this traffic, "+
+				"including credentials.",
+			"url", c.BaseURL,
+		)
+	}
+
+	c.HTTPClient = &http.Client{
+		Transport: c.TransportOptions.transport(c.TLSConfig),
+		Timeout:   timeout,
+		Jar:       c.CookieJar,
+
+		// The Authorization header is carried across redirects by
+		// authMiddleware
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > 30 {
+				return fmt.Errorf("%d > 30 consecutive requests(redirects)", len(via))
+			}
+			return nil
+		},
+	}
+}
+
+// CurrentJWT returns the JWT in use. Unlike reading the JWT field, it is
+// safe to call while other goroutines may be refreshing the token.
+func (c *Conch) CurrentJWT() ConchJWT {
+	c.jwtMu.RLock()
+	defer c.jwtMu.RUnlock()
+	return c.JWT
+}
+
+func (c *Conch) setJWT(jwt ConchJWT) {
+	c.jwtMu.Lock()
+	defer c.jwtMu.Unlock()
+	c.JWT = jwt
+}
@@ -0,0 +1,172 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// These tests are most useful under 'go test -race'
+
+func fakeJWT(expires time.Time) string {
+	seg := func(v interface{}) string {
+		j, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(j)
+	}
+	return seg(map[string]string{"alg": "HS256"}) + "." +
+		seg(map[string]interface{}{"exp": expires.Unix()})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		c := conch.New()
+		st.Expect(t, c.BaseURL, "https://conch.joyent.us")
+		st.Expect(t, c.UA, "go-conch")
+		st.Reject(t, c.HTTPClient, nil)
+		st.Reject(t, c.HTTPClient.Jar, nil)
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		c := conch.New(
+			conch.WithBaseURL("http://localhost"),
+			conch.WithUserAgent("test"),
+			conch.WithToken("token"),
+			conch.WithHTTPClient(http.DefaultClient),
+		)
+		st.Expect(t, c.BaseURL, "http://localhost")
+		st.Expect(t, c.UA, "test")
+		st.Expect(t, c.Token, "token")
+
+		// The caller's client is left alone when a jar is needed
+		st.Expect(t, http.DefaultClient.Jar, nil)
+		st.Reject(t, c.HTTPClient.Jar, nil)
+	})
+}
+
+func TestConcurrentUse(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	const workers = 20
+
+	t.Run("Requests", func(t *testing.T) {
+		c := conch.New(
+			conch.WithBaseURL("http://localhost"),
+			conch.WithToken("token"),
+			conch.WithHTTPClient(&http.Client{}),
+		)
+
+		gock.New(c.BaseURL).Get("/version").
+			MatchHeader("Authorization", "Bearer token").
+			Persist().
+			Reply(200).JSON(map[string]string{"version": "v2.20.0"})
+		defer gock.Flush()
+
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.GetVersion()
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			st.Expect(t, err, nil)
+		}
+	})
+
+	t.Run("StructLiteral", func(t *testing.T) {
+		c := &conch.Conch{
+			BaseURL:    "http://localhost",
+			HTTPClient: &http.Client{},
+		}
+
+		gock.New(c.BaseURL).Get("/version").Persist().
+			Reply(200).JSON(map[string]string{"version": "v2.20.0"})
+		defer gock.Flush()
+
+		var wg sync.WaitGroup
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.GetVersion()
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			st.Expect(t, err, nil)
+		}
+		st.Expect(t, c.UA, "go-conch")
+	})
+
+	t.Run("RefreshesOnce", func(t *testing.T) {
+		c := conch.New(
+			conch.WithBaseURL("http://localhost"),
+			conch.WithHTTPClient(&http.Client{}),
+			conch.WithJWT(conch.ConchJWT{
+				Token:     fakeJWT(time.Now().Add(time.Minute)),
+				Signature: "old",
+				Expires:   time.Now().Add(time.Minute),
+			}),
+		)
+
+		fresh := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+		gock.New(c.BaseURL).Post("/refresh_token").
+			Times(1).
+			Reply(200).
+			SetHeader("Set-Cookie", "jwt_sig=new; Path=/").
+			JSON(map[string]string{"jwt_token": fakeJWT(fresh)})
+
+		gock.New(c.BaseURL).Get("/version").Persist().
+			Reply(200).JSON(map[string]string{"version": "v2.20.0"})
+		defer gock.Flush()
+
+		var wg sync.WaitGroup
+		errs := make(chan error, workers*2)
+		for i := 0; i < workers; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				errs <- c.VerifyJwtLogin(3600, false)
+			}()
+			go func() {
+				defer wg.Done()
+				_, err := c.GetVersion()
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			st.Expect(t, err, nil)
+		}
+
+		jwt := c.CurrentJWT()
+		st.Expect(t, jwt.Signature, "new")
+		st.Expect(t, jwt.Expires.Equal(fresh), true)
+	})
+}
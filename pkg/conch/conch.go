@@ -7,16 +7,20 @@
 // Package conch provides access to the Conch API
 package conch
 
+import (
+	"context"
+)
+
 /*
 This is a bit of trickery used elsewhere to help make it clear that we are
 omitting fields from json output.
 
 Specifically, you'll see something like
 
-out := type writableFoo {
-	*Foo
-	ID omit `json:"id,omitempty"`
-}{ myFoo }
+	out := type writableFoo {
+		*Foo
+		ID omit `json:"id,omitempty"`
+	}{ myFoo }
 
 This gives us a special version of Foo that leaves out the ID field unless we
 explicitly set it in the new structure.
@@ -26,26 +30,40 @@ rather than repeating the full contents of the structure and just leaving those
 fields out. This at least lets us grow the structure without remembering to
 update it in multiple places and it lets us document explicitly via this 'omit'
 data type that we're leaving stuff out.
-
 */
 type omit bool
 
 const (
-	// MinimumAPIVersion sets the earliest API version that we support.
-	MinimumAPIVersion  = "2.30.0"
+	// MinimumAPIVersion sets the earliest API version that we fully support.
+	// It has every one of the Capabilities.
+	MinimumAPIVersion = "2.30.0"
+
+	// OldestAPIVersion sets the earliest API version that we work with at
+	// all. Against servers older than MinimumAPIVersion, methods needing a
+	// Capability the server lacks return ErrNotSupported. Below the oldest
+	// of the Capabilities, nothing says what a server lacks.
+	OldestAPIVersion = "2.23.0"
+
 	BreakingAPIVersion = "3.0.0"
 )
 
-// GetVersion returns the API's version string, via /version
+// GetVersion returns the API's version string, via /version. The client
+// remembers it to decide which Capabilities the server has.
 func (c *Conch) GetVersion() (string, error) {
+	return c.GetVersionContext(context.Background())
+}
+
+// GetVersionContext is the context.Context aware version of GetVersion
+func (c *Conch) GetVersionContext(ctx context.Context) (string, error) {
 	v := struct {
 		Version string `json:"version"`
 	}{}
 
-	err := c.get("/version", &v)
+	err := c.get(ctx, "/version", &v)
 	if err != nil {
 		return "", err
 	}
 
+	c.setAPIVersion(v.Version)
 	return v.Version, nil
 }
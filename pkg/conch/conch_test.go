@@ -1,6 +1,7 @@
 package conch_test
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"testing"
@@ -14,7 +15,14 @@ var ErrApi = struct {
 	ErrorMsg string `json:"error"`
 }{"totally broken"}
 
-var ErrApiUnpacked = errors.New(ErrApi.ErrorMsg)
+// apiErrorMessage digs the server's message out of an *conch.APIError
+func apiErrorMessage(err error) string {
+	var aerr *conch.APIError
+	if errors.As(err, &aerr) {
+		return aerr.Message
+	}
+	return ""
+}
 
 var API = &conch.Conch{
 	BaseURL:    "http://localhost",
@@ -42,7 +50,16 @@ func TestConch(t *testing.T) {
 		gock.New(API.BaseURL).Get("/version").Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetVersion()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
+		st.Expect(t, ret, "")
+	})
+
+	t.Run("GetVersionContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		ret, err := API.GetVersionContext(ctx)
+		st.Expect(t, err, context.Canceled)
 		st.Expect(t, ret, "")
 	})
 }
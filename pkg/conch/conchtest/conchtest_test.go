@@ -0,0 +1,553 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/conchtest"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+	"github.com/nbio/st"
+)
+
+func statusCode(err error) int {
+	var aerr *conch.APIError
+	if errors.As(err, &aerr) {
+		return aerr.StatusCode
+	}
+	return 0
+}
+
+// deviceIDs lists the IDs of devices, in order
+func deviceIDs(devices conch.Devices) []string {
+	ids := make([]string, 0, len(devices))
+	for _, d := range devices {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+// seedRack builds a datacenter, room, role, rack, and a product with two
+// slots in the rack
+func seedRack(srv *conchtest.Server) (conch.Rack, conch.HardwareProduct) {
+	dc := srv.AddDatacenter(conch.Datacenter{Vendor: "Acme", Region: "us-east-1", Location: "NYC"})
+	room := srv.AddRoom(conch.Room{DatacenterID: dc.ID, AZ: "us-east-1a", Alias: "room1"})
+	role := srv.AddRackRole(conch.RackRole{Name: "compute", RackSize: 42})
+	rack := srv.AddRack(conch.Rack{DatacenterRoomID: room.ID, RoleID: role.ID, Name: "A01"})
+
+	vendor := srv.AddHardwareVendor("Acme")
+	product := srv.AddHardwareProduct(conch.HardwareProduct{
+		Name:             "Acme 2U",
+		Alias:            "2u",
+		HardwareVendorID: vendor.ID,
+		Specification:    map[string]interface{}{"disk_size": "big"},
+		Profile:          conch.HardwareProfile{RackUnit: 2},
+	})
+
+	srv.AddLayoutSlot(conch.RackLayoutSlot{RackID: rack.ID, ProductID: product.ID, RUStart: 1})
+	srv.AddLayoutSlot(conch.RackLayoutSlot{RackID: rack.ID, ProductID: product.ID, RUStart: 3})
+	return rack, product
+}
+
+func TestAuth(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+
+	t.Run("Version", func(t *testing.T) {
+		v, err := conch.New(conch.WithBaseURL(srv.URL)).GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, v, conchtest.Version)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		_, err := conch.New(conch.WithBaseURL(srv.URL)).GetWorkspaces()
+		st.Expect(t, errors.Is(err, conch.ErrNotAuthorized), true)
+	})
+
+	t.Run("LoginAndRefresh", func(t *testing.T) {
+		api := conch.New(conch.WithBaseURL(srv.URL))
+
+		err := api.Login("nobody@conch.test", "nope")
+		st.Expect(t, statusCode(err), 401)
+
+		st.Expect(t, api.Login(conchtest.AdminEmail, conchtest.AdminPassword), nil)
+		first := api.CurrentJWT()
+		st.Reject(t, first.Token, "")
+		st.Expect(t, first.Expires.IsZero(), false)
+
+		profile, err := api.GetUserProfile()
+		st.Expect(t, err, nil)
+		st.Expect(t, profile.Email, conchtest.AdminEmail)
+
+		st.Expect(t, api.VerifyJwtLogin(0, true), nil)
+		st.Reject(t, api.CurrentJWT().Token, first.Token)
+
+		// The refreshed session works, the old one does not
+		_, err = api.GetUserProfile()
+		st.Expect(t, err, nil)
+
+		old := conch.New(conch.WithBaseURL(srv.URL), conch.WithJWT(first))
+		_, err = old.GetUserProfile()
+		st.Expect(t, statusCode(err), 401)
+	})
+
+	t.Run("Tokens", func(t *testing.T) {
+		admin := srv.Client()
+
+		tok, err := admin.CreateMyToken("ci")
+		st.Expect(t, err, nil)
+		st.Reject(t, tok.Token, "")
+
+		_, err = admin.CreateMyToken("ci")
+		st.Expect(t, statusCode(err), 409)
+
+		api := srv.Client(conch.WithToken(tok.Token))
+		ok, err := api.VerifyToken()
+		st.Expect(t, err, nil)
+		st.Expect(t, ok, true)
+
+		st.Expect(t, admin.DeleteMyToken("ci"), nil)
+		_, err = api.VerifyToken()
+		st.Expect(t, statusCode(err), 401)
+	})
+
+	t.Run("PasswordReset", func(t *testing.T) {
+		admin := srv.Client()
+		st.Expect(t, admin.CreateUser("new@conch.test", "first", "New", false), nil)
+
+		api := conch.New(conch.WithBaseURL(srv.URL))
+		st.Expect(t, api.Login("new@conch.test", "first"), nil)
+
+		st.Expect(t, admin.ResetUserPassword("new@conch.test", false), nil)
+		_, err := api.GetUserProfile()
+		st.Expect(t, statusCode(err), 401)
+		st.Expect(t, statusCode(api.Login("new@conch.test", "first")), 401)
+	})
+}
+
+func TestInventory(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+	api := srv.Client()
+
+	dc := conch.Datacenter{Vendor: "Acme", Region: "us-west-1", Location: "SFO"}
+	st.Expect(t, api.SaveDatacenter(&dc), nil)
+	st.Expect(t, dc.ID.IsZero(), false)
+
+	room := conch.Room{DatacenterID: dc.ID, AZ: "us-west-1a", Alias: "r1"}
+	st.Expect(t, api.SaveRoom(&room), nil)
+
+	role := conch.RackRole{Name: "storage", RackSize: 42}
+	st.Expect(t, api.SaveRackRole(&role), nil)
+
+	rack := conch.Rack{DatacenterRoomID: room.ID, RoleID: role.ID, Name: "B01"}
+	st.Expect(t, api.SaveRack(&rack), nil)
+	st.Expect(t, rack.Phase, "integration")
+
+	vendor := conch.HardwareVendor{Name: "Acme"}
+	st.Expect(t, api.SaveHardwareVendor(&vendor), nil)
+
+	product := conch.HardwareProduct{
+		Name:             "Acme 4U",
+		Alias:            "4u",
+		HardwareVendorID: vendor.ID,
+		Specification:    map[string]interface{}{"disks": float64(36)},
+		Profile:          conch.HardwareProfile{RackUnit: 4},
+	}
+	st.Expect(t, api.SaveHardwareProduct(&product), nil)
+
+	got, err := api.GetHardwareProduct(product.ID)
+	st.Expect(t, err, nil)
+	st.Expect(t, got.Specification, map[string]interface{}{"disks": float64(36)})
+
+	slot := conch.RackLayoutSlot{RackID: rack.ID, ProductID: product.ID, RUStart: 10}
+	st.Expect(t, api.SaveRackLayoutSlot(&slot), nil)
+
+	dupe := conch.RackLayoutSlot{RackID: rack.ID, ProductID: product.ID, RUStart: 10}
+	st.Expect(t, statusCode(api.SaveRackLayoutSlot(&dupe)), 409)
+
+	racks, err := api.GetRoomRacks(room)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(racks), 1)
+	st.Expect(t, racks[0].Name, "B01")
+
+	st.Expect(t, api.AssignDevicesToRackSlots(rack.ID, conch.RequestRackAssignmentUpdates{
+		{DeviceID: "SERIAL1", RackUnitStart: 10, DeviceAssetTag: "tag1"},
+	}), nil)
+
+	assignments, err := api.GetRackAssignments(rack.ID)
+	st.Expect(t, err, nil)
+	st.Expect(t, assignments, conch.ResponseRackAssignments{{
+		DeviceID:        "SERIAL1",
+		DeviceAssetTag:  "tag1",
+		HardwareProduct: "Acme 4U",
+		RackUnitStart:   10,
+		RackUnitSize:    4,
+	}})
+
+	loc, err := api.GetDeviceLocation("SERIAL1")
+	st.Expect(t, err, nil)
+	st.Expect(t, loc.Rack.ID, rack.ID)
+	st.Expect(t, loc.Room.AZ, "us-west-1a")
+	st.Expect(t, loc.Datacenter.Region, "us-west-1")
+	st.Expect(t, loc.TargetHardwareProduct.Vendor, "Acme")
+	st.Expect(t, loc.RackUnitStart, 10)
+
+	st.Expect(t, api.SetRackPhase(rack.ID, "production", true), nil)
+	phase, err := api.GetDevicePhase("SERIAL1")
+	st.Expect(t, err, nil)
+	st.Expect(t, phase, "production")
+
+	st.Expect(t, statusCode(api.DeleteRackLayoutSlot(slot.ID)), 409)
+	st.Expect(t, api.DeleteDevicesFromRackSlots(rack.ID, conch.RequestRackAssignmentDeletes{
+		{DeviceID: "SERIAL1", RackUnitStart: 10},
+	}), nil)
+	st.Expect(t, api.DeleteRackLayoutSlot(slot.ID), nil)
+	st.Expect(t, api.DeleteRack(rack.ID), nil)
+
+	_, err = api.GetRack(rack.ID)
+	st.Expect(t, errors.Is(err, conch.ErrDataNotFound), true)
+}
+
+func TestWorkspaces(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+	admin := srv.Client()
+	rack, _ := seedRack(srv)
+
+	st.Expect(t, srv.AssignDevice("SERIAL1", rack.ID, 1), errors.New("no such device SERIAL1"))
+	srv.AddDevice(conch.Device{ID: "SERIAL1", Health: "pass"})
+	srv.AddDevice(conch.Device{ID: "SERIAL2", Health: "fail"})
+	st.Expect(t, srv.AssignDevice("SERIAL1", rack.ID, 1), nil)
+	st.Expect(t, srv.AssignDevice("SERIAL2", rack.ID, 3), nil)
+	st.Expect(t, admin.GraduateDevice("SERIAL1"), nil)
+
+	global, err := admin.GetWorkspaceByName("GLOBAL")
+	st.Expect(t, err, nil)
+	st.Expect(t, global.ID, srv.GlobalWorkspace.ID)
+	st.Expect(t, global.Role, "admin")
+
+	child, err := admin.CreateSubWorkspace(global, conch.Workspace{Name: "dev"})
+	st.Expect(t, err, nil)
+	st.Expect(t, child.ParentID, global.ID)
+
+	racks, err := admin.GetWorkspaceRacks(child.ID)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(racks), 0)
+
+	st.Expect(t, admin.AddRackToWorkspace(child.ID, rack.ID), nil)
+	racks, err = admin.GetWorkspaceRacks(child.ID)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(racks), 1)
+	st.Expect(t, racks[0].Datacenter, "us-east-1a")
+	st.Expect(t, racks[0].Role, "compute")
+
+	wr, err := admin.GetWorkspaceRack(child.ID, rack.ID)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(wr.Slots), 2)
+	st.Expect(t, wr.Slots[0].Occupant.ID, "SERIAL1")
+	st.Expect(t, wr.Slots[0].Vendor, "Acme")
+
+	devices, err := admin.GetWorkspaceDevices(child.ID, false, "t", "", "")
+	st.Expect(t, err, nil)
+	st.Expect(t, len(devices), 1)
+	st.Expect(t, devices[0].ID, "SERIAL1")
+
+	devices, err = admin.GetWorkspaceDevices(child.ID, true, "", "fail", "")
+	st.Expect(t, err, nil)
+	st.Expect(t, devices, conch.Devices{{ID: "SERIAL2"}})
+
+	t.Run("Roles", func(t *testing.T) {
+		user := srv.AddUser("ro@conch.test", "pw", "Read Only", false)
+		token, err := srv.AddToken(user.ID, "test")
+		st.Expect(t, err, nil)
+		ro := srv.Client(conch.WithToken(token))
+
+		_, err = ro.GetWorkspace(child.ID)
+		st.Expect(t, errors.Is(err, conch.ErrDataNotFound), true)
+
+		st.Expect(t, admin.AddUserToWorkspace(child.ID, "ro@conch.test", "ro"), nil)
+		ws, err := ro.GetWorkspace(child.ID)
+		st.Expect(t, err, nil)
+		st.Expect(t, ws.Role, "ro")
+
+		err = ro.AddRackToWorkspace(child.ID, rack.ID)
+		st.Expect(t, errors.Is(err, conch.ErrForbidden), true)
+
+		users, err := admin.GetWorkspaceUsers(child.ID)
+		st.Expect(t, err, nil)
+		st.Expect(t, len(users), 2)
+		st.Expect(t, users[0].Email, conchtest.AdminEmail)
+		st.Expect(t, users[0].RoleVia, srv.GlobalWorkspace.ID)
+		st.Expect(t, users[1].Email, "ro@conch.test")
+		st.Expect(t, users[1].RoleVia, child.ID)
+
+		st.Expect(t, admin.RemoveUserFromWorkspace(child.ID, "ro@conch.test"), nil)
+		_, err = ro.GetWorkspace(child.ID)
+		st.Expect(t, errors.Is(err, conch.ErrDataNotFound), true)
+	})
+}
+
+func TestDevices(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+	api := srv.Client()
+	rack, _ := seedRack(srv)
+
+	st.Expect(t, api.AssignWorkspaceDevicesToRackSlots(
+		srv.GlobalWorkspace.ID,
+		rack.ID,
+		conch.WorkspaceRackLayoutAssignments{"SERIAL1": 1, "SERIAL2": 3},
+	), nil)
+
+	t.Run("Settings", func(t *testing.T) {
+		st.Expect(t, api.SetDeviceSetting("SERIAL1", "build", "42"), nil)
+		st.Expect(t, api.SetDeviceTag("SERIAL1", "owner", "ops"), nil)
+
+		settings, err := api.GetDeviceSettings("SERIAL1")
+		st.Expect(t, err, nil)
+		st.Expect(t, settings, map[string]string{"build": "42"})
+
+		tag, err := api.GetDeviceTag("SERIAL1", "owner")
+		st.Expect(t, err, nil)
+		st.Expect(t, tag, "ops")
+
+		found, err := api.GetDevicesByTag("owner", "ops")
+		st.Expect(t, err, nil)
+		st.Expect(t, len(found), 1)
+		st.Expect(t, found[0].ID, "SERIAL1")
+
+		st.Expect(t, api.DeleteDeviceSetting("SERIAL1", "build"), nil)
+		_, err = api.GetDeviceSetting("SERIAL1", "build")
+		st.Expect(t, errors.Is(err, conch.ErrDataNotFound), true)
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		// Outside of the rack, and so of any workspace
+		srv.AddDevice(conch.Device{ID: "LOOSE1", Health: "fail"})
+
+		st.Expect(t, api.SetDeviceTag("SERIAL1", "role", "storage"), nil)
+		st.Expect(t, api.SetDeviceTag("SERIAL2", "role", "storage"), nil)
+		st.Expect(t, api.SetDeviceTag("LOOSE1", "role", "storage"), nil)
+		st.Expect(t, api.SetDeviceSetting("SERIAL2", "build", "42"), nil)
+		st.Expect(t, api.SetDeviceSetting("LOOSE1", "build", "42"), nil)
+		st.Expect(t, api.SetDevicePhase("SERIAL2", "production"), nil)
+		st.Expect(t, api.SetDevicePhase("LOOSE1", "production"), nil)
+
+		found, err := api.FindDevices(conch.NewDeviceQuery().
+			Tag("role", "storage").
+			Phase("production"))
+		st.Expect(t, err, nil)
+		st.Expect(t, deviceIDs(found), []string{"LOOSE1", "SERIAL2"})
+
+		found, err = api.FindDevices(conch.NewDeviceQuery().
+			Tag("role", "storage").
+			Setting("build", "42").
+			Health("fail").
+			IDsOnly())
+		st.Expect(t, err, nil)
+		st.Expect(t, found, conch.Devices{{ID: "LOOSE1"}})
+
+		found, err = api.FindDevices(conch.NewDeviceQuery().
+			Tag("role", "storage").
+			Rack(rack.ID).
+			IDsOnly())
+		st.Expect(t, err, nil)
+		st.Expect(t, found, conch.Devices{{ID: "SERIAL1"}, {ID: "SERIAL2"}})
+
+		found, err = api.FindDevices(conch.NewDeviceQuery().
+			Workspace(srv.GlobalWorkspace.ID).
+			Setting("build", "42").
+			Phase("production"))
+		st.Expect(t, err, nil)
+		st.Expect(t, deviceIDs(found), []string{"SERIAL2"})
+
+		found, err = api.FindDevices(conch.NewDeviceQuery().
+			Workspace(srv.GlobalWorkspace.ID).
+			Phase("production").
+			Graduated(false).
+			IDsOnly())
+		st.Expect(t, err, nil)
+		st.Expect(t, found, conch.Devices{{ID: "SERIAL2"}})
+
+		found, err = api.FindDevices(conch.NewDeviceQuery().
+			Workspace(srv.GlobalWorkspace.ID).
+			Tag("role", "storage").
+			Graduated(true))
+		st.Expect(t, err, nil)
+		st.Expect(t, len(found), 0)
+
+		_, err = api.FindDevices(conch.NewDeviceQuery().Health("fail"))
+		st.Expect(t, err, conch.ErrBadInput)
+	})
+
+	t.Run("Triton", func(t *testing.T) {
+		st.Expect(t, statusCode(api.MarkDeviceTritonSetup("SERIAL1")), 409)
+		st.Expect(t, api.SetDeviceTritonUUID("SERIAL1", uuid.NewV4()), nil)
+		st.Expect(t, api.MarkDeviceTritonSetup("SERIAL1"), nil)
+
+		d, err := api.GetDevice("SERIAL1")
+		st.Expect(t, err, nil)
+		st.Expect(t, d.TritonSetup.IsZero(), false)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		state, err := api.SubmitDeviceReport(
+			"SERIAL3",
+			`{"serial_number":"SERIAL3","os":{"hostname":"box3"}}`,
+		)
+		st.Expect(t, err, nil)
+		st.Expect(t, state.DeviceID, "SERIAL3")
+		st.Expect(t, state.Status, "pass")
+
+		found, err := api.GetDevicesByField("hostname", "box3")
+		st.Expect(t, err, nil)
+		st.Expect(t, len(found), 1)
+		st.Expect(t, found[0].ID, "SERIAL3")
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		devices, err := api.GetDevices([]string{"SERIAL1", "SERIAL2", "NOPE"})
+		st.Expect(t, len(devices), 2)
+		st.Expect(t, devices["SERIAL2"].Location.RackUnitStart, 3)
+
+		var berr conch.BatchError
+		st.Expect(t, errors.As(err, &berr), true)
+		st.Expect(t, errors.Is(berr["NOPE"], conch.ErrDataNotFound), true)
+	})
+}
+
+func TestValidationsAndRelays(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+	api := srv.Client()
+	rack, _ := seedRack(srv)
+
+	srv.AddDevice(conch.Device{ID: "SERIAL1"})
+	st.Expect(t, srv.AssignDevice("SERIAL1", rack.ID, 1), nil)
+
+	v := srv.AddValidation(conch.Validation{Name: "cpu_count", Version: 1})
+	plan := srv.AddValidationPlan(conch.ValidationPlan{Name: "server"}, v.ID)
+
+	results, err := api.RunDeviceValidationPlan("SERIAL1", plan.ID, `{}`)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(results), 1)
+	st.Expect(t, results[0].ValidationID, v.ID)
+
+	srv.AddValidationState(conch.ValidationState{
+		DeviceID:         "SERIAL1",
+		ValidationPlanID: plan.ID,
+		Results: []conch.ValidationResult{
+			{ValidationID: v.ID, Status: "fail"},
+		},
+	})
+	states, err := api.WorkspaceValidationStates(srv.GlobalWorkspace.ID)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(states), 1)
+	st.Expect(t, states[0].Status, "fail")
+
+	st.Expect(t, api.RegisterRelay(conch.WorkspaceRelay{
+		ID:      "relay0",
+		SSHPort: 22,
+		Version: "v1",
+	}), nil)
+	st.Expect(t, srv.LinkRelayDevice("relay0", "SERIAL1"), nil)
+
+	relays, err := api.GetWorkspaceRelays(srv.GlobalWorkspace.ID)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(relays), 1)
+	st.Expect(t, relays[0].NumDevices, 1)
+
+	devices, err := api.GetWorkspaceRelayDevices(srv.GlobalWorkspace.ID, "relay0")
+	st.Expect(t, err, nil)
+	st.Expect(t, len(devices), 1)
+}
+
+// fixtureID returns the ID of something in testdata/fixtures, which differ
+// only in their last few digits
+func fixtureID(t *testing.T, suffix string) uuid.UUID {
+	id, err := uuid.FromString("0b0b2c1e-5d5e-4f7e-9a3c-1a1a1a1a" + suffix)
+	st.Assert(t, err, nil)
+	return id
+}
+
+func TestFixtures(t *testing.T) {
+	srv := conchtest.NewServer()
+	defer srv.Close()
+	api := srv.Client()
+
+	st.Expect(t, srv.LoadFixtures("testdata/fixtures"), nil)
+
+	rack, err := api.GetRack(fixtureID(t, "0004"))
+	st.Expect(t, err, nil)
+	st.Expect(t, rack.Name, "A01")
+
+	products, err := api.GetHardwareProducts()
+	st.Expect(t, err, nil)
+	st.Expect(t, len(products), 1)
+	st.Expect(t, products[0].Profile.RackUnit, 2)
+
+	loc, err := api.GetDeviceLocation("SERIAL001")
+	st.Expect(t, err, nil)
+	st.Expect(t, loc.RackUnitStart, 1)
+	st.Expect(t, loc.TargetHardwareProduct.Vendor, "Acme")
+
+	build, err := api.GetDeviceSetting("SERIAL001", "build")
+	st.Expect(t, err, nil)
+	st.Expect(t, build, "rack-a01")
+
+	d, err := api.GetDevice("SERIAL001")
+	st.Expect(t, err, nil)
+	st.Expect(t, d.Hostname, "serial001.example.com")
+	st.Expect(t, d.Health, "pass")
+
+	d, err = api.GetDevice("SERIAL002")
+	st.Expect(t, err, nil)
+	st.Expect(t, d.Hostname, "serial002.example.com")
+
+	validations, err := api.GetValidationPlanValidations(
+		fixtureID(t, "0009"),
+	)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(validations), 1)
+	st.Expect(t, validations[0].Name, "cpu_count")
+
+	t.Run("Reset", func(t *testing.T) {
+		admin := srv.Admin.ID
+		srv.Reset()
+		st.Reject(t, srv.Admin.ID, admin)
+
+		_, err := api.GetDevice("SERIAL001")
+		st.Expect(t, errors.Is(err, conch.ErrDataNotFound), true)
+
+		st.Expect(t, srv.LoadFixtures("testdata/fixtures"), nil)
+		_, err = api.GetDevice("SERIAL001")
+		st.Expect(t, err, nil)
+	})
+
+	t.Run("BadReference", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "conchtest")
+		st.Expect(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		st.Expect(t, ioutil.WriteFile(
+			filepath.Join(dir, "rooms.json"),
+			[]byte(`[{"datacenter":"`+uuid.NewV4().String()+`","alias":"lost"}]`),
+			0644,
+		), nil)
+
+		err = conchtest.NewUnstartedServer().LoadFixtures(dir)
+		st.Reject(t, err, nil)
+		st.Expect(t, strings.HasPrefix(err.Error(), "rooms.json: "), true)
+	})
+}
@@ -0,0 +1,427 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+)
+
+// device returns a copy of a device with its location filled in
+func (s *Server) device(id string) conch.Device {
+	d := *s.devices[id]
+	if loc, ok := s.deviceLocation(id); ok {
+		d.Location = loc
+		d.RackID = loc.Rack.ID
+		d.RackUnitStart = loc.RackUnitStart
+	}
+	return d
+}
+
+func (s *Server) deviceLocation(id string) (conch.DeviceLocation, bool) {
+	rackID, ru, ok := s.deviceSlot(id)
+	if !ok {
+		return conch.DeviceLocation{}, false
+	}
+
+	loc := conch.DeviceLocation{
+		Rack:          *s.racks[rackID],
+		RackUnitStart: ru,
+	}
+	if room, ok := s.rooms[loc.Rack.DatacenterRoomID]; ok {
+		loc.Room = conch.DatacenterDetailedRoom{
+			ID:           room.ID,
+			AZ:           room.AZ,
+			Alias:        room.Alias,
+			VendorName:   room.VendorName,
+			DatacenterID: room.DatacenterID,
+			Created:      room.Created,
+			Updated:      room.Updated,
+		}
+		if dc, ok := s.datacenters[room.DatacenterID]; ok {
+			loc.Datacenter = *dc
+		}
+	}
+	if slot := s.layoutAt(rackID, ru); slot != nil {
+		if p, ok := s.products[slot.ProductID]; ok {
+			loc.TargetHardwareProduct = conch.HardwareProductTarget{
+				ID:    p.ID,
+				Name:  p.Name,
+				Alias: p.Alias,
+			}
+			if v, ok := s.vendors[p.HardwareVendorID]; ok {
+				loc.TargetHardwareProduct.Vendor = v.Name
+			}
+		}
+	}
+	return loc, true
+}
+
+// targetDevice finds the device named in the first path parameter
+func (s *Server) targetDevice(req *request) *conch.Device {
+	d, ok := s.devices[req.params[0]]
+	if !ok {
+		req.notFound()
+		return nil
+	}
+	return d
+}
+
+// findDevices handles /device?key=value. The well known fields are matched
+// directly and anything else is taken to be a device setting.
+func (s *Server) findDevices(req *request) {
+	q := req.r.URL.Query()
+	if len(q) != 1 {
+		req.error(http.StatusBadRequest, "exactly one search parameter is required")
+		return
+	}
+
+	var key, value string
+	for k, v := range q {
+		key, value = k, v[0]
+	}
+
+	devices := make(conch.Devices, 0)
+	for id, d := range s.devices {
+		match := false
+		switch key {
+		case "hostname":
+			match = d.Hostname == value
+		case "asset_tag":
+			match = d.AssetTag == value
+		case "mac":
+			for _, nic := range d.Nics {
+				if strings.EqualFold(nic.MAC, value) {
+					match = true
+				}
+			}
+		default:
+			v, ok := s.deviceSettings[id][key]
+			match = ok && (v == value)
+		}
+		if match {
+			devices = append(devices, s.device(id))
+		}
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	req.json(devices)
+}
+
+func (s *Server) getDevice(req *request) {
+	if d := s.targetDevice(req); d != nil {
+		req.json(s.device(d.ID))
+	}
+}
+
+// submitDeviceReport takes a device report from a relay or livesys
+func (s *Server) submitDeviceReport(req *request) {
+	body, err := readBody(req)
+	if err != nil {
+		return
+	}
+
+	state, err := s.recordReport(req.params[0], body)
+	if err != nil {
+		req.error(http.StatusBadRequest, err.Error())
+		return
+	}
+	req.json(state)
+}
+
+// recordReport stores a device report. The fake doesn't run validations. It
+// records the device, keeps the report, and answers with a passing
+// validation state.
+func (s *Server) recordReport(id string, body []byte) (conch.ValidationState, error) {
+	report := struct {
+		SerialNumber string    `json:"serial_number"`
+		SystemUUID   uuid.UUID `json:"system_uuid"`
+		OS           struct {
+			Hostname string `json:"hostname"`
+		} `json:"os"`
+	}{}
+	var raw interface{}
+
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return conch.ValidationState{}, err
+	}
+	_ = json.Unmarshal(body, &report)
+
+	if id == "" {
+		id = report.SerialNumber
+	}
+	if id == "" {
+		return conch.ValidationState{}, errors.New("report has no serial_number")
+	}
+	if (report.SerialNumber != "") && (report.SerialNumber != id) {
+		return conch.ValidationState{}, errors.New("serial number in URL does not match report")
+	}
+
+	d, ok := s.devices[id]
+	if !ok {
+		s.addDevice(conch.Device{ID: id})
+		d = s.devices[id]
+	}
+	d.LastSeen = now()
+	d.Updated = d.LastSeen
+	d.LatestReport = raw
+	if report.OS.Hostname != "" {
+		d.Hostname = report.OS.Hostname
+	}
+	if !report.SystemUUID.IsZero() {
+		d.SystemUUID = report.SystemUUID
+	}
+	if d.Health == "unknown" {
+		d.Health = "pass"
+	}
+
+	return s.addValidationState(conch.ValidationState{
+		DeviceID: id,
+		Status:   "pass",
+		Results:  make([]conch.ValidationResult, 0),
+	}), nil
+}
+
+func (s *Server) getDeviceLocation(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	loc, ok := s.deviceLocation(d.ID)
+	if !ok {
+		req.error(http.StatusNotFound, "device "+d.ID+" is not assigned to a rack")
+		return
+	}
+	req.json(loc)
+}
+
+func (s *Server) graduateDevice(req *request) {
+	if d := s.targetDevice(req); d != nil {
+		if d.Graduated.IsZero() {
+			d.Graduated = now()
+		}
+		req.noContent()
+	}
+}
+
+func (s *Server) tritonRebootDevice(req *request) {
+	if d := s.targetDevice(req); d != nil {
+		d.Updated = now()
+		req.noContent()
+	}
+}
+
+func (s *Server) setDeviceTritonUUID(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	in := struct {
+		TritonUUID uuid.UUID `json:"triton_uuid"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	d.TritonUUID = in.TritonUUID
+	req.noContent()
+}
+
+func (s *Server) markDeviceTritonSetup(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	if d.TritonUUID.IsZero() {
+		req.error(http.StatusConflict, "device "+d.ID+" must have a triton UUID set")
+		return
+	}
+	if d.TritonSetup.IsZero() {
+		d.TritonSetup = now()
+	}
+	req.noContent()
+}
+
+func (s *Server) setDeviceAssetTag(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	in := struct {
+		AssetTag string `json:"asset_tag"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	d.AssetTag = in.AssetTag
+	req.noContent()
+}
+
+func (s *Server) getDevicePhase(req *request) {
+	if d := s.targetDevice(req); d != nil {
+		req.json(map[string]string{"id": d.ID, "phase": d.Phase})
+	}
+}
+
+func (s *Server) setDevicePhase(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	in := struct {
+		Phase string `json:"phase"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if in.Phase == "" {
+		req.error(http.StatusBadRequest, "phase is required")
+		return
+	}
+	d.Phase = in.Phase
+	req.noContent()
+}
+
+/***/
+
+func (s *Server) getDeviceSettings(req *request) {
+	if d := s.targetDevice(req); d != nil {
+		req.json(s.deviceSettings[d.ID])
+	}
+}
+
+func (s *Server) getDeviceSetting(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	key := req.params[1]
+	v, ok := s.deviceSettings[d.ID][key]
+	if !ok {
+		req.notFound()
+		return
+	}
+	req.json(map[string]string{key: v})
+}
+
+func (s *Server) setDeviceSetting(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	key := req.params[1]
+	in := make(map[string]string)
+	if !req.decode(&in) {
+		return
+	}
+	v, ok := in[key]
+	if !ok || (len(in) != 1) {
+		req.error(http.StatusBadRequest, "setting key in request body must match name in the URL")
+		return
+	}
+	s.deviceSettings[d.ID][key] = v
+	req.noContent()
+}
+
+func (s *Server) deleteDeviceSetting(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	key := req.params[1]
+	if _, ok := s.deviceSettings[d.ID][key]; !ok {
+		req.notFound()
+		return
+	}
+	delete(s.deviceSettings[d.ID], key)
+	req.noContent()
+}
+
+/***/
+
+// latestValidationStates returns the most recent state for each validation
+// plan run against a device
+func (s *Server) latestValidationStates(deviceID string) []conch.ValidationState {
+	latest := make(map[uuid.UUID]conch.ValidationState)
+	for _, state := range s.validationStates[deviceID] {
+		if prev, ok := latest[state.ValidationPlanID]; !ok || !state.Created.Before(prev.Created) {
+			latest[state.ValidationPlanID] = state
+		}
+	}
+
+	states := make([]conch.ValidationState, 0, len(latest))
+	for _, state := range latest {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Created.Before(states[j].Created) })
+	return states
+}
+
+func (s *Server) getDeviceValidationStates(req *request) {
+	if d := s.targetDevice(req); d != nil {
+		req.json(s.latestValidationStates(d.ID))
+	}
+}
+
+// passingResult is what the fake answers for every validation it is asked to
+// run
+func passingResult(d *conch.Device, v *conch.Validation) conch.ValidationResult {
+	return conch.ValidationResult{
+		ID:              uuid.NewV4(),
+		Category:        "conchtest",
+		DeviceID:        d.ID,
+		HardwareProduct: d.HardwareProduct,
+		Message:         v.Name + " passed",
+		Status:          "pass",
+		ValidationID:    v.ID,
+	}
+}
+
+func (s *Server) runDeviceValidation(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	id, _ := parseID(req.params[1])
+	v, ok := s.validations[id]
+	if !ok {
+		req.notFound()
+		return
+	}
+	if _, err := readBody(req); err != nil {
+		return
+	}
+	req.json([]conch.ValidationResult{passingResult(d, v)})
+}
+
+func (s *Server) runDeviceValidationPlan(req *request) {
+	d := s.targetDevice(req)
+	if d == nil {
+		return
+	}
+	id, _ := parseID(req.params[1])
+	plan, ok := s.validationPlans[id]
+	if !ok {
+		req.notFound()
+		return
+	}
+	if _, err := readBody(req); err != nil {
+		return
+	}
+
+	results := make([]conch.ValidationResult, 0)
+	for _, vid := range plan.validations {
+		if v, ok := s.validations[vid]; ok {
+			results = append(results, passingResult(d, v))
+		}
+	}
+	req.json(results)
+}
@@ -0,0 +1,233 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+)
+
+// deviceFixture is a device as it appears in devices.json. Besides the usual
+// device fields, it can say where the device lives and what settings it has.
+type deviceFixture struct {
+	conch.Device
+	RackID        uuid.UUID         `json:"rack_id"`
+	RackUnitStart int               `json:"rack_unit_start"`
+	Settings      map[string]string `json:"settings"`
+}
+
+// validationPlanFixture is a validation plan as it appears in
+// validation_plans.json, along with the IDs of the validations it runs
+type validationPlanFixture struct {
+	conch.ValidationPlan
+	Validations []uuid.UUID `json:"validations"`
+}
+
+// LoadFixtures adds the contents of a fixture directory to the server. Every
+// file is optional:
+//
+//	datacenters.json        []conch.Datacenter
+//	rooms.json              []conch.Room
+//	rack_roles.json         []conch.RackRole
+//	racks.json              []conch.Rack
+//	hardware_vendors.json   []conch.HardwareVendor
+//	hardware_products.json  []conch.HardwareProduct
+//	layouts.json            []conch.RackLayoutSlot
+//	devices.json            []conch.Device, plus "rack_id",
+//	                        "rack_unit_start", and "settings"
+//	validations.json        []conch.Validation
+//	validation_plans.json   []conch.ValidationPlan, plus "validations", a
+//	                        list of validation IDs
+//	reports/*.json          device reports, as a livesys would submit them
+//
+// Things are in the shape the API sends them, so fixtures can be captured
+// from a real API. IDs are kept so files can refer to each other. Anything
+// without an ID is given one. A reference to something that doesn't exist
+// is an error, and loading stops at the first one. Whatever was loaded
+// before that stays loaded.
+func (s *Server) LoadFixtures(dir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var datacenters []conch.Datacenter
+	if err := readFixture(dir, "datacenters.json", &datacenters); err != nil {
+		return err
+	}
+	for _, d := range datacenters {
+		s.addDatacenter(d)
+	}
+
+	var rooms []conch.Room
+	if err := readFixture(dir, "rooms.json", &rooms); err != nil {
+		return err
+	}
+	for _, r := range rooms {
+		if _, ok := s.datacenters[r.DatacenterID]; !ok {
+			return fmt.Errorf("rooms.json: room %s: no such datacenter %s", r.Alias, r.DatacenterID)
+		}
+		s.addRoom(r)
+	}
+
+	var roles []conch.RackRole
+	if err := readFixture(dir, "rack_roles.json", &roles); err != nil {
+		return err
+	}
+	for _, r := range roles {
+		s.addRackRole(r)
+	}
+
+	var racks []conch.Rack
+	if err := readFixture(dir, "racks.json", &racks); err != nil {
+		return err
+	}
+	for _, r := range racks {
+		if _, ok := s.rooms[r.DatacenterRoomID]; !ok {
+			return fmt.Errorf("racks.json: rack %s: no such room %s", r.Name, r.DatacenterRoomID)
+		}
+		if _, ok := s.rackRoles[r.RoleID]; !ok {
+			return fmt.Errorf("racks.json: rack %s: no such rack role %s", r.Name, r.RoleID)
+		}
+		s.addRack(r)
+	}
+
+	var vendors []conch.HardwareVendor
+	if err := readFixture(dir, "hardware_vendors.json", &vendors); err != nil {
+		return err
+	}
+	for _, v := range vendors {
+		s.addHardwareVendor(v)
+	}
+
+	var products []conch.HardwareProduct
+	if err := readFixture(dir, "hardware_products.json", &products); err != nil {
+		return err
+	}
+	for _, p := range products {
+		if _, ok := s.vendors[p.HardwareVendorID]; !ok {
+			return fmt.Errorf("hardware_products.json: product %s: no such hardware vendor %s", p.Name, p.HardwareVendorID)
+		}
+		s.addHardwareProduct(p)
+	}
+
+	var layouts []conch.RackLayoutSlot
+	if err := readFixture(dir, "layouts.json", &layouts); err != nil {
+		return err
+	}
+	for _, l := range layouts {
+		if _, ok := s.racks[l.RackID]; !ok {
+			return fmt.Errorf("layouts.json: no such rack %s", l.RackID)
+		}
+		if _, ok := s.products[l.ProductID]; !ok {
+			return fmt.Errorf("layouts.json: no such hardware product %s", l.ProductID)
+		}
+		if s.layoutAt(l.RackID, l.RUStart) != nil {
+			return fmt.Errorf("layouts.json: rack %s already has a slot at rack unit %d", l.RackID, l.RUStart)
+		}
+		s.addLayoutSlot(l)
+	}
+
+	var devices []deviceFixture
+	if err := readFixture(dir, "devices.json", &devices); err != nil {
+		return err
+	}
+	for _, d := range devices {
+		if d.ID == "" {
+			return fmt.Errorf("devices.json: a device has no id")
+		}
+		s.addDevice(d.Device)
+		for k, v := range d.Settings {
+			s.deviceSettings[d.ID][k] = v
+		}
+		if !d.RackID.IsZero() {
+			if err := s.assignDevice(d.ID, d.RackID, d.RackUnitStart); err != nil {
+				return fmt.Errorf("devices.json: device %s: %s", d.ID, err)
+			}
+		}
+	}
+
+	var validations []conch.Validation
+	if err := readFixture(dir, "validations.json", &validations); err != nil {
+		return err
+	}
+	for _, v := range validations {
+		s.addValidation(v)
+	}
+
+	var plans []validationPlanFixture
+	if err := readFixture(dir, "validation_plans.json", &plans); err != nil {
+		return err
+	}
+	for _, p := range plans {
+		for _, id := range p.Validations {
+			if _, ok := s.validations[id]; !ok {
+				return fmt.Errorf("validation_plans.json: plan %s: no such validation %s", p.Name, id)
+			}
+		}
+		s.addValidationPlan(p.ValidationPlan, p.Validations...)
+	}
+
+	return s.loadReports(filepath.Join(dir, "reports"))
+}
+
+// loadReports submits every *.json file in dir as a device report, in name
+// order. A report without a serial number is taken to be for the device
+// named by the file.
+func (s *Server) loadReports(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+	for _, f := range files {
+		if f.IsDir() || (filepath.Ext(f.Name()) != ".json") {
+			continue
+		}
+		body, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return err
+		}
+
+		serial := struct {
+			SerialNumber string `json:"serial_number"`
+		}{}
+		_ = json.Unmarshal(body, &serial)
+		if serial.SerialNumber == "" {
+			serial.SerialNumber = strings.TrimSuffix(f.Name(), ".json")
+		}
+
+		if _, err := s.recordReport(serial.SerialNumber, body); err != nil {
+			return fmt.Errorf("reports/%s: %s", f.Name(), err)
+		}
+	}
+	return nil
+}
+
+// readFixture decodes a fixture file into v. A missing file is not an error
+// and leaves v alone.
+func readFixture(dir string, name string, v interface{}) error {
+	body, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	return nil
+}
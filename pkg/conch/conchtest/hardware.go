@@ -0,0 +1,187 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+)
+
+// productJSON gives a hardware product the shape the API sends, where the
+// specification is a string of JSON rather than an object
+func productJSON(p *conch.HardwareProduct) interface{} {
+	spec := ""
+	if p.Specification != nil {
+		if str, ok := p.Specification.(string); ok {
+			spec = str
+		} else if j, err := json.Marshal(p.Specification); err == nil {
+			spec = string(j)
+		}
+	}
+
+	return struct {
+		*conch.HardwareProduct
+		Specification string `json:"specification"`
+	}{p, spec}
+}
+
+func (s *Server) targetProduct(req *request) *conch.HardwareProduct {
+	id, ok := targetID(req)
+	if !ok {
+		return nil
+	}
+	p, ok := s.products[id]
+	if !ok {
+		req.notFound()
+		return nil
+	}
+	return p
+}
+
+func (s *Server) getHardwareProducts(req *request) {
+	ret := make([]interface{}, 0)
+	ids := make([]uuid.UUID, 0)
+	for id := range s.products {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return s.products[ids[i]].Name < s.products[ids[j]].Name })
+	for _, id := range ids {
+		ret = append(ret, productJSON(s.products[id]))
+	}
+	req.json(ret)
+}
+
+func (s *Server) getHardwareProduct(req *request) {
+	if p := s.targetProduct(req); p != nil {
+		req.json(productJSON(p))
+	}
+}
+
+func (s *Server) saveHardwareProduct(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+
+	p := &conch.HardwareProduct{ID: uuid.NewV4(), Created: now()}
+	if updating(req) {
+		if p = s.targetProduct(req); p == nil {
+			return
+		}
+	}
+
+	var in conch.HardwareProduct
+	if !req.decode(&in) {
+		return
+	}
+	if (in.Name == "") || (in.Alias == "") {
+		req.error(http.StatusBadRequest, "name and alias are required")
+		return
+	}
+	if _, ok := s.vendors[in.HardwareVendorID]; !ok {
+		req.error(http.StatusBadRequest, "hardware vendor does not exist")
+		return
+	}
+	for _, other := range s.products {
+		if (other.ID != p.ID) && ((other.Name == in.Name) || (other.Alias == in.Alias)) {
+			req.error(http.StatusConflict, "a hardware product already exists with that name or alias")
+			return
+		}
+	}
+
+	in.ID, in.Created, in.Updated = p.ID, p.Created, now()
+	in.Profile.ID = p.Profile.ID
+	if in.Profile.ID.IsZero() {
+		in.Profile.ID = uuid.NewV4()
+	}
+	*p = in
+	s.products[p.ID] = p
+	req.json(productJSON(p))
+}
+
+func (s *Server) deleteHardwareProduct(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	p := s.targetProduct(req)
+	if p == nil {
+		return
+	}
+	for _, l := range s.layouts {
+		if l.ProductID == p.ID {
+			req.error(http.StatusConflict, "cannot delete a hardware product used in a rack layout")
+			return
+		}
+	}
+	delete(s.products, p.ID)
+	req.noContent()
+}
+
+/***/
+
+// targetVendor finds the vendor, by name or ID, named in the first path
+// parameter
+func (s *Server) targetVendor(req *request) *conch.HardwareVendor {
+	if id, ok := parseID(req.params[0]); ok {
+		if v, ok := s.vendors[id]; ok {
+			return v
+		}
+	}
+	if v := s.vendorByName(req.params[0]); v != nil {
+		return v
+	}
+	req.notFound()
+	return nil
+}
+
+func (s *Server) getHardwareVendors(req *request) {
+	ret := make([]conch.HardwareVendor, 0)
+	for _, v := range s.vendors {
+		ret = append(ret, *v)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	req.json(ret)
+}
+
+func (s *Server) getHardwareVendor(req *request) {
+	if v := s.targetVendor(req); v != nil {
+		req.json(v)
+	}
+}
+
+func (s *Server) createHardwareVendor(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	name := req.params[0]
+	if s.vendorByName(name) != nil {
+		req.error(http.StatusConflict, "a hardware vendor already exists with that name")
+		return
+	}
+	req.json(s.addHardwareVendor(conch.HardwareVendor{Name: name}))
+}
+
+func (s *Server) deleteHardwareVendor(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	v := s.targetVendor(req)
+	if v == nil {
+		return
+	}
+	for _, p := range s.products {
+		if p.HardwareVendorID == v.ID {
+			req.error(http.StatusConflict, "cannot delete a hardware vendor that still has products")
+			return
+		}
+	}
+	delete(s.vendors, v.ID)
+	req.noContent()
+}
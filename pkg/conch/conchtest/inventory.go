@@ -0,0 +1,626 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+)
+
+// targetID parses the UUID in the first path parameter. ok is false, and a
+// 404 has been sent, if it isn't one.
+func targetID(req *request) (uuid.UUID, bool) {
+	id, ok := parseID(req.params[0])
+	if !ok {
+		req.notFound()
+	}
+	return id, ok
+}
+
+// updating reports if a save handler was reached through /thing/:id, as
+// opposed to /thing
+func updating(req *request) bool {
+	return len(req.params) > 0
+}
+
+func (s *Server) getDatacenters(req *request) {
+	ret := make([]conch.Datacenter, 0)
+	for _, d := range s.datacenters {
+		ret = append(ret, *d)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Region < ret[j].Region })
+	req.json(ret)
+}
+
+func (s *Server) getDatacenter(req *request) {
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	d, ok := s.datacenters[id]
+	if !ok {
+		req.notFound()
+		return
+	}
+	req.json(d)
+}
+
+func (s *Server) saveDatacenter(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+
+	d := &conch.Datacenter{ID: uuid.NewV4(), Created: now()}
+	if updating(req) {
+		id, ok := targetID(req)
+		if !ok {
+			return
+		}
+		if d, ok = s.datacenters[id]; !ok {
+			req.notFound()
+			return
+		}
+	}
+
+	in := struct {
+		Vendor     string `json:"vendor"`
+		Region     string `json:"region"`
+		Location   string `json:"location"`
+		VendorName string `json:"vendor_name"`
+	}{d.Vendor, d.Region, d.Location, d.VendorName}
+	if !req.decode(&in) {
+		return
+	}
+	if (in.Vendor == "") || (in.Region == "") || (in.Location == "") {
+		req.error(http.StatusBadRequest, "vendor, region, and location are required")
+		return
+	}
+
+	d.Vendor, d.Region, d.Location, d.VendorName = in.Vendor, in.Region, in.Location, in.VendorName
+	d.Updated = now()
+	s.datacenters[d.ID] = d
+	req.json(d)
+}
+
+func (s *Server) deleteDatacenter(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	if _, ok := s.datacenters[id]; !ok {
+		req.notFound()
+		return
+	}
+	for _, r := range s.rooms {
+		if r.DatacenterID == id {
+			req.error(http.StatusConflict, "cannot delete a datacenter that still has rooms")
+			return
+		}
+	}
+	delete(s.datacenters, id)
+	req.noContent()
+}
+
+func (s *Server) getDatacenterRooms(req *request) {
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	if _, ok := s.datacenters[id]; !ok {
+		req.notFound()
+		return
+	}
+
+	ret := make([]conch.Room, 0)
+	for _, r := range s.rooms {
+		if r.DatacenterID == id {
+			ret = append(ret, *r)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].AZ < ret[j].AZ })
+	req.json(ret)
+}
+
+/***/
+
+func (s *Server) getRooms(req *request) {
+	ret := make([]conch.Room, 0)
+	for _, r := range s.rooms {
+		ret = append(ret, *r)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].AZ < ret[j].AZ })
+	req.json(ret)
+}
+
+func (s *Server) getRoom(req *request) {
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	r, ok := s.rooms[id]
+	if !ok {
+		req.notFound()
+		return
+	}
+	req.json(r)
+}
+
+func (s *Server) saveRoom(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+
+	r := &conch.Room{ID: uuid.NewV4(), Created: now()}
+	if updating(req) {
+		id, ok := targetID(req)
+		if !ok {
+			return
+		}
+		if r, ok = s.rooms[id]; !ok {
+			req.notFound()
+			return
+		}
+	}
+
+	in := struct {
+		Datacenter uuid.UUID `json:"datacenter"`
+		AZ         string    `json:"az"`
+		Alias      string    `json:"alias"`
+		VendorName string    `json:"vendor_name"`
+	}{r.DatacenterID, r.AZ, r.Alias, r.VendorName}
+	if !req.decode(&in) {
+		return
+	}
+	if _, ok := s.datacenters[in.Datacenter]; !ok {
+		req.error(http.StatusBadRequest, "datacenter does not exist")
+		return
+	}
+	if (in.AZ == "") || (in.Alias == "") {
+		req.error(http.StatusBadRequest, "az and alias are required")
+		return
+	}
+
+	r.DatacenterID, r.AZ, r.Alias, r.VendorName = in.Datacenter, in.AZ, in.Alias, in.VendorName
+	r.Updated = now()
+	s.rooms[r.ID] = r
+	req.json(r)
+}
+
+func (s *Server) deleteRoom(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	if _, ok := s.rooms[id]; !ok {
+		req.notFound()
+		return
+	}
+	for _, r := range s.racks {
+		if r.DatacenterRoomID == id {
+			req.error(http.StatusConflict, "cannot delete a datacenter room that still has racks")
+			return
+		}
+	}
+	delete(s.rooms, id)
+	req.noContent()
+}
+
+func (s *Server) getRoomRacks(req *request) {
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	if _, ok := s.rooms[id]; !ok {
+		req.notFound()
+		return
+	}
+
+	ret := make([]conch.Rack, 0)
+	for _, r := range s.racks {
+		if r.DatacenterRoomID == id {
+			ret = append(ret, *r)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	req.json(ret)
+}
+
+/***/
+
+func (s *Server) getRackRoles(req *request) {
+	ret := make([]conch.RackRole, 0)
+	for _, r := range s.rackRoles {
+		ret = append(ret, *r)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	req.json(ret)
+}
+
+func (s *Server) getRackRole(req *request) {
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	r, ok := s.rackRoles[id]
+	if !ok {
+		req.notFound()
+		return
+	}
+	req.json(r)
+}
+
+func (s *Server) saveRackRole(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+
+	r := &conch.RackRole{ID: uuid.NewV4(), Created: now()}
+	if updating(req) {
+		id, ok := targetID(req)
+		if !ok {
+			return
+		}
+		if r, ok = s.rackRoles[id]; !ok {
+			req.notFound()
+			return
+		}
+	}
+
+	in := struct {
+		Name     string `json:"name"`
+		RackSize int    `json:"rack_size"`
+	}{r.Name, r.RackSize}
+	if !req.decode(&in) {
+		return
+	}
+	if (in.Name == "") || (in.RackSize <= 0) {
+		req.error(http.StatusBadRequest, "name and rack_size are required")
+		return
+	}
+	for _, other := range s.rackRoles {
+		if (other.Name == in.Name) && (other.ID != r.ID) {
+			req.error(http.StatusConflict, "a rack role already exists with that name")
+			return
+		}
+	}
+
+	r.Name, r.RackSize = in.Name, in.RackSize
+	r.Updated = now()
+	s.rackRoles[r.ID] = r
+	req.json(r)
+}
+
+func (s *Server) deleteRackRole(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	if _, ok := s.rackRoles[id]; !ok {
+		req.notFound()
+		return
+	}
+	for _, r := range s.racks {
+		if r.RoleID == id {
+			req.error(http.StatusConflict, "cannot delete a rack role that is still in use")
+			return
+		}
+	}
+	delete(s.rackRoles, id)
+	req.noContent()
+}
+
+/***/
+
+func (s *Server) targetRack(req *request) *conch.Rack {
+	id, ok := targetID(req)
+	if !ok {
+		return nil
+	}
+	r, ok := s.racks[id]
+	if !ok {
+		req.notFound()
+		return nil
+	}
+	return r
+}
+
+func (s *Server) getRacks(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	ret := make([]conch.Rack, 0)
+	for _, r := range s.racks {
+		ret = append(ret, *r)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	req.json(ret)
+}
+
+func (s *Server) getRack(req *request) {
+	if r := s.targetRack(req); r != nil {
+		req.json(r)
+	}
+}
+
+func (s *Server) saveRack(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+
+	r := &conch.Rack{ID: uuid.NewV4(), Created: now(), Phase: "integration"}
+	if updating(req) {
+		if r = s.targetRack(req); r == nil {
+			return
+		}
+	}
+
+	in := struct {
+		DatacenterRoomID uuid.UUID `json:"datacenter_room_id"`
+		Name             string    `json:"name"`
+		RoleID           uuid.UUID `json:"role"`
+		SerialNumber     string    `json:"serial_number"`
+		AssetTag         string    `json:"asset_tag"`
+	}{r.DatacenterRoomID, r.Name, r.RoleID, r.SerialNumber, r.AssetTag}
+	if !req.decode(&in) {
+		return
+	}
+	if _, ok := s.rooms[in.DatacenterRoomID]; !ok {
+		req.error(http.StatusBadRequest, "room does not exist")
+		return
+	}
+	if _, ok := s.rackRoles[in.RoleID]; !ok {
+		req.error(http.StatusBadRequest, "rack role does not exist")
+		return
+	}
+	if in.Name == "" {
+		req.error(http.StatusBadRequest, "name is required")
+		return
+	}
+
+	r.DatacenterRoomID, r.Name, r.RoleID = in.DatacenterRoomID, in.Name, in.RoleID
+	r.SerialNumber, r.AssetTag = in.SerialNumber, in.AssetTag
+	r.Updated = now()
+	s.racks[r.ID] = r
+	req.json(r)
+}
+
+func (s *Server) deleteRack(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	r := s.targetRack(req)
+	if r == nil {
+		return
+	}
+	if len(s.rackLayout(r.ID)) > 0 {
+		req.error(http.StatusConflict, "cannot delete a rack that still has a layout")
+		return
+	}
+	for _, ws := range s.workspaces {
+		delete(ws.racks, r.ID)
+	}
+	delete(s.racks, r.ID)
+	req.noContent()
+}
+
+func (s *Server) getRackLayouts(req *request) {
+	if r := s.targetRack(req); r != nil {
+		req.json(s.rackLayout(r.ID))
+	}
+}
+
+func (s *Server) setRackPhase(req *request) {
+	r := s.targetRack(req)
+	if r == nil {
+		return
+	}
+	in := struct {
+		Phase string `json:"phase"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if in.Phase == "" {
+		req.error(http.StatusBadRequest, "phase is required")
+		return
+	}
+
+	r.Phase = in.Phase
+	r.Updated = now()
+	if req.query("rack_only") == "" {
+		for _, id := range s.assignments[r.ID] {
+			s.devices[id].Phase = in.Phase
+		}
+	}
+	req.noContent()
+}
+
+func (s *Server) getRackAssignments(req *request) {
+	r := s.targetRack(req)
+	if r == nil {
+		return
+	}
+
+	ret := make(conch.ResponseRackAssignments, 0)
+	for _, l := range s.rackLayout(r.ID) {
+		a := conch.ResponseRackAssignment{RackUnitStart: l.RUStart}
+		if p, ok := s.products[l.ProductID]; ok {
+			a.HardwareProduct = p.Name
+			a.RackUnitSize = p.Profile.RackUnit
+		}
+		if id, ok := s.assignments[r.ID][l.RUStart]; ok {
+			a.DeviceID = id
+			a.DeviceAssetTag = s.devices[id].AssetTag
+		}
+		ret = append(ret, a)
+	}
+	req.json(ret)
+}
+
+func (s *Server) assignRackSlots(req *request) {
+	r := s.targetRack(req)
+	if r == nil {
+		return
+	}
+	in := make(conch.RequestRackAssignmentUpdates, 0)
+	if !req.decode(&in) {
+		return
+	}
+
+	for _, a := range in {
+		if s.layoutAt(r.ID, a.RackUnitStart) == nil {
+			req.error(http.StatusConflict, "no slot at rack unit "+strconv.Itoa(a.RackUnitStart))
+			return
+		}
+	}
+	for _, a := range in {
+		if _, ok := s.devices[a.DeviceID]; !ok {
+			s.addDevice(conch.Device{ID: a.DeviceID})
+		}
+		if a.DeviceAssetTag != "" {
+			s.devices[a.DeviceID].AssetTag = a.DeviceAssetTag
+		}
+		_ = s.assignDevice(a.DeviceID, r.ID, a.RackUnitStart)
+	}
+	req.noContent()
+}
+
+func (s *Server) unassignRackSlots(req *request) {
+	r := s.targetRack(req)
+	if r == nil {
+		return
+	}
+	in := make(conch.RequestRackAssignmentDeletes, 0)
+	if !req.decode(&in) {
+		return
+	}
+
+	for _, a := range in {
+		if s.assignments[r.ID][a.RackUnitStart] != a.DeviceID {
+			req.error(
+				http.StatusNotFound,
+				"device "+a.DeviceID+" is not at rack unit "+strconv.Itoa(a.RackUnitStart),
+			)
+			return
+		}
+	}
+	for _, a := range in {
+		delete(s.assignments[r.ID], a.RackUnitStart)
+	}
+	req.noContent()
+}
+
+/***/
+
+func (s *Server) getLayouts(req *request) {
+	ret := make(conch.RackLayoutSlots, 0)
+	for _, l := range s.layouts {
+		ret = append(ret, *l)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].RackID != ret[j].RackID {
+			return ret[i].RackID.String() < ret[j].RackID.String()
+		}
+		return ret[i].RUStart < ret[j].RUStart
+	})
+	req.json(ret)
+}
+
+func (s *Server) getLayout(req *request) {
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	l, ok := s.layouts[id]
+	if !ok {
+		req.notFound()
+		return
+	}
+	req.json(l)
+}
+
+func (s *Server) saveLayout(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+
+	l := &conch.RackLayoutSlot{ID: uuid.NewV4(), Created: now()}
+	if updating(req) {
+		id, ok := targetID(req)
+		if !ok {
+			return
+		}
+		if l, ok = s.layouts[id]; !ok {
+			req.notFound()
+			return
+		}
+	}
+
+	in := struct {
+		RackID    uuid.UUID `json:"rack_id"`
+		ProductID uuid.UUID `json:"product_id"`
+		RUStart   int       `json:"ru_start"`
+	}{l.RackID, l.ProductID, l.RUStart}
+	if !req.decode(&in) {
+		return
+	}
+	if _, ok := s.racks[in.RackID]; !ok {
+		req.error(http.StatusBadRequest, "rack does not exist")
+		return
+	}
+	if _, ok := s.products[in.ProductID]; !ok {
+		req.error(http.StatusBadRequest, "hardware product does not exist")
+		return
+	}
+	if in.RUStart <= 0 {
+		req.error(http.StatusBadRequest, "ru_start is required")
+		return
+	}
+	if other := s.layoutAt(in.RackID, in.RUStart); (other != nil) && (other.ID != l.ID) {
+		req.error(http.StatusConflict, "rack unit "+strconv.Itoa(in.RUStart)+" is already occupied")
+		return
+	}
+
+	l.RackID, l.ProductID, l.RUStart = in.RackID, in.ProductID, in.RUStart
+	l.Updated = now()
+	s.layouts[l.ID] = l
+	req.json(l)
+}
+
+func (s *Server) deleteLayout(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	l, ok := s.layouts[id]
+	if !ok {
+		req.notFound()
+		return
+	}
+	if _, occupied := s.assignments[l.RackID][l.RUStart]; occupied {
+		req.error(http.StatusConflict, "cannot delete a layout slot that is occupied by a device")
+		return
+	}
+	delete(s.layouts, id)
+	req.noContent()
+}
@@ -0,0 +1,53 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+)
+
+func (s *Server) getRelays(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	relays := make(conch.WorkspaceRelays, 0)
+	for id, r := range s.relays {
+		relay := *r
+		relay.NumDevices = len(s.relayDevices[id])
+		relays = append(relays, relay)
+	}
+	sort.Sort(relays)
+	req.json(relays)
+}
+
+func (s *Server) registerRelay(req *request) {
+	in := struct {
+		Alias   string `json:"alias"`
+		IPAddr  string `json:"ipaddr"`
+		SSHPort int    `json:"ssh_port"`
+		Version string `json:"version"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if (in.SSHPort == 0) || (in.Version == "") {
+		req.error(http.StatusBadRequest, "ssh_port and version are required")
+		return
+	}
+
+	s.addRelay(conch.WorkspaceRelay{
+		ID:      req.params[0],
+		Alias:   in.Alias,
+		IPAddr:  in.IPAddr,
+		SSHPort: in.SSHPort,
+		Version: in.Version,
+	})
+	req.noContent()
+}
@@ -0,0 +1,138 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import "net/http"
+
+const (
+	get  = http.MethodGet
+	post = http.MethodPost
+	del  = http.MethodDelete
+)
+
+// buildRoutes lists every route the fake knows. The first match wins, so
+// literal routes like /user/me must come before /user/:user.
+func (s *Server) buildRoutes() []route {
+	return []route{
+		public(on(get, "/version", s.getVersion)),
+		public(on(post, "/login", s.login)),
+		on(post, "/refresh_token", s.refreshToken),
+
+		on(get, "/user/me", s.getMe),
+		on(get, "/user/me/settings", s.getMySettings),
+		on(post, "/user/me/settings", s.setMySettings),
+		on(get, "/user/me/settings/:key", s.getMySetting),
+		on(post, "/user/me/settings/:key", s.setMySetting),
+		on(del, "/user/me/settings/:key", s.deleteMySetting),
+		on(post, "/user/me/token", s.createMyToken),
+		on(post, "/user/me/password", s.changeMyPassword),
+
+		on(get, "/user", s.getUsers),
+		on(post, "/user", s.createUser),
+		on(get, "/user/:user", s.getUser),
+		on(post, "/user/:user", s.updateUser),
+		on(del, "/user/:user", s.deleteUser),
+		on(post, "/user/:user/revoke", s.revokeUser),
+		on(del, "/user/:user/password", s.resetUserPassword),
+		on(get, "/user/:user/token", s.getUserTokens),
+		on(get, "/user/:user/token/:name", s.getUserToken),
+		on(del, "/user/:user/token/:name", s.deleteUserToken),
+
+		on(get, "/workspace", s.getWorkspaces),
+		on(get, "/workspace/:ws", s.getWorkspace),
+		on(get, "/workspace/:ws/child", s.getChildWorkspaces),
+		on(post, "/workspace/:ws/child", s.createChildWorkspace),
+		on(get, "/workspace/:ws/rack", s.getWorkspaceRacks),
+		on(post, "/workspace/:ws/rack", s.addWorkspaceRack),
+		on(get, "/workspace/:ws/rack/:rack", s.getWorkspaceRack),
+		on(del, "/workspace/:ws/rack/:rack", s.removeWorkspaceRack),
+		on(post, "/workspace/:ws/rack/:rack/layout", s.assignWorkspaceRackLayout),
+		on(get, "/workspace/:ws/device", s.getWorkspaceDevices),
+		on(get, "/workspace/:ws/user", s.getWorkspaceUsers),
+		on(post, "/workspace/:ws/user", s.addWorkspaceUser),
+		on(del, "/workspace/:ws/user/:user", s.removeWorkspaceUser),
+		on(get, "/workspace/:ws/relay", s.getWorkspaceRelays),
+		on(get, "/workspace/:ws/relay/:relay/device", s.getWorkspaceRelayDevices),
+		on(get, "/workspace/:ws/validation_state", s.getWorkspaceValidationStates),
+
+		on(get, "/device", s.findDevices),
+		on(get, "/device/:device", s.getDevice),
+		on(post, "/device/:device", s.submitDeviceReport),
+		on(get, "/device/:device/location", s.getDeviceLocation),
+		on(post, "/device/:device/graduate", s.graduateDevice),
+		on(post, "/device/:device/triton_reboot", s.tritonRebootDevice),
+		on(post, "/device/:device/triton_uuid", s.setDeviceTritonUUID),
+		on(post, "/device/:device/triton_setup", s.markDeviceTritonSetup),
+		on(post, "/device/:device/asset_tag", s.setDeviceAssetTag),
+		on(get, "/device/:device/phase", s.getDevicePhase),
+		on(post, "/device/:device/phase", s.setDevicePhase),
+		on(get, "/device/:device/settings", s.getDeviceSettings),
+		on(get, "/device/:device/settings/:key", s.getDeviceSetting),
+		on(post, "/device/:device/settings/:key", s.setDeviceSetting),
+		on(del, "/device/:device/settings/:key", s.deleteDeviceSetting),
+		on(get, "/device/:device/validation_state", s.getDeviceValidationStates),
+		on(post, "/device/:device/validation/:validation", s.runDeviceValidation),
+		on(post, "/device/:device/validation_plan/:plan", s.runDeviceValidationPlan),
+
+		on(get, "/dc", s.getDatacenters),
+		on(post, "/dc", s.saveDatacenter),
+		on(get, "/dc/:dc", s.getDatacenter),
+		on(post, "/dc/:dc", s.saveDatacenter),
+		on(del, "/dc/:dc", s.deleteDatacenter),
+		on(get, "/dc/:dc/rooms", s.getDatacenterRooms),
+
+		on(get, "/room", s.getRooms),
+		on(post, "/room", s.saveRoom),
+		on(get, "/room/:room", s.getRoom),
+		on(post, "/room/:room", s.saveRoom),
+		on(del, "/room/:room", s.deleteRoom),
+		on(get, "/room/:room/racks", s.getRoomRacks),
+
+		on(get, "/rack_role", s.getRackRoles),
+		on(post, "/rack_role", s.saveRackRole),
+		on(get, "/rack_role/:role", s.getRackRole),
+		on(post, "/rack_role/:role", s.saveRackRole),
+		on(del, "/rack_role/:role", s.deleteRackRole),
+
+		on(get, "/rack", s.getRacks),
+		on(post, "/rack", s.saveRack),
+		on(get, "/rack/:rack", s.getRack),
+		on(post, "/rack/:rack", s.saveRack),
+		on(del, "/rack/:rack", s.deleteRack),
+		on(get, "/rack/:rack/layouts", s.getRackLayouts),
+		on(post, "/rack/:rack/phase", s.setRackPhase),
+		on(get, "/rack/:rack/assignment", s.getRackAssignments),
+		on(post, "/rack/:rack/assignment", s.assignRackSlots),
+		on(del, "/rack/:rack/assignment", s.unassignRackSlots),
+
+		on(get, "/layout", s.getLayouts),
+		on(post, "/layout", s.saveLayout),
+		on(get, "/layout/:layout", s.getLayout),
+		on(post, "/layout/:layout", s.saveLayout),
+		on(del, "/layout/:layout", s.deleteLayout),
+
+		on(get, "/hardware_product", s.getHardwareProducts),
+		on(post, "/hardware_product", s.saveHardwareProduct),
+		on(get, "/hardware_product/:product", s.getHardwareProduct),
+		on(post, "/hardware_product/:product", s.saveHardwareProduct),
+		on(del, "/hardware_product/:product", s.deleteHardwareProduct),
+
+		on(get, "/hardware_vendor", s.getHardwareVendors),
+		on(get, "/hardware_vendor/:vendor", s.getHardwareVendor),
+		on(post, "/hardware_vendor/:vendor", s.createHardwareVendor),
+		on(del, "/hardware_vendor/:vendor", s.deleteHardwareVendor),
+
+		on(get, "/validation", s.getValidations),
+		on(get, "/validation/:validation", s.getValidation),
+		on(get, "/validation_plan", s.getValidationPlans),
+		on(get, "/validation_plan/:plan", s.getValidationPlan),
+		on(get, "/validation_plan/:plan/validation", s.getValidationPlanValidations),
+
+		on(get, "/relay", s.getRelays),
+		on(post, "/relay/:relay/register", s.registerRelay),
+	}
+}
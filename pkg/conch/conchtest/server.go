@@ -0,0 +1,355 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package conchtest provides an in-memory fake of the Conch API, for testing
+// code that uses pkg/conch without a real API server or a network.
+//
+// The fake keeps state: a rack created via the API shows up when racks are
+// listed, a device assigned to a slot has a location, and so on. It is not
+// a reimplementation of the API's business rules. Permissions are only
+// checked as far as "is this a valid token" and "is this an admin".
+//
+//	srv := conchtest.NewServer()
+//	defer srv.Close()
+//
+//	api := srv.Client()
+//	roles, err := api.GetRackRoles()
+package conchtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+)
+
+const (
+	// Version is the API version the fake claims to be
+	Version = "v2.31.0"
+
+	// AdminEmail, AdminPassword, and AdminToken are the credentials of the
+	// admin user every Server starts with
+	AdminEmail    = "admin@conch.test"
+	AdminPassword = "conchtest"
+	AdminToken    = "conchtest-admin-token"
+)
+
+// Server is a fake Conch API, listening on a local port
+type Server struct {
+	*httptest.Server
+
+	// Admin is the user that every Server starts with. Client authenticates
+	// as this user.
+	Admin conch.UserDetailed
+
+	// GlobalWorkspace is the root workspace, which contains every rack
+	GlobalWorkspace conch.Workspace
+
+	mu     sync.Mutex
+	routes []route
+
+	users    map[uuid.UUID]*user
+	sessions map[string]session // bearer token => who it belongs to
+
+	workspaces map[uuid.UUID]*workspace
+
+	datacenters map[uuid.UUID]*conch.Datacenter
+	rooms       map[uuid.UUID]*conch.Room
+	rackRoles   map[uuid.UUID]*conch.RackRole
+	racks       map[uuid.UUID]*conch.Rack
+	layouts     map[uuid.UUID]*conch.RackLayoutSlot
+
+	vendors  map[uuid.UUID]*conch.HardwareVendor
+	products map[uuid.UUID]*conch.HardwareProduct
+
+	devices        map[string]*conch.Device
+	deviceSettings map[string]map[string]string
+	assignments    map[uuid.UUID]map[int]string // rack ID => RU => device ID
+
+	validations      map[uuid.UUID]*conch.Validation
+	validationPlans  map[uuid.UUID]*validationPlan
+	validationStates map[string][]conch.ValidationState
+
+	relays       map[string]*conch.WorkspaceRelay
+	relayDevices map[string]map[string]bool
+}
+
+// NewServer starts a fake Conch API with an admin user and a GLOBAL
+// workspace, and nothing else. Use the Add* methods or the API itself to
+// fill it in. Callers should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// NewUnstartedServer returns a fake Conch API that isn't listening yet, so
+// that the listener or TLS configuration can be changed first. Call Start
+// or StartTLS when ready.
+func NewUnstartedServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewUnstartedServer(s)
+	return s
+}
+
+func newServer() *Server {
+	s := &Server{}
+	s.reset()
+	s.routes = s.buildRoutes()
+	return s
+}
+
+// Reset throws away everything the server knows and starts over with just
+// the admin user and the GLOBAL workspace, as if it were new. AdminToken
+// keeps working but any other credentials do not. Admin and GlobalWorkspace
+// are replaced, with new IDs.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+}
+
+func (s *Server) reset() {
+	s.users = make(map[uuid.UUID]*user)
+	s.sessions = make(map[string]session)
+	s.workspaces = make(map[uuid.UUID]*workspace)
+	s.datacenters = make(map[uuid.UUID]*conch.Datacenter)
+	s.rooms = make(map[uuid.UUID]*conch.Room)
+	s.rackRoles = make(map[uuid.UUID]*conch.RackRole)
+	s.racks = make(map[uuid.UUID]*conch.Rack)
+	s.layouts = make(map[uuid.UUID]*conch.RackLayoutSlot)
+	s.vendors = make(map[uuid.UUID]*conch.HardwareVendor)
+	s.products = make(map[uuid.UUID]*conch.HardwareProduct)
+	s.devices = make(map[string]*conch.Device)
+	s.deviceSettings = make(map[string]map[string]string)
+	s.assignments = make(map[uuid.UUID]map[int]string)
+	s.validations = make(map[uuid.UUID]*conch.Validation)
+	s.validationPlans = make(map[uuid.UUID]*validationPlan)
+	s.validationStates = make(map[string][]conch.ValidationState)
+	s.relays = make(map[string]*conch.WorkspaceRelay)
+	s.relayDevices = make(map[string]map[string]bool)
+
+	s.Admin = s.addUser(AdminEmail, AdminPassword, "Admin", true)
+	s.addToken(s.Admin.ID, "conchtest", AdminToken)
+
+	global := &workspace{
+		Workspace: conch.Workspace{
+			ID:          uuid.NewV4(),
+			Name:        "GLOBAL",
+			Description: "Global workspace. Ancestor of all workspaces.",
+		},
+		racks: make(map[uuid.UUID]bool),
+		users: map[uuid.UUID]string{s.Admin.ID: "admin"},
+	}
+	s.workspaces[global.ID] = global
+	s.GlobalWorkspace = global.Workspace
+}
+
+// Client returns a Conch client for this server, authenticated with
+// AdminToken. Options are applied after the defaults so they can override
+// them.
+func (s *Server) Client(opts ...conch.Option) *conch.Conch {
+	return conch.New(append([]conch.Option{
+		conch.WithBaseURL(s.URL),
+		conch.WithHTTPClient(s.Server.Client()),
+		conch.WithToken(AdminToken),
+		conch.WithRetryPolicy(conch.NoRetries),
+	}, opts...)...)
+}
+
+/***/
+
+type handler func(*request)
+
+type route struct {
+	method   string
+	segments []string
+	public   bool
+	handler  handler
+}
+
+// request bundles everything a handler needs
+type request struct {
+	w      http.ResponseWriter
+	r      *http.Request
+	params []string
+	user   *user
+}
+
+// on registers a handler for a route like "/device/:id/phase". Anything
+// starting with a ':' matches a single path segment, handed to the handler
+// in order.
+func on(method string, path string, h handler) route {
+	return route{
+		method:   method,
+		segments: strings.Split(strings.Trim(path, "/"), "/"),
+		handler:  h,
+	}
+}
+
+// public marks a route as not requiring authentication
+func public(r route) route {
+	r.public = true
+	return r
+}
+
+func (rt route) match(method string, segments []string) ([]string, bool) {
+	if (rt.method != method) || (len(rt.segments) != len(segments)) {
+		return nil, false
+	}
+
+	params := make([]string, 0)
+	for i, seg := range rt.segments {
+		if strings.HasPrefix(seg, ":") {
+			params = append(params, segments[i])
+		} else if seg != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// ServeHTTP routes a request to its handler. Requests are handled one at a
+// time.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	segments := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	for i, seg := range segments {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			segments[i] = unescaped
+		}
+	}
+
+	pathMatched := false
+	for _, rt := range s.routes {
+		params, ok := rt.match(r.Method, segments)
+		if !ok {
+			if _, ok := rt.match(rt.method, segments); ok {
+				pathMatched = true
+			}
+			continue
+		}
+
+		req := &request{w: w, r: r, params: params}
+		if !rt.public {
+			req.user = s.authenticate(r)
+			if req.user == nil {
+				req.error(http.StatusUnauthorized, "unauthorized")
+				return
+			}
+		}
+
+		rt.handler(req)
+		return
+	}
+
+	if pathMatched {
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	writeError(w, http.StatusNotFound, "Not Found")
+}
+
+func (s *Server) authenticate(r *http.Request) *user {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return nil
+	}
+
+	sess, ok := s.sessions[strings.TrimPrefix(auth, "Bearer ")]
+	if !ok {
+		return nil
+	}
+	return s.users[sess.user]
+}
+
+/***/
+
+func (req *request) json(v interface{}) {
+	writeJSON(req.w, http.StatusOK, v)
+}
+
+func (req *request) created(v interface{}) {
+	writeJSON(req.w, http.StatusCreated, v)
+}
+
+func (req *request) noContent() {
+	req.w.WriteHeader(http.StatusNoContent)
+}
+
+func (req *request) error(status int, msg string) {
+	writeError(req.w, status, msg)
+}
+
+func (req *request) notFound() {
+	req.error(http.StatusNotFound, "Not Found")
+}
+
+func (req *request) forbidden() {
+	req.error(http.StatusForbidden, "Forbidden")
+}
+
+// decode reads the request body into v, answering with a 400 and returning
+// false if that fails
+func (req *request) decode(v interface{}) bool {
+	body, err := readBody(req)
+	if err != nil {
+		return false
+	}
+	if len(body) == 0 {
+		return true
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		req.error(http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// readBody reads the whole request body, answering with a 400 if that fails
+func readBody(req *request) ([]byte, error) {
+	body, err := ioutil.ReadAll(req.r.Body)
+	if err != nil {
+		req.error(http.StatusBadRequest, err.Error())
+	}
+	return body, err
+}
+
+func (req *request) query(key string) string {
+	return req.r.URL.Query().Get(key)
+}
+
+func (req *request) requireAdmin() bool {
+	if !req.user.IsAdmin {
+		req.forbidden()
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// parseID parses a UUID from a path parameter
+func parseID(s string) (uuid.UUID, bool) {
+	id, err := uuid.FromString(s)
+	return id, err == nil
+}
@@ -0,0 +1,656 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+)
+
+type user struct {
+	conch.UserDetailed
+	password string
+	settings map[string]interface{}
+	tokens   map[string]*conch.UserToken // by name
+}
+
+// session is what a bearer token unlocks. API tokens have a name, login
+// sessions don't.
+type session struct {
+	user uuid.UUID
+	name string
+}
+
+type workspace struct {
+	conch.Workspace
+	racks map[uuid.UUID]bool
+	users map[uuid.UUID]string // user ID => role
+}
+
+type validationPlan struct {
+	conch.ValidationPlan
+	validations []uuid.UUID
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+/***/
+
+// AddUser creates a user that can log in with the given email and password
+func (s *Server) AddUser(email string, password string, name string, admin bool) conch.UserDetailed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addUser(email, password, name, admin)
+}
+
+func (s *Server) addUser(email string, password string, name string, admin bool) conch.UserDetailed {
+	u := &user{
+		UserDetailed: conch.UserDetailed{
+			ID:      uuid.NewV4(),
+			Email:   email,
+			Name:    name,
+			Created: now(),
+			IsAdmin: admin,
+		},
+		password: password,
+		settings: make(map[string]interface{}),
+		tokens:   make(map[string]*conch.UserToken),
+	}
+	s.users[u.ID] = u
+	return u.UserDetailed
+}
+
+// AddToken creates an API token for a user and returns the secret a client
+// would authenticate with
+func (s *Server) AddToken(userID uuid.UUID, name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return "", fmt.Errorf("no such user %s", userID)
+	}
+	if _, ok := u.tokens[name]; ok {
+		return "", fmt.Errorf("user %s already has a token named %s", userID, name)
+	}
+
+	secret := randomHex(16)
+	s.addToken(userID, name, secret)
+	return secret, nil
+}
+
+func (s *Server) addToken(userID uuid.UUID, name string, secret string) conch.UserToken {
+	t := &conch.UserToken{
+		Name:    name,
+		Created: now(),
+		Expires: now().AddDate(5, 0, 0),
+	}
+	s.users[userID].tokens[name] = t
+	s.sessions[secret] = session{user: userID, name: name}
+	return *t
+}
+
+// revoke drops a user's login sessions, API tokens, or both
+func (s *Server) revoke(userID uuid.UUID, logins bool, tokens bool) {
+	for secret, sess := range s.sessions {
+		if sess.user != userID {
+			continue
+		}
+		if (sess.name == "" && logins) || (sess.name != "" && tokens) {
+			delete(s.sessions, secret)
+		}
+	}
+	if tokens {
+		s.users[userID].tokens = make(map[string]*conch.UserToken)
+	}
+}
+
+func (s *Server) deleteToken(userID uuid.UUID, name string) bool {
+	u := s.users[userID]
+	if _, ok := u.tokens[name]; !ok {
+		return false
+	}
+	delete(u.tokens, name)
+	for secret, sess := range s.sessions {
+		if (sess.user == userID) && (sess.name == name) {
+			delete(s.sessions, secret)
+		}
+	}
+	return true
+}
+
+func (s *Server) userByEmail(email string) *user {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Email, email) {
+			return u
+		}
+	}
+	return nil
+}
+
+/***/
+
+// AddWorkspace creates a workspace under the given parent. Use
+// GlobalWorkspace.ID for a top level workspace.
+func (s *Server) AddWorkspace(parentID uuid.UUID, name string, description string) (conch.Workspace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addWorkspace(parentID, name, description)
+}
+
+func (s *Server) addWorkspace(parentID uuid.UUID, name string, description string) (conch.Workspace, error) {
+	if _, ok := s.workspaces[parentID]; !ok {
+		return conch.Workspace{}, fmt.Errorf("no such workspace %s", parentID)
+	}
+	if s.workspaceByName(name) != nil {
+		return conch.Workspace{}, fmt.Errorf("a workspace named %s already exists", name)
+	}
+
+	ws := &workspace{
+		Workspace: conch.Workspace{
+			ID:          uuid.NewV4(),
+			Name:        name,
+			Description: description,
+			ParentID:    parentID,
+		},
+		racks: make(map[uuid.UUID]bool),
+		users: make(map[uuid.UUID]string),
+	}
+	s.workspaces[ws.ID] = ws
+	return ws.Workspace, nil
+}
+
+// AddWorkspaceRack adds a rack to a workspace. Every rack is always part of
+// the GLOBAL workspace.
+func (s *Server) AddWorkspaceRack(workspaceID uuid.UUID, rackID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ws, ok := s.workspaces[workspaceID]
+	if !ok {
+		return fmt.Errorf("no such workspace %s", workspaceID)
+	}
+	if _, ok := s.racks[rackID]; !ok {
+		return fmt.Errorf("no such rack %s", rackID)
+	}
+	ws.racks[rackID] = true
+	return nil
+}
+
+// AddWorkspaceUser grants a user a role ("ro", "rw", or "admin") on a
+// workspace and, by extension, everything below it
+func (s *Server) AddWorkspaceUser(workspaceID uuid.UUID, userID uuid.UUID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ws, ok := s.workspaces[workspaceID]
+	if !ok {
+		return fmt.Errorf("no such workspace %s", workspaceID)
+	}
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("no such user %s", userID)
+	}
+	ws.users[userID] = role
+	return nil
+}
+
+func (s *Server) workspaceByName(name string) *workspace {
+	for _, ws := range s.workspaces {
+		if ws.Name == name {
+			return ws
+		}
+	}
+	return nil
+}
+
+// grantedRole finds the role a user was given on a workspace, either directly
+// or on one of its ancestors, and the workspace it was given on
+func (s *Server) grantedRole(u *user, ws *workspace) (string, uuid.UUID) {
+	for w := ws; w != nil; w = s.workspaces[w.ParentID] {
+		if role, ok := w.users[u.ID]; ok {
+			return role, w.ID
+		}
+	}
+	return "", uuid.UUID{}
+}
+
+// workspaceRole is grantedRole, except that system admins are admins
+// everywhere
+func (s *Server) workspaceRole(u *user, ws *workspace) (string, uuid.UUID) {
+	if role, via := s.grantedRole(u, ws); (role == "admin") || !u.IsAdmin {
+		return role, via
+	}
+	return "admin", s.GlobalWorkspace.ID
+}
+
+// workspaceHasRack reports if a rack belongs to a workspace or any of its
+// descendants
+func (s *Server) workspaceHasRack(ws *workspace, rackID uuid.UUID) bool {
+	if ws.ID == s.GlobalWorkspace.ID {
+		_, ok := s.racks[rackID]
+		return ok
+	}
+	if ws.racks[rackID] {
+		return true
+	}
+	for _, child := range s.workspaces {
+		if (child.ParentID == ws.ID) && s.workspaceHasRack(child, rackID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) workspaceRackIDs(ws *workspace) []uuid.UUID {
+	ids := make([]uuid.UUID, 0)
+	for id := range s.racks {
+		if s.workspaceHasRack(ws, id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return s.racks[ids[i]].Name < s.racks[ids[j]].Name
+	})
+	return ids
+}
+
+/***/
+
+// AddDatacenter stores a datacenter, assigning it an ID if it doesn't
+// have one
+func (s *Server) AddDatacenter(d conch.Datacenter) conch.Datacenter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addDatacenter(d)
+}
+
+func (s *Server) addDatacenter(d conch.Datacenter) conch.Datacenter {
+	if d.ID.IsZero() {
+		d.ID = uuid.NewV4()
+	}
+	if d.Created.IsZero() {
+		d.Created = now()
+	}
+	d.Updated = now()
+	s.datacenters[d.ID] = &d
+	return d
+}
+
+// AddRoom stores a datacenter room, assigning it an ID if it doesn't
+// have one
+func (s *Server) AddRoom(r conch.Room) conch.Room {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addRoom(r)
+}
+
+func (s *Server) addRoom(r conch.Room) conch.Room {
+	if r.ID.IsZero() {
+		r.ID = uuid.NewV4()
+	}
+	if r.Created.IsZero() {
+		r.Created = now()
+	}
+	r.Updated = now()
+	s.rooms[r.ID] = &r
+	return r
+}
+
+// AddRackRole stores a rack role, assigning it an ID if it doesn't have one
+func (s *Server) AddRackRole(r conch.RackRole) conch.RackRole {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addRackRole(r)
+}
+
+func (s *Server) addRackRole(r conch.RackRole) conch.RackRole {
+	if r.ID.IsZero() {
+		r.ID = uuid.NewV4()
+	}
+	if r.Created.IsZero() {
+		r.Created = now()
+	}
+	r.Updated = now()
+	s.rackRoles[r.ID] = &r
+	return r
+}
+
+// AddRack stores a rack, assigning it an ID if it doesn't have one
+func (s *Server) AddRack(r conch.Rack) conch.Rack {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addRack(r)
+}
+
+func (s *Server) addRack(r conch.Rack) conch.Rack {
+	if r.ID.IsZero() {
+		r.ID = uuid.NewV4()
+	}
+	if r.Created.IsZero() {
+		r.Created = now()
+	}
+	r.Updated = now()
+	if r.Phase == "" {
+		r.Phase = "integration"
+	}
+	s.racks[r.ID] = &r
+	return r
+}
+
+// AddLayoutSlot stores a rack layout slot, assigning it an ID if it doesn't
+// have one
+func (s *Server) AddLayoutSlot(l conch.RackLayoutSlot) conch.RackLayoutSlot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addLayoutSlot(l)
+}
+
+func (s *Server) addLayoutSlot(l conch.RackLayoutSlot) conch.RackLayoutSlot {
+	if l.ID.IsZero() {
+		l.ID = uuid.NewV4()
+	}
+	if l.Created.IsZero() {
+		l.Created = now()
+	}
+	l.Updated = now()
+	s.layouts[l.ID] = &l
+	return l
+}
+
+func (s *Server) rackLayout(rackID uuid.UUID) []conch.RackLayoutSlot {
+	slots := make([]conch.RackLayoutSlot, 0)
+	for _, l := range s.layouts {
+		if l.RackID == rackID {
+			slots = append(slots, *l)
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].RUStart < slots[j].RUStart })
+	return slots
+}
+
+func (s *Server) layoutAt(rackID uuid.UUID, ru int) *conch.RackLayoutSlot {
+	for _, l := range s.layouts {
+		if (l.RackID == rackID) && (l.RUStart == ru) {
+			return l
+		}
+	}
+	return nil
+}
+
+/***/
+
+// AddHardwareVendor stores a hardware vendor with the given name
+func (s *Server) AddHardwareVendor(name string) conch.HardwareVendor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addHardwareVendor(conch.HardwareVendor{Name: name})
+}
+
+func (s *Server) addHardwareVendor(v conch.HardwareVendor) conch.HardwareVendor {
+	if v.ID.IsZero() {
+		v.ID = uuid.NewV4()
+	}
+	if v.Created.IsZero() {
+		v.Created = now()
+	}
+	v.Updated = now()
+	s.vendors[v.ID] = &v
+	return v
+}
+
+// AddHardwareProduct stores a hardware product, assigning it an ID if it
+// doesn't have one
+func (s *Server) AddHardwareProduct(p conch.HardwareProduct) conch.HardwareProduct {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addHardwareProduct(p)
+}
+
+func (s *Server) addHardwareProduct(p conch.HardwareProduct) conch.HardwareProduct {
+	if p.ID.IsZero() {
+		p.ID = uuid.NewV4()
+	}
+	if p.Created.IsZero() {
+		p.Created = now()
+	}
+	p.Updated = now()
+	if p.Specification == nil {
+		p.Specification = make(map[string]interface{})
+	}
+	s.products[p.ID] = &p
+	return p
+}
+
+func (s *Server) vendorByName(name string) *conch.HardwareVendor {
+	for _, v := range s.vendors {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+/***/
+
+// AddDevice stores a device. Unlike most things, devices bring their own ID,
+// their serial number.
+func (s *Server) AddDevice(d conch.Device) conch.Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addDevice(d)
+}
+
+func (s *Server) addDevice(d conch.Device) conch.Device {
+	if d.Created.IsZero() {
+		d.Created = now()
+	}
+	d.Updated = now()
+	if d.Phase == "" {
+		d.Phase = "integration"
+	}
+	if d.Health == "" {
+		d.Health = "unknown"
+	}
+	s.devices[d.ID] = &d
+	if _, ok := s.deviceSettings[d.ID]; !ok {
+		s.deviceSettings[d.ID] = make(map[string]string)
+	}
+	return d
+}
+
+// AssignDevice puts a device into the layout slot starting at the given rack
+// unit, taking it out of wherever it was before
+func (s *Server) AssignDevice(deviceID string, rackID uuid.UUID, ru int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.assignDevice(deviceID, rackID, ru)
+}
+
+func (s *Server) assignDevice(deviceID string, rackID uuid.UUID, ru int) error {
+	if _, ok := s.racks[rackID]; !ok {
+		return fmt.Errorf("no such rack %s", rackID)
+	}
+	if s.layoutAt(rackID, ru) == nil {
+		return fmt.Errorf("rack %s has no slot at rack unit %d", rackID, ru)
+	}
+	if _, ok := s.devices[deviceID]; !ok {
+		return fmt.Errorf("no such device %s", deviceID)
+	}
+
+	s.unassignDevice(deviceID)
+	if s.assignments[rackID] == nil {
+		s.assignments[rackID] = make(map[int]string)
+	}
+	s.assignments[rackID][ru] = deviceID
+	return nil
+}
+
+func (s *Server) unassignDevice(deviceID string) {
+	for _, slots := range s.assignments {
+		for ru, id := range slots {
+			if id == deviceID {
+				delete(slots, ru)
+			}
+		}
+	}
+}
+
+// deviceSlot finds where a device lives, if anywhere
+func (s *Server) deviceSlot(deviceID string) (uuid.UUID, int, bool) {
+	for rackID, slots := range s.assignments {
+		for ru, id := range slots {
+			if id == deviceID {
+				return rackID, ru, true
+			}
+		}
+	}
+	return uuid.UUID{}, 0, false
+}
+
+// SetDeviceSetting stores a setting, or a tag if key starts with "tag.", on a
+// device
+func (s *Server) SetDeviceSetting(deviceID string, key string, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, ok := s.deviceSettings[deviceID]
+	if !ok {
+		return fmt.Errorf("no such device %s", deviceID)
+	}
+	settings[key] = value
+	return nil
+}
+
+/***/
+
+// AddValidation stores a validation, assigning it an ID if it doesn't
+// have one
+func (s *Server) AddValidation(v conch.Validation) conch.Validation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addValidation(v)
+}
+
+func (s *Server) addValidation(v conch.Validation) conch.Validation {
+	if v.ID.IsZero() {
+		v.ID = uuid.NewV4()
+	}
+	if v.Created.IsZero() {
+		v.Created = now()
+	}
+	v.Updated = now()
+	s.validations[v.ID] = &v
+	return v
+}
+
+// AddValidationPlan stores a validation plan made up of the given
+// validations, assigning it an ID if it doesn't have one
+func (s *Server) AddValidationPlan(p conch.ValidationPlan, validations ...uuid.UUID) conch.ValidationPlan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addValidationPlan(p, validations...)
+}
+
+func (s *Server) addValidationPlan(p conch.ValidationPlan, validations ...uuid.UUID) conch.ValidationPlan {
+	if p.ID.IsZero() {
+		p.ID = uuid.NewV4()
+	}
+	if p.Created.IsZero() {
+		p.Created = now()
+	}
+	s.validationPlans[p.ID] = &validationPlan{
+		ValidationPlan: p,
+		validations:    validations,
+	}
+	return p
+}
+
+// AddValidationState records the outcome of running a validation plan
+// against a device
+func (s *Server) AddValidationState(v conch.ValidationState) conch.ValidationState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addValidationState(v)
+}
+
+func (s *Server) addValidationState(v conch.ValidationState) conch.ValidationState {
+	v.ID = uuid.NewV4()
+	if v.Created.IsZero() {
+		v.Created = now()
+	}
+	if v.Completed.IsZero() {
+		v.Completed = v.Created
+	}
+	if v.Status == "" {
+		v.Status = "pass"
+		for _, r := range v.Results {
+			if r.Status != "pass" {
+				v.Status = r.Status
+				break
+			}
+		}
+	}
+	s.validationStates[v.DeviceID] = append(s.validationStates[v.DeviceID], v)
+	return v
+}
+
+/***/
+
+// AddRelay stores a relay. Like devices, relays bring their own ID.
+func (s *Server) AddRelay(r conch.WorkspaceRelay) conch.WorkspaceRelay {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addRelay(r)
+}
+
+func (s *Server) addRelay(r conch.WorkspaceRelay) conch.WorkspaceRelay {
+	if existing, ok := s.relays[r.ID]; ok {
+		r.Created = existing.Created
+	} else {
+		r.Created = now()
+	}
+	r.Updated = now()
+	r.LastSeen = r.Updated
+	s.relays[r.ID] = &r
+	if _, ok := s.relayDevices[r.ID]; !ok {
+		s.relayDevices[r.ID] = make(map[string]bool)
+	}
+	return r
+}
+
+// LinkRelayDevice records that a device reported in through a relay
+func (s *Server) LinkRelayDevice(relayID string, deviceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices, ok := s.relayDevices[relayID]
+	if !ok {
+		return fmt.Errorf("no such relay %s", relayID)
+	}
+	if _, ok := s.devices[deviceID]; !ok {
+		return fmt.Errorf("no such device %s", deviceID)
+	}
+	devices[deviceID] = true
+	return nil
+}
@@ -0,0 +1,428 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+)
+
+// JWTLifetime is how long the login tokens handed out by the fake are good
+// for
+const JWTLifetime = 24 * time.Hour
+
+func (s *Server) getVersion(req *request) {
+	req.json(map[string]string{"version": Version})
+}
+
+func (s *Server) login(req *request) {
+	creds := struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}{}
+	if !req.decode(&creds) {
+		return
+	}
+
+	u := s.lookupUser(nil, creds.User)
+	if (u == nil) || (u.password != creds.Password) {
+		req.error(http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	u.LastLogin = now()
+
+	if u.ForcePasswordChange {
+		req.w.Header().Set("Location", "/user/me/password")
+	}
+	s.issueJWT(req, u)
+}
+
+func (s *Server) refreshToken(req *request) {
+	// Refreshing a login session replaces it. API tokens stay valid.
+	secret := strings.TrimPrefix(req.r.Header.Get("Authorization"), "Bearer ")
+	if sess := s.sessions[secret]; sess.name == "" {
+		delete(s.sessions, secret)
+	}
+	s.issueJWT(req, req.user)
+}
+
+// issueJWT starts a login session, answering the way the real API does: the
+// header and claims in the body and the signature in a cookie
+func (s *Server) issueJWT(req *request, u *user) {
+	seg := func(v interface{}) string {
+		j, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(j)
+	}
+
+	token := seg(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." +
+		seg(map[string]interface{}{
+			"user_id": u.ID.String(),
+			"jti":     randomHex(8),
+			"exp":     now().Add(JWTLifetime).Unix(),
+		})
+	signature := randomHex(16)
+
+	s.sessions[token+"."+signature] = session{user: u.ID}
+
+	http.SetCookie(req.w, &http.Cookie{
+		Name:     "jwt_sig",
+		Value:    signature,
+		Path:     "/",
+		HttpOnly: true,
+	})
+	req.json(map[string]string{"jwt_token": token})
+}
+
+/***/
+
+// lookupUser finds a user by "me", "email=foo@example.com", or ID
+func (s *Server) lookupUser(me *user, key string) *user {
+	if key == "me" {
+		return me
+	}
+	if strings.HasPrefix(key, "email=") {
+		return s.userByEmail(strings.TrimPrefix(key, "email="))
+	}
+	if id, ok := parseID(key); ok {
+		return s.users[id]
+	}
+	return s.userByEmail(key)
+}
+
+// targetUser finds the user named in the first path parameter. Only admins
+// get to look at anyone but themselves.
+func (s *Server) targetUser(req *request) *user {
+	u := s.lookupUser(req.user, req.params[0])
+	if u == nil {
+		req.notFound()
+		return nil
+	}
+	if (u.ID != req.user.ID) && !req.user.IsAdmin {
+		req.forbidden()
+		return nil
+	}
+	return u
+}
+
+func (s *Server) userWorkspaces(u *user) conch.WorkspacesAndRoles {
+	ret := make(conch.WorkspacesAndRoles, 0)
+	for _, ws := range s.workspaces {
+		role, via := s.workspaceRole(u, ws)
+		if role == "" {
+			continue
+		}
+		w := ws.Workspace
+		w.Role = role
+		ret = append(ret, conch.WorkspaceAndRole{Workspace: w, RoleVia: via})
+	}
+	sort.Sort(ret)
+	return ret
+}
+
+func (s *Server) detailedUser(u *user) conch.UserDetailed {
+	d := u.UserDetailed
+	d.Workspaces = s.userWorkspaces(u)
+	return d
+}
+
+func (s *Server) getMe(req *request) {
+	u := req.user
+	req.json(conch.UserProfile{
+		Created:             u.Created,
+		Email:               u.Email,
+		ForcePasswordChange: u.ForcePasswordChange,
+		ID:                  u.ID,
+		LastLogin:           u.LastLogin,
+		Name:                u.Name,
+		RefuseSessionAuth:   u.RefuseSessionAuth,
+		Workspaces:          s.userWorkspaces(u),
+	})
+}
+
+func (s *Server) getMySettings(req *request) {
+	req.json(req.user.settings)
+}
+
+func (s *Server) setMySettings(req *request) {
+	settings := make(map[string]interface{})
+	if !req.decode(&settings) {
+		return
+	}
+	req.user.settings = settings
+	req.noContent()
+}
+
+func (s *Server) getMySetting(req *request) {
+	key := req.params[0]
+	v, ok := req.user.settings[key]
+	if !ok {
+		req.notFound()
+		return
+	}
+	req.json(map[string]interface{}{key: v})
+}
+
+func (s *Server) setMySetting(req *request) {
+	key := req.params[0]
+
+	var v interface{}
+	if !req.decode(&v) {
+		return
+	}
+	// The API wants { key: value } but take a bare value too
+	if m, ok := v.(map[string]interface{}); ok && (len(m) == 1) {
+		if inner, ok := m[key]; ok {
+			v = inner
+		}
+	}
+	req.user.settings[key] = v
+	req.noContent()
+}
+
+func (s *Server) deleteMySetting(req *request) {
+	if _, ok := req.user.settings[req.params[0]]; !ok {
+		req.notFound()
+		return
+	}
+	delete(req.user.settings, req.params[0])
+	req.noContent()
+}
+
+func (s *Server) createMyToken(req *request) {
+	in := conch.CreateNewUserToken{}
+	if !req.decode(&in) {
+		return
+	}
+	if in.Name == "" {
+		req.error(http.StatusBadRequest, "name is required")
+		return
+	}
+	if _, ok := req.user.tokens[in.Name]; ok {
+		req.error(http.StatusConflict, "name \""+in.Name+"\" is already in use")
+		return
+	}
+
+	secret := randomHex(16)
+	t := s.addToken(req.user.ID, in.Name, secret)
+	req.created(conch.NewUserToken{UserToken: t, Token: secret})
+}
+
+func (s *Server) changeMyPassword(req *request) {
+	in := struct {
+		Password string `json:"password"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if in.Password == "" {
+		req.error(http.StatusBadRequest, "password is required")
+		return
+	}
+
+	req.user.password = in.Password
+	req.user.ForcePasswordChange = false
+	s.clearTokens(req.user, req.query("clear_tokens"))
+	req.noContent()
+}
+
+// clearTokens applies the clear_tokens query parameter used by the password
+// endpoints. The default is to log the user out everywhere.
+func (s *Server) clearTokens(u *user, which string) {
+	switch which {
+	case "none":
+	case "all":
+		s.revoke(u.ID, true, true)
+	default:
+		s.revoke(u.ID, true, false)
+	}
+}
+
+/***/
+
+func (s *Server) getUsers(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	users := make(conch.UsersDetailed, 0)
+	for _, u := range s.users {
+		users = append(users, s.detailedUser(u))
+	}
+	sort.Sort(users)
+	req.json(users)
+}
+
+func (s *Server) createUser(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	in := struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Name     string `json:"name"`
+		IsAdmin  bool   `json:"is_admin"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if in.Email == "" {
+		req.error(http.StatusBadRequest, "email is required")
+		return
+	}
+	if s.userByEmail(in.Email) != nil {
+		req.error(http.StatusConflict, "duplicate user found")
+		return
+	}
+	if in.Name == "" {
+		in.Name = in.Email
+	}
+	if in.Password == "" {
+		in.Password = randomHex(8)
+	}
+
+	u := s.addUser(in.Email, in.Password, in.Name, in.IsAdmin)
+	req.created(map[string]string{
+		"id":    u.ID.String(),
+		"email": u.Email,
+		"name":  u.Name,
+	})
+}
+
+func (s *Server) getUser(req *request) {
+	if u := s.targetUser(req); u != nil {
+		req.json(s.detailedUser(u))
+	}
+}
+
+func (s *Server) updateUser(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	u := s.targetUser(req)
+	if u == nil {
+		return
+	}
+
+	in := struct {
+		Email   *string `json:"email"`
+		Name    *string `json:"name"`
+		IsAdmin *bool   `json:"is_admin"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if in.Email != nil {
+		if other := s.userByEmail(*in.Email); (other != nil) && (other.ID != u.ID) {
+			req.error(http.StatusConflict, "duplicate user found")
+			return
+		}
+		u.Email = *in.Email
+	}
+	if in.Name != nil {
+		u.Name = *in.Name
+	}
+	if in.IsAdmin != nil {
+		u.IsAdmin = *in.IsAdmin
+	}
+	req.json(s.detailedUser(u))
+}
+
+func (s *Server) deleteUser(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	u := s.targetUser(req)
+	if u == nil {
+		return
+	}
+	if u.ID == req.user.ID {
+		req.error(http.StatusConflict, "can't deactivate yourself")
+		return
+	}
+
+	s.revoke(u.ID, true, true)
+	for _, ws := range s.workspaces {
+		delete(ws.users, u.ID)
+	}
+	delete(s.users, u.ID)
+	req.noContent()
+}
+
+func (s *Server) revokeUser(req *request) {
+	u := s.targetUser(req)
+	if u == nil {
+		return
+	}
+
+	logins, tokens := true, true
+	if req.query("auth_only") != "" {
+		tokens = false
+	}
+	if req.query("api_only") != "" {
+		logins = false
+	}
+	s.revoke(u.ID, logins, tokens)
+	req.noContent()
+}
+
+func (s *Server) resetUserPassword(req *request) {
+	if !req.requireAdmin() {
+		return
+	}
+	u := s.targetUser(req)
+	if u == nil {
+		return
+	}
+
+	u.password = randomHex(8)
+	u.ForcePasswordChange = true
+	s.clearTokens(u, req.query("clear_tokens"))
+	req.noContent()
+}
+
+func (s *Server) getUserTokens(req *request) {
+	u := s.targetUser(req)
+	if u == nil {
+		return
+	}
+	tokens := make(conch.UserTokens, 0)
+	for _, t := range u.tokens {
+		tokens = append(tokens, *t)
+	}
+	sort.Sort(tokens)
+	req.json(tokens)
+}
+
+func (s *Server) getUserToken(req *request) {
+	u := s.targetUser(req)
+	if u == nil {
+		return
+	}
+	t, ok := u.tokens[req.params[1]]
+	if !ok {
+		req.notFound()
+		return
+	}
+	req.json(t)
+}
+
+func (s *Server) deleteUserToken(req *request) {
+	u := s.targetUser(req)
+	if u == nil {
+		return
+	}
+	if !s.deleteToken(u.ID, req.params[1]) {
+		req.notFound()
+		return
+	}
+	req.noContent()
+}
@@ -0,0 +1,77 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"sort"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+)
+
+func (s *Server) getValidations(req *request) {
+	ret := make(conch.Validations, 0)
+	for _, v := range s.validations {
+		ret = append(ret, *v)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	req.json(ret)
+}
+
+func (s *Server) getValidation(req *request) {
+	id, ok := targetID(req)
+	if !ok {
+		return
+	}
+	v, ok := s.validations[id]
+	if !ok {
+		req.notFound()
+		return
+	}
+	req.json(v)
+}
+
+func (s *Server) targetValidationPlan(req *request) *validationPlan {
+	id, ok := targetID(req)
+	if !ok {
+		return nil
+	}
+	p, ok := s.validationPlans[id]
+	if !ok {
+		req.notFound()
+		return nil
+	}
+	return p
+}
+
+func (s *Server) getValidationPlans(req *request) {
+	ret := make([]conch.ValidationPlan, 0)
+	for _, p := range s.validationPlans {
+		ret = append(ret, p.ValidationPlan)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	req.json(ret)
+}
+
+func (s *Server) getValidationPlan(req *request) {
+	if p := s.targetValidationPlan(req); p != nil {
+		req.json(p.ValidationPlan)
+	}
+}
+
+func (s *Server) getValidationPlanValidations(req *request) {
+	p := s.targetValidationPlan(req)
+	if p == nil {
+		return
+	}
+	ret := make(conch.Validations, 0)
+	for _, id := range p.validations {
+		if v, ok := s.validations[id]; ok {
+			ret = append(ret, *v)
+		}
+	}
+	req.json(ret)
+}
@@ -0,0 +1,455 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conchtest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+)
+
+var roleRank = map[string]int{"ro": 1, "rw": 2, "admin": 3}
+
+// targetWorkspace finds the workspace, by ID or name, named in the first path
+// parameter and checks that the user holds at least the given role on it
+func (s *Server) targetWorkspace(req *request, minRole string) *workspace {
+	var ws *workspace
+	if id, ok := parseID(req.params[0]); ok {
+		ws = s.workspaces[id]
+	} else {
+		ws = s.workspaceByName(req.params[0])
+	}
+	if ws == nil {
+		req.notFound()
+		return nil
+	}
+
+	role, _ := s.workspaceRole(req.user, ws)
+	if role == "" {
+		req.notFound()
+		return nil
+	}
+	if roleRank[role] < roleRank[minRole] {
+		req.forbidden()
+		return nil
+	}
+	return ws
+}
+
+func (s *Server) withRole(u *user, ws *workspace) conch.Workspace {
+	w := ws.Workspace
+	w.Role, _ = s.workspaceRole(u, ws)
+	return w
+}
+
+func (s *Server) getWorkspaces(req *request) {
+	ret := make(conch.Workspaces, 0)
+	for _, ws := range s.userWorkspaces(req.user) {
+		ret = append(ret, ws.Workspace)
+	}
+	req.json(ret)
+}
+
+func (s *Server) getWorkspace(req *request) {
+	if ws := s.targetWorkspace(req, "ro"); ws != nil {
+		req.json(s.withRole(req.user, ws))
+	}
+}
+
+func (s *Server) getChildWorkspaces(req *request) {
+	ws := s.targetWorkspace(req, "ro")
+	if ws == nil {
+		return
+	}
+
+	ret := make(conch.Workspaces, 0)
+	var walk func(parent uuid.UUID)
+	walk = func(parent uuid.UUID) {
+		for _, child := range s.workspaces {
+			if (child.ParentID == parent) && (child.ID != parent) {
+				ret = append(ret, s.withRole(req.user, child))
+				walk(child.ID)
+			}
+		}
+	}
+	walk(ws.ID)
+
+	sort.Sort(ret)
+	req.json(ret)
+}
+
+func (s *Server) createChildWorkspace(req *request) {
+	parent := s.targetWorkspace(req, "rw")
+	if parent == nil {
+		return
+	}
+	in := struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if in.Name == "" {
+		req.error(http.StatusBadRequest, "name is required")
+		return
+	}
+
+	w, err := s.addWorkspace(parent.ID, in.Name, in.Description)
+	if err != nil {
+		req.error(http.StatusConflict, err.Error())
+		return
+	}
+	req.created(s.withRole(req.user, s.workspaces[w.ID]))
+}
+
+/***/
+
+func (s *Server) workspaceRack(rack *conch.Rack) conch.WorkspaceRack {
+	wr := conch.WorkspaceRack{
+		ID:           rack.ID,
+		Name:         rack.Name,
+		SerialNumber: rack.SerialNumber,
+		AssetTag:     rack.AssetTag,
+		Phase:        rack.Phase,
+	}
+	if role, ok := s.rackRoles[rack.RoleID]; ok {
+		wr.Role = role.Name
+		wr.Size = role.RackSize
+	}
+	if room, ok := s.rooms[rack.DatacenterRoomID]; ok {
+		wr.Datacenter = room.AZ
+	}
+	return wr
+}
+
+func (s *Server) getWorkspaceRacks(req *request) {
+	ws := s.targetWorkspace(req, "ro")
+	if ws == nil {
+		return
+	}
+
+	byAZ := make(map[string][]conch.WorkspaceRack)
+	for _, id := range s.workspaceRackIDs(ws) {
+		wr := s.workspaceRack(s.racks[id])
+		byAZ[wr.Datacenter] = append(byAZ[wr.Datacenter], wr)
+	}
+	req.json(byAZ)
+}
+
+// workspaceRackSlot is conch.WorkspaceRackSlot as the API sends it, with a
+// null occupant for empty slots
+type workspaceRackSlot struct {
+	conch.WorkspaceRackSlot
+	Occupant *conch.Device `json:"occupant"`
+}
+
+func (s *Server) getWorkspaceRack(req *request) {
+	ws := s.targetWorkspace(req, "ro")
+	if ws == nil {
+		return
+	}
+	rackID, _ := parseID(req.params[1])
+	if !s.workspaceHasRack(ws, rackID) {
+		req.notFound()
+		return
+	}
+	rack := s.racks[rackID]
+
+	slots := make([]workspaceRackSlot, 0)
+	for _, l := range s.rackLayout(rackID) {
+		slot := workspaceRackSlot{
+			WorkspaceRackSlot: conch.WorkspaceRackSlot{
+				ID:            l.ProductID,
+				RackUnitStart: l.RUStart,
+			},
+		}
+		if p, ok := s.products[l.ProductID]; ok {
+			slot.Name = p.Name
+			slot.Alias = p.Alias
+			slot.Size = p.Profile.RackUnit
+			if v, ok := s.vendors[p.HardwareVendorID]; ok {
+				slot.Vendor = v.Name
+			}
+		}
+		if deviceID, ok := s.assignments[rackID][l.RUStart]; ok {
+			d := s.device(deviceID)
+			slot.Occupant = &d
+		}
+		slots = append(slots, slot)
+	}
+
+	req.json(struct {
+		conch.WorkspaceRack
+		Slots []workspaceRackSlot `json:"slots"`
+	}{s.workspaceRack(rack), slots})
+}
+
+func (s *Server) addWorkspaceRack(req *request) {
+	ws := s.targetWorkspace(req, "admin")
+	if ws == nil {
+		return
+	}
+	in := struct {
+		ID uuid.UUID `json:"id"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if _, ok := s.racks[in.ID]; !ok {
+		req.notFound()
+		return
+	}
+	if ws.ID == s.GlobalWorkspace.ID {
+		req.error(http.StatusBadRequest, "cannot modify GLOBAL workspace")
+		return
+	}
+	ws.racks[in.ID] = true
+	req.noContent()
+}
+
+func (s *Server) removeWorkspaceRack(req *request) {
+	ws := s.targetWorkspace(req, "admin")
+	if ws == nil {
+		return
+	}
+	rackID, _ := parseID(req.params[1])
+	if !ws.racks[rackID] {
+		req.notFound()
+		return
+	}
+	delete(ws.racks, rackID)
+	req.noContent()
+}
+
+func (s *Server) assignWorkspaceRackLayout(req *request) {
+	ws := s.targetWorkspace(req, "rw")
+	if ws == nil {
+		return
+	}
+	rackID, _ := parseID(req.params[1])
+	if !s.workspaceHasRack(ws, rackID) {
+		req.notFound()
+		return
+	}
+
+	assignments := make(conch.WorkspaceRackLayoutAssignments)
+	if !req.decode(&assignments) {
+		return
+	}
+	updated := make([]string, 0)
+	for deviceID, ru := range assignments {
+		if s.layoutAt(rackID, ru) == nil {
+			req.error(http.StatusConflict, "no slot at rack unit "+strconv.Itoa(ru))
+			return
+		}
+		if _, ok := s.devices[deviceID]; !ok {
+			s.addDevice(conch.Device{ID: deviceID})
+		}
+		_ = s.assignDevice(deviceID, rackID, ru)
+		updated = append(updated, deviceID)
+	}
+	sort.Strings(updated)
+	req.json(map[string][]string{"updated": updated})
+}
+
+/***/
+
+// workspaceDeviceIDs lists the devices in the racks of a workspace
+func (s *Server) workspaceDeviceIDs(ws *workspace) []string {
+	ids := make([]string, 0)
+	for _, rackID := range s.workspaceRackIDs(ws) {
+		for _, id := range s.assignments[rackID] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *Server) getWorkspaceDevices(req *request) {
+	ws := s.targetWorkspace(req, "ro")
+	if ws == nil {
+		return
+	}
+
+	boolFilter := func(param string, t time.Time) bool {
+		switch req.query(param) {
+		case "t", "true", "1":
+			return !t.IsZero()
+		case "f", "false", "0":
+			return t.IsZero()
+		}
+		return true
+	}
+
+	devices := make(conch.Devices, 0)
+	ids := make([]string, 0)
+	for _, id := range s.workspaceDeviceIDs(ws) {
+		d := s.device(id)
+		if !boolFilter("graduated", d.Graduated) || !boolFilter("validated", d.Validated) {
+			continue
+		}
+		if h := req.query("health"); (h != "") && (h != d.Health) {
+			continue
+		}
+		devices = append(devices, d)
+		ids = append(ids, d.ID)
+	}
+
+	if req.query("ids_only") != "" {
+		req.json(ids)
+		return
+	}
+	req.json(devices)
+}
+
+/***/
+
+func (s *Server) getWorkspaceUsers(req *request) {
+	ws := s.targetWorkspace(req, "ro")
+	if ws == nil {
+		return
+	}
+
+	users := make(conch.Users, 0)
+	for _, u := range s.users {
+		role, via := s.grantedRole(u, ws)
+		if role == "" {
+			continue
+		}
+		users = append(users, conch.User{
+			ID:      u.ID.String(),
+			Email:   u.Email,
+			Name:    u.Name,
+			Role:    role,
+			RoleVia: via,
+		})
+	}
+	sort.Sort(users)
+	req.json(users)
+}
+
+func (ws *workspace) hasDirectRole(u *user) bool {
+	_, ok := ws.users[u.ID]
+	return ok
+}
+
+func (s *Server) addWorkspaceUser(req *request) {
+	ws := s.targetWorkspace(req, "admin")
+	if ws == nil {
+		return
+	}
+	in := struct {
+		User string `json:"user"`
+		Role string `json:"role"`
+	}{}
+	if !req.decode(&in) {
+		return
+	}
+	if _, ok := roleRank[in.Role]; !ok {
+		req.error(http.StatusBadRequest, "role must be one of ro, rw, admin")
+		return
+	}
+	u := s.lookupUser(nil, in.User)
+	if u == nil {
+		req.notFound()
+		return
+	}
+	ws.users[u.ID] = in.Role
+	req.noContent()
+}
+
+func (s *Server) removeWorkspaceUser(req *request) {
+	ws := s.targetWorkspace(req, "admin")
+	if ws == nil {
+		return
+	}
+	u := s.lookupUser(req.user, req.params[1])
+	if (u == nil) || !ws.hasDirectRole(u) {
+		req.notFound()
+		return
+	}
+	delete(ws.users, u.ID)
+	req.noContent()
+}
+
+/***/
+
+func (s *Server) getWorkspaceRelays(req *request) {
+	ws := s.targetWorkspace(req, "ro")
+	if ws == nil {
+		return
+	}
+
+	var cutoff time.Time
+	if within, err := strconv.Atoi(req.query("active_within")); err == nil {
+		cutoff = now().Add(-time.Duration(within) * time.Minute)
+	}
+
+	inWorkspace := make(map[string]bool)
+	for _, id := range s.workspaceDeviceIDs(ws) {
+		inWorkspace[id] = true
+	}
+
+	relays := make(conch.WorkspaceRelays, 0)
+	for id, r := range s.relays {
+		if r.LastSeen.Before(cutoff) {
+			continue
+		}
+		relay := *r
+		relay.NumDevices = 0
+		for deviceID := range s.relayDevices[id] {
+			if inWorkspace[deviceID] {
+				relay.NumDevices++
+			}
+		}
+		if relay.NumDevices == 0 {
+			continue
+		}
+		relays = append(relays, relay)
+	}
+	sort.Sort(relays)
+	req.json(relays)
+}
+
+func (s *Server) getWorkspaceRelayDevices(req *request) {
+	ws := s.targetWorkspace(req, "ro")
+	if ws == nil {
+		return
+	}
+	linked, ok := s.relayDevices[req.params[1]]
+	if !ok {
+		req.notFound()
+		return
+	}
+
+	devices := make(conch.Devices, 0)
+	for _, id := range s.workspaceDeviceIDs(ws) {
+		if linked[id] {
+			devices = append(devices, s.device(id))
+		}
+	}
+	req.json(devices)
+}
+
+func (s *Server) getWorkspaceValidationStates(req *request) {
+	ws := s.targetWorkspace(req, "ro")
+	if ws == nil {
+		return
+	}
+
+	states := make([]conch.ValidationState, 0)
+	for _, id := range s.workspaceDeviceIDs(ws) {
+		states = append(states, s.latestValidationStates(id)...)
+	}
+	req.json(states)
+}
@@ -7,24 +7,43 @@
 package conch
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/joyent/conch-shell/pkg/conch/uuid"
 )
 
 func (c *Conch) GetDatacenters() ([]Datacenter, error) {
+	return c.GetDatacentersContext(context.Background())
+}
+
+// GetDatacentersContext is the context.Context aware version of GetDatacenters
+func (c *Conch) GetDatacentersContext(ctx context.Context) ([]Datacenter, error) {
 	d := make([]Datacenter, 0)
-	return d, c.get("/dc", &d)
+	return d, c.get(ctx, "/dc", &d)
 }
 
 func (c *Conch) GetDatacenter(id uuid.UUID) (d Datacenter, err error) {
+	return c.GetDatacenterContext(context.Background(), id)
+}
 
-	return d, c.get("/dc/"+id.String(), &d)
+// GetDatacenterContext is the context.Context aware version of GetDatacenter
+func (c *Conch) GetDatacenterContext(
+	ctx context.Context,
+	id uuid.UUID,
+) (d Datacenter, err error) {
+
+	return d, c.get(ctx, "/dc/"+id.String(), &d)
 }
 
 // SaveDatacenter creates or updates a datacenter in the global domain,
 // based on the presence of an ID
 func (c *Conch) SaveDatacenter(d *Datacenter) error {
+	return c.SaveDatacenterContext(context.Background(), d)
+}
+
+// SaveDatacenterContext is the context.Context aware version of SaveDatacenter
+func (c *Conch) SaveDatacenterContext(ctx context.Context, d *Datacenter) error {
 	if d.Vendor == "" {
 		return ErrBadInput
 	}
@@ -42,22 +61,35 @@ func (c *Conch) SaveDatacenter(d *Datacenter) error {
 	}{d.Vendor, d.Region, d.Location, d.VendorName}
 
 	if uuid.Equal(d.ID, uuid.UUID{}) {
-		return c.post("/dc", j, &d)
+		return c.post(ctx, "/dc", j, &d)
 	} else {
 		escaped := url.PathEscape(d.ID.String())
-		return c.post("/dc/"+escaped, j, &d)
+		return c.post(ctx, "/dc/"+escaped, j, &d)
 	}
 }
 
 // DeleteDatacenter deletes a datacenter
 func (c *Conch) DeleteDatacenter(id uuid.UUID) error {
+	return c.DeleteDatacenterContext(context.Background(), id)
+}
+
+// DeleteDatacenterContext is the context.Context aware version of DeleteDatacenter
+func (c *Conch) DeleteDatacenterContext(ctx context.Context, id uuid.UUID) error {
 	escaped := url.PathEscape(id.String())
-	return c.httpDelete("/dc/" + escaped)
+	return c.httpDelete(ctx, "/dc/"+escaped)
 }
 
 // GetDatacenterRooms gets the global rooms assigned to a global datacenter
 func (c *Conch) GetDatacenterRooms(d Datacenter) ([]Room, error) {
+	return c.GetDatacenterRoomsContext(context.Background(), d)
+}
+
+// GetDatacenterRoomsContext is the context.Context aware version of GetDatacenterRooms
+func (c *Conch) GetDatacenterRoomsContext(
+	ctx context.Context,
+	d Datacenter,
+) ([]Room, error) {
 	r := make([]Room, 0)
 	escaped := url.PathEscape(d.ID.String())
-	return r, c.get("/dc/"+escaped+"/rooms", &r)
+	return r, c.get(ctx, "/dc/"+escaped+"/rooms", &r)
 }
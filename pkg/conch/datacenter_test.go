@@ -23,7 +23,7 @@ func TestDatacenterErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/dc").Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDatacenters()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.Datacenter{})
 	})
 
@@ -33,7 +33,7 @@ func TestDatacenterErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/dc/" + id.String()).Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDatacenter(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Datacenter{})
 	})
 
@@ -47,7 +47,7 @@ func TestDatacenterErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/dc").Reply(400).JSON(ErrApi)
 
 		err := API.SaveDatacenter(&d)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("UpdateDatacenter", func(t *testing.T) {
@@ -62,7 +62,7 @@ func TestDatacenterErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/dc/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.SaveDatacenter(&d)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteDatacenter", func(t *testing.T) {
@@ -71,7 +71,7 @@ func TestDatacenterErrors(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/dc/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.DeleteDatacenter(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetDatacenterRooms", func(t *testing.T) {
@@ -87,7 +87,7 @@ func TestDatacenterErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDatacenterRooms(d)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.Room{})
 	})
 
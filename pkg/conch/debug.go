@@ -8,50 +8,108 @@ package conch
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 )
 
-// ddp pretty prints a structure to stderr. "Deep Data Printer"
-func (c *Conch) ddp(v interface{}) {
-	spew.Fdump(
-		os.Stderr,
-		v,
-	)
+// Logger receives the library's diagnostic output. Each call carries a short
+// message and a list of alternating keys and values, like
+// "method", "GET", "status", 200.
+//
+// Debug and Trace are only called if the matching flag is set on the Conch
+// struct. Warn is always called.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Trace(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+}
+
+// StderrLogger is the Logger used when the Conch struct doesn't provide one.
+// Simple values are printed on one line with the message. Anything more
+// complicated, like a response body, is pretty printed below it.
+type StderrLogger struct{}
+
+// Debug prints the message and values to stderr
+func (l StderrLogger) Debug(msg string, keyvals ...interface{}) {
+	l.print(os.Stderr, msg, keyvals)
+}
+
+// Trace prints the message and values to stderr
+func (l StderrLogger) Trace(msg string, keyvals ...interface{}) {
+	l.print(os.Stderr, msg, keyvals)
+}
+
+// Warn prints the message and values to stderr, prefixed with "WARNING:"
+func (l StderrLogger) Warn(msg string, keyvals ...interface{}) {
+	l.print(os.Stderr, "WARNING: "+msg, keyvals)
+}
+
+func (l StderrLogger) print(w io.Writer, msg string, keyvals []interface{}) {
+	line := []string{msg}
+	dumps := make([]interface{}, 0)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+
+		var val interface{}
+		if i+1 < len(keyvals) {
+			val = keyvals[i+1]
+		}
+
+		switch v := val.(type) {
+		case nil:
+			line = append(line, key+"=")
+		case string:
+			if strings.ContainsAny(v, " \t\n\"") {
+				line = append(line, fmt.Sprintf("%s=%q", key, v))
+			} else {
+				line = append(line, key+"="+v)
+			}
+		case bool, int, int64, float64, time.Duration, error, fmt.Stringer:
+			line = append(line, fmt.Sprintf("%s=%v", key, v))
+		default:
+			// Deep Data Printer
+			dumps = append(dumps, key, v)
+		}
+	}
+
+	fmt.Fprintln(w, strings.Join(line, " "))
+	for i := 0; i < len(dumps); i += 2 {
+		fmt.Fprintf(w, "  %s: %s", dumps[i], spew.Sdump(dumps[i+1]))
+	}
 }
 
-// debugLog prints a string to stderr *if* the Debug flag is set
-func (c *Conch) debugLog(out string) {
-	if c.Debug {
-		fmt.Fprintln(os.Stderr, out)
+func (c *Conch) logger() Logger {
+	if c.Logger == nil {
+		return StderrLogger{}
 	}
+	return c.Logger
 }
 
-// traceLog prints a string to stderr *if* the Trace flag is set
-func (c *Conch) traceLog(out string) {
-	if c.Trace {
-		fmt.Fprintln(os.Stderr, out)
+// debugLog hands a message to the Logger *if* the Debug or Trace flag is set
+func (c *Conch) debugLog(msg string, keyvals ...interface{}) {
+	if c.Debug || c.Trace {
+		c.logger().Debug(msg, keyvals...)
 	}
 }
 
-// traceLogDDP is a convenience function for printing a data structure with a
-// message, *if* the Trace flag is set
-func (c *Conch) traceLogDDP(out string, v interface{}) {
+// traceLog hands a message to the Logger *if* the Trace flag is set.
+// Anything that might carry credentials must go through the redact helpers
+// first.
+func (c *Conch) traceLog(msg string, keyvals ...interface{}) {
 	if c.Trace {
-		c.traceLog(out)
-		c.ddp(v)
+		c.logger().Trace(msg, keyvals...)
 	}
 }
 
-// debugLogDDP is a convenience function for printing a data structure with a
-// message, *if* the Debug flag is set
-//lint:ignore U1000 sure this will get used later
-func (c *Conch) debugLogDDP(out string, v interface{}) {
-	if c.Debug {
-		c.debugLog(out)
-		c.ddp(v)
-	}
+// warnLog hands a message to the Logger, regardless of the Debug and Trace
+// flags
+func (c *Conch) warnLog(msg string, keyvals ...interface{}) {
+	c.logger().Warn(msg, keyvals...)
 }
 
 func init() {
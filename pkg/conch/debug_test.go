@@ -0,0 +1,170 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+type logLine struct {
+	level   string
+	msg     string
+	keyvals []interface{}
+}
+
+type recordingLogger struct {
+	lines []logLine
+}
+
+func (l *recordingLogger) Debug(msg string, keyvals ...interface{}) {
+	l.lines = append(l.lines, logLine{"debug", msg, keyvals})
+}
+
+func (l *recordingLogger) Trace(msg string, keyvals ...interface{}) {
+	l.lines = append(l.lines, logLine{"trace", msg, keyvals})
+}
+
+func (l *recordingLogger) Warn(msg string, keyvals ...interface{}) {
+	l.lines = append(l.lines, logLine{"warn", msg, keyvals})
+}
+
+func (l *recordingLogger) find(msg string) *logLine {
+	for i := range l.lines {
+		if l.lines[i].msg == msg {
+			return &l.lines[i]
+		}
+	}
+	return nil
+}
+
+func (l *recordingLogger) String() string {
+	out := ""
+	for _, line := range l.lines {
+		out += fmt.Sprintf("%s %s %v\n", line.level, line.msg, line.keyvals)
+	}
+	return out
+}
+
+func (l logLine) value(key string) interface{} {
+	for i := 0; i+1 < len(l.keyvals); i += 2 {
+		if l.keyvals[i] == key {
+			return l.keyvals[i+1]
+		}
+	}
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	newAPI := func(log *recordingLogger) *conch.Conch {
+		return &conch.Conch{
+			BaseURL:     "http://localhost",
+			HTTPClient:  http.DefaultClient,
+			RetryPolicy: &conch.NoRetries,
+			Logger:      log,
+		}
+	}
+
+	t.Run("QuietByDefault", func(t *testing.T) {
+		log := &recordingLogger{}
+		api := newAPI(log)
+
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "v2.20.0"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, len(log.lines), 0)
+	})
+
+	t.Run("Debug", func(t *testing.T) {
+		log := &recordingLogger{}
+		api := newAPI(log)
+		api.Debug = true
+
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "v2.20.0"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+
+		res := log.find("response")
+		st.Reject(t, res, nil)
+		st.Expect(t, res.level, "debug")
+		st.Expect(t, res.value("method"), "GET")
+		st.Expect(t, res.value("url"), "http://localhost/version")
+		st.Expect(t, res.value("status"), 200)
+		st.Reject(t, res.value("duration"), nil)
+
+		// Bodies are only for trace
+		st.Expect(t, log.find("response body"), (*logLine)(nil))
+	})
+
+	t.Run("TraceRedactsAuthorization", func(t *testing.T) {
+		log := &recordingLogger{}
+		api := newAPI(log)
+		api.Trace = true
+		api.Token = "sekrit-token"
+
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "v2.20.0"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+
+		headers := log.find("request headers")
+		st.Reject(t, headers, nil)
+		st.Expect(
+			t,
+			headers.value("headers").(http.Header).Get("Authorization"),
+			"Bearer "+conch.Redacted,
+		)
+		st.Expect(t, strings.Contains(log.String(), "sekrit-token"), false)
+	})
+
+	t.Run("TraceRedactsLoginPassword", func(t *testing.T) {
+		log := &recordingLogger{}
+		api := newAPI(log)
+		api.Trace = true
+
+		gock.New(api.BaseURL).Post("/login").Reply(401).
+			JSON(map[string]string{"error": "unauthorized"})
+
+		err := api.Login("user", "hunter2")
+		st.Reject(t, err, nil)
+
+		body := log.find("request body")
+		st.Reject(t, body, nil)
+		st.Expect(t, strings.Contains(body.value("body").(string), `"user":"user"`), true)
+		st.Expect(t, strings.Contains(log.String(), "hunter2"), false)
+	})
+
+	t.Run("WarnUnknownFields", func(t *testing.T) {
+		log := &recordingLogger{}
+		api := newAPI(log)
+		api.WarnUnknownFields = true
+
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "v2.20.0", "shiny": "new"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+
+		st.Expect(t, len(log.lines), 1)
+		st.Expect(t, log.lines[0].level, "warn")
+		st.Expect(t, log.lines[0].value("path"), "/version")
+	})
+}
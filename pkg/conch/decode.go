@@ -0,0 +1,117 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+)
+
+// snippetRadius is how many bytes on either side of a decode failure are
+// included in a DecodeError
+const snippetRadius = 40
+
+// decode unpacks a response body into data. Unless StrictDecoding is set,
+// failures are ignored and data keeps whatever the decoder managed to fill
+// in, which is how this library has always behaved.
+func (c *Conch) decode(req *http.Request, body []byte, data interface{}) error {
+	// 204s and friends. There's nothing to decode and that's fine.
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	err := json.Unmarshal(body, data)
+
+	if c.WarnUnknownFields {
+		c.checkUnknownFields(req, body, data)
+	}
+
+	if err != nil {
+		if c.StrictDecoding {
+			return newDecodeError(req, body, err)
+		}
+		c.debugLog(
+			"ignoring decode failure",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"error", err,
+		)
+	}
+
+	c.traceLog("decoded response", "data", redactData(data))
+
+	return nil
+}
+
+// checkUnknownFields decodes the body a second time, into a throwaway value
+// of the same type, refusing any field the type has no room for
+func (c *Conch) checkUnknownFields(req *http.Request, body []byte, data interface{}) {
+	t := reflect.TypeOf(data)
+	if t.Kind() != reflect.Ptr {
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(reflect.New(t.Elem()).Interface())
+	if err == nil {
+		return
+	}
+
+	// Type mismatches are the business of StrictDecoding. Here, we only care
+	// about fields the API added.
+	if _, ok := err.(*json.UnmarshalTypeError); ok {
+		return
+	}
+	if _, ok := err.(*json.SyntaxError); ok {
+		return
+	}
+
+	c.warnLog(
+		"response does not match the expected structure. The API may have changed",
+		"method", req.Method,
+		"path", req.URL.Path,
+		"type", t.Elem().String(),
+		"error", err,
+	)
+}
+
+func newDecodeError(req *http.Request, body []byte, err error) *DecodeError {
+	derr := &DecodeError{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Offset: -1,
+		Err:    err,
+	}
+
+	switch e := err.(type) {
+	case *json.UnmarshalTypeError:
+		derr.Field = e.Field
+		derr.Offset = e.Offset
+	case *json.SyntaxError:
+		derr.Offset = e.Offset
+	}
+
+	start := int64(0)
+	end := int64(len(body))
+	if derr.Offset >= 0 {
+		if derr.Offset-snippetRadius > start {
+			start = derr.Offset - snippetRadius
+		}
+		if derr.Offset+snippetRadius < end {
+			end = derr.Offset + snippetRadius
+		}
+	} else if end > 2*snippetRadius {
+		end = 2 * snippetRadius
+	}
+	derr.Snippet = string(body[start:end])
+
+	return derr
+}
@@ -0,0 +1,84 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestDecoding(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	broken := `{"id":42,"asset_tag":"wat","health":"pass"}`
+
+	t.Run("LenientByDefault", func(t *testing.T) {
+		gock.New(API.BaseURL).Get("/device/test").Reply(200).
+			BodyString(broken)
+
+		ret, err := API.GetDevice("test")
+		st.Expect(t, err, nil)
+		st.Expect(t, ret.AssetTag, "wat")
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		api := &conch.Conch{
+			BaseURL:        API.BaseURL,
+			HTTPClient:     http.DefaultClient,
+			StrictDecoding: true,
+		}
+
+		gock.New(api.BaseURL).Get("/device/test").Reply(200).
+			BodyString(broken)
+
+		_, err := api.GetDevice("test")
+
+		var derr *conch.DecodeError
+		st.Expect(t, errors.As(err, &derr), true)
+		st.Expect(t, derr.Method, "GET")
+		st.Expect(t, derr.Path, "/device/test")
+		st.Expect(t, derr.Field, "id")
+		st.Expect(t, strings.Contains(derr.Snippet, `"id":42`), true)
+	})
+
+	t.Run("StrictIgnoresEmptyBodies", func(t *testing.T) {
+		api := &conch.Conch{
+			BaseURL:        API.BaseURL,
+			HTTPClient:     http.DefaultClient,
+			StrictDecoding: true,
+		}
+
+		gock.New(api.BaseURL).Post("/rack_role").Reply(204)
+
+		err := api.SaveRackRole(&conch.RackRole{Name: "wat", RackSize: 42})
+		st.Expect(t, err, nil)
+	})
+
+	t.Run("StrictSyntaxError", func(t *testing.T) {
+		api := &conch.Conch{
+			BaseURL:        API.BaseURL,
+			HTTPClient:     http.DefaultClient,
+			StrictDecoding: true,
+		}
+
+		gock.New(api.BaseURL).Get("/rack_role").Reply(200).
+			BodyString(`[{"name":`)
+
+		_, err := api.GetRackRoles()
+
+		var derr *conch.DecodeError
+		st.Expect(t, errors.As(err, &derr), true)
+		st.Expect(t, derr.Snippet, `[{"name":`)
+	})
+}
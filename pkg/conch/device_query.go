@@ -0,0 +1,354 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+)
+
+// DeviceQuery describes a search for devices, for FindDevices. Every filter
+// narrows the search, so a device has to match all of them. Setting the same
+// filter twice keeps the last value, except for settings and tags, where
+// each key is its own filter.
+//
+//	q := conch.NewDeviceQuery().
+//		Tag("role", "storage").
+//		Health("fail").
+//		Phase("production")
+//	devices, err := api.FindDevices(q)
+//
+// The API only searches on one thing at a time. /device takes a single
+// hostname, MAC, IP address, or setting, and the devices of a workspace can
+// only be narrowed by health, graduation, and validation. FindDevices asks
+// the API for what it can, once for each of those, and applies the rest of
+// the filters itself.
+type DeviceQuery struct {
+	workspace string
+	idsOnly   bool
+
+	fields   map[string]string
+	settings map[string]string
+
+	health    string
+	phase     string
+	graduated *bool
+	validated *bool
+	rack      uuid.UUID
+	product   uuid.UUID
+}
+
+// reservedSettings are the /device parameters that the API reads as
+// something other than a setting, so settings of those names cannot be
+// searched for
+var reservedSettings = map[string]bool{
+	"hostname": true,
+	"mac":      true,
+	"ipaddr":   true,
+}
+
+// NewDeviceQuery returns an empty DeviceQuery. A zero DeviceQuery works just
+// as well.
+func NewDeviceQuery() *DeviceQuery {
+	return &DeviceQuery{}
+}
+
+func setOrDelete(m *map[string]string, key string, value string) {
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	if value == "" {
+		delete(*m, key)
+	} else {
+		(*m)[key] = value
+	}
+}
+
+// Field searches /device on an arbitrary query parameter, for anything the
+// other methods don't cover. The API takes any name but hostname, mac, and
+// ipaddr to be a setting. An empty value removes the filter.
+func (q *DeviceQuery) Field(key string, value string) *DeviceQuery {
+	setOrDelete(&q.fields, key, value)
+	return q
+}
+
+// Hostname matches devices with exactly this hostname
+func (q *DeviceQuery) Hostname(hostname string) *DeviceQuery {
+	return q.Field("hostname", hostname)
+}
+
+// MAC matches devices with a NIC having this MAC address
+func (q *DeviceQuery) MAC(mac string) *DeviceQuery {
+	return q.Field("mac", mac)
+}
+
+// IPAddr matches devices with a NIC having this IP address
+func (q *DeviceQuery) IPAddr(ipaddr string) *DeviceQuery {
+	return q.Field("ipaddr", ipaddr)
+}
+
+// Setting matches devices whose setting has exactly this value. Settings
+// named hostname, mac, or ipaddr cannot be searched for, and FindDevices
+// returns an error for them.
+func (q *DeviceQuery) Setting(key string, value string) *DeviceQuery {
+	setOrDelete(&q.settings, key, value)
+	return q
+}
+
+// Tag matches devices whose tag has exactly this value. Tags are settings
+// whose name begins with "tag.".
+func (q *DeviceQuery) Tag(key string, value string) *DeviceQuery {
+	return q.Setting("tag."+key, value)
+}
+
+// Phase matches devices in this phase, like "production"
+func (q *DeviceQuery) Phase(phase string) *DeviceQuery {
+	q.phase = phase
+	return q
+}
+
+// Health matches devices of this health, like "fail"
+func (q *DeviceQuery) Health(health string) *DeviceQuery {
+	q.health = health
+	return q
+}
+
+// Graduated matches devices that have graduated, or have not
+func (q *DeviceQuery) Graduated(graduated bool) *DeviceQuery {
+	q.graduated = &graduated
+	return q
+}
+
+// Validated matches devices that have been validated, or have not
+func (q *DeviceQuery) Validated(validated bool) *DeviceQuery {
+	q.validated = &validated
+	return q
+}
+
+// Rack matches devices in this rack. A zero ID removes the filter.
+func (q *DeviceQuery) Rack(id uuid.UUID) *DeviceQuery {
+	q.rack = id
+	return q
+}
+
+// HardwareProduct matches devices of this hardware product. A zero ID
+// removes the filter.
+func (q *DeviceQuery) HardwareProduct(id uuid.UUID) *DeviceQuery {
+	q.product = id
+	return q
+}
+
+// Workspace limits the search to the devices of this workspace. Without
+// it, every device the user can see is searched.
+func (q *DeviceQuery) Workspace(id fmt.Stringer) *DeviceQuery {
+	q.workspace = id.String()
+	return q
+}
+
+// IDsOnly asks for the device IDs only. The Devices returned carry nothing
+// but their ID.
+func (q *DeviceQuery) IDsOnly() *DeviceQuery {
+	q.idsOnly = true
+	return q
+}
+
+// searches returns the /device searches for the query, one parameter each,
+// sorted by name with the fields first
+func (q *DeviceQuery) searches() []url.Values {
+	searches := make([]url.Values, 0, len(q.fields)+len(q.settings))
+	for _, m := range []map[string]string{q.fields, q.settings} {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			searches = append(searches, url.Values{key: {m[key]}})
+		}
+	}
+	return searches
+}
+
+// workspaceParams returns the filters that the workspace device route
+// applies itself
+func (q *DeviceQuery) workspaceParams() url.Values {
+	v := make(url.Values)
+	if q.health != "" {
+		v.Set("health", q.health)
+	}
+	if q.graduated != nil {
+		v.Set("graduated", boolParam(*q.graduated))
+	}
+	if q.validated != nil {
+		v.Set("validated", boolParam(*q.validated))
+	}
+	return v
+}
+
+// filtersLocally reports whether any filter has to be applied to the full
+// devices, as neither route knows it
+func (q *DeviceQuery) filtersLocally() bool {
+	return (q.phase != "") || !q.rack.IsZero() || !q.product.IsZero()
+}
+
+// match reports whether a device passes the filters that are not searched
+// for
+func (q *DeviceQuery) match(d Device) bool {
+	if (q.health != "") && !strings.EqualFold(d.Health, q.health) {
+		return false
+	}
+	if (q.phase != "") && !strings.EqualFold(d.Phase, q.phase) {
+		return false
+	}
+	if (q.graduated != nil) && (*q.graduated == d.Graduated.IsZero()) {
+		return false
+	}
+	if (q.validated != nil) && (*q.validated == d.Validated.IsZero()) {
+		return false
+	}
+	if !q.rack.IsZero() && !uuid.Equal(q.rack, d.RackID) {
+		return false
+	}
+	if !q.product.IsZero() && !uuid.Equal(q.product, d.HardwareProduct) {
+		return false
+	}
+	return true
+}
+
+func boolParam(b bool) string {
+	if b {
+		return "t"
+	}
+	return "f"
+}
+
+// intersectDevices returns the devices of a whose ID is in ids, in the order
+// of a
+func intersectDevices(a Devices, ids map[string]bool) Devices {
+	devices := make(Devices, 0, len(a))
+	for _, d := range a {
+		if ids[d.ID] {
+			devices = append(devices, d)
+		}
+	}
+	return devices
+}
+
+func deviceIDs(devices Devices) map[string]bool {
+	ids := make(map[string]bool, len(devices))
+	for _, d := range devices {
+		ids[d.ID] = true
+	}
+	return ids
+}
+
+// FindDevices returns the devices matching a DeviceQuery. Searching all
+// devices needs a hostname, MAC, IP address, setting, or tag, or ErrBadInput
+// is returned. A workspace's devices can be listed without any.
+func (c *Conch) FindDevices(q *DeviceQuery) (Devices, error) {
+	return c.FindDevicesContext(context.Background(), q)
+}
+
+// FindDevicesContext is the context.Context aware version of FindDevices
+func (c *Conch) FindDevicesContext(
+	ctx context.Context,
+	q *DeviceQuery,
+) (Devices, error) {
+	devices := make(Devices, 0)
+
+	if q.phase != "" {
+		if err := c.require(CapDevicePhases); err != nil {
+			return devices, err
+		}
+	}
+
+	for key := range q.settings {
+		if reservedSettings[key] {
+			return devices, fmt.Errorf(
+				"cannot search for the setting '%s', as the API takes it to be the %s of the device",
+				key,
+				key,
+			)
+		}
+	}
+
+	searches := q.searches()
+	if (len(searches) == 0) && (q.workspace == "") {
+		return devices, ErrBadInput
+	}
+
+	for i, params := range searches {
+		found := make(Devices, 0)
+		if err := c.get(ctx, "/device?"+params.Encode(), &found); err != nil {
+			return devices, err
+		}
+
+		if i == 0 {
+			devices = found
+		} else {
+			devices = intersectDevices(devices, deviceIDs(found))
+		}
+		if len(devices) == 0 {
+			return devices, nil
+		}
+	}
+
+	if q.workspace != "" {
+		path := "/workspace/" + url.PathEscape(q.workspace) + "/device"
+		params := q.workspaceParams()
+
+		// The devices found so far only need narrowing down to the
+		// workspace, and with nothing else to filter, neither does the
+		// caller need more than the IDs
+		if (len(searches) > 0) || (q.idsOnly && !q.filtersLocally()) {
+			params.Set("ids_only", "true")
+
+			ids := make([]string, 0)
+			if err := c.get(ctx, path+"?"+params.Encode(), &ids); err != nil {
+				return make(Devices, 0), err
+			}
+
+			if len(searches) == 0 {
+				for _, id := range ids {
+					devices = append(devices, Device{ID: id})
+				}
+				return devices, nil
+			}
+
+			inWorkspace := make(map[string]bool, len(ids))
+			for _, id := range ids {
+				inWorkspace[id] = true
+			}
+			devices = intersectDevices(devices, inWorkspace)
+		} else {
+			if query := params.Encode(); query != "" {
+				path += "?" + query
+			}
+			if err := c.get(ctx, path, &devices); err != nil {
+				return make(Devices, 0), err
+			}
+		}
+	}
+
+	matched := make(Devices, 0, len(devices))
+	for _, d := range devices {
+		if !q.match(d) {
+			continue
+		}
+		if q.idsOnly {
+			d = Device{ID: d.ID}
+		}
+		matched = append(matched, d)
+	}
+	return matched, nil
+}
@@ -0,0 +1,137 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/conch/uuid"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestDeviceQuery(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	t.Run("FindDevices", func(t *testing.T) {
+		gock.New(API.BaseURL).Get("/device").
+			MatchParam("hostname", "foo").
+			Reply(200).JSON([]conch.Device{{ID: "AB123"}, {ID: "CD456"}})
+
+		ret, err := API.FindDevices(conch.NewDeviceQuery().Hostname("foo"))
+		st.Expect(t, err, nil)
+		st.Expect(t, ret, conch.Devices{{ID: "AB123"}, {ID: "CD456"}})
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("FindDevicesOneParameterEach", func(t *testing.T) {
+		rack := uuid.NewV4()
+		devices := []conch.Device{
+			{ID: "AB123", Health: "fail", RackID: rack},
+			{ID: "CD456", Health: "pass", RackID: rack},
+			{ID: "EF789", Health: "fail"},
+			{ID: "GH012", Health: "fail", RackID: rack},
+		}
+
+		// Each search must be the only parameter, so that the API doesn't
+		// take the rest for settings. AddMatcher would change gock's
+		// default matcher, which every mock shares, so each mock gets a
+		// matcher of its own.
+		only := func(key string) gock.Matcher {
+			m := gock.NewEmptyMatcher()
+			for _, fn := range gock.Matchers {
+				m.Add(fn)
+			}
+			m.Add(func(r *http.Request, _ *gock.Request) (bool, error) {
+				q := r.URL.Query()
+				return (len(q) == 1) && (q.Get(key) != ""), nil
+			})
+			return m
+		}
+
+		gock.New(API.BaseURL).Get("/device").SetMatcher(only("mac")).
+			MatchParam("mac", "00:11:22:33:44:55").
+			Reply(200).JSON(devices)
+		gock.New(API.BaseURL).Get("/device").SetMatcher(only("tag.role")).
+			MatchParam("tag.role", "storage").
+			Reply(200).JSON(devices[:3])
+
+		ret, err := API.FindDevices(conch.NewDeviceQuery().
+			MAC("00:11:22:33:44:55").
+			Tag("role", "storage").
+			Health("fail").
+			Rack(rack).
+			Graduated(false).
+			IDsOnly())
+		st.Expect(t, err, nil)
+		st.Expect(t, ret, conch.Devices{{ID: "AB123"}})
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("FindDevicesInWorkspace", func(t *testing.T) {
+		ws := uuid.NewV4()
+
+		gock.New(API.BaseURL).Get("/workspace/"+ws.String()+"/device").
+			MatchParam("health", "fail").
+			MatchParam("validated", "t").
+			MatchParam("ids_only", "true").
+			Reply(200).JSON([]string{"AB123", "CD456"})
+
+		ret, err := API.FindDevices(conch.NewDeviceQuery().
+			Workspace(ws).
+			Health("fail").
+			Validated(true).
+			IDsOnly())
+		st.Expect(t, err, nil)
+		st.Expect(t, ret, conch.Devices{{ID: "AB123"}, {ID: "CD456"}})
+		st.Expect(t, gock.IsDone(), true)
+
+		gock.New(API.BaseURL).Get("/device").
+			MatchParam("hostname", "foo").
+			Reply(200).JSON([]conch.Device{{ID: "AB123"}, {ID: "EF789"}})
+		gock.New(API.BaseURL).Get("/workspace/"+ws.String()+"/device").
+			MatchParam("ids_only", "true").
+			Reply(200).JSON([]string{"AB123", "CD456"})
+
+		ret, err = API.FindDevices(conch.NewDeviceQuery().Workspace(ws).Hostname("foo"))
+		st.Expect(t, err, nil)
+		st.Expect(t, ret, conch.Devices{{ID: "AB123"}})
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("FindDevicesReservedSetting", func(t *testing.T) {
+		for _, name := range []string{"hostname", "mac", "ipaddr"} {
+			_, err := API.FindDevices(conch.NewDeviceQuery().Setting(name, "foo"))
+			st.Reject(t, err, nil)
+		}
+		st.Expect(t, gock.HasUnmatchedRequest(), false)
+	})
+
+	t.Run("FindDevicesErrors", func(t *testing.T) {
+		ret, err := API.FindDevices(conch.NewDeviceQuery())
+		st.Expect(t, err, conch.ErrBadInput)
+		st.Expect(t, ret, conch.Devices{})
+
+		gock.New(API.BaseURL).Get("/device").MatchParam("hostname", "bar").
+			Reply(400).JSON(ErrApi)
+
+		ret, err = API.FindDevices(conch.NewDeviceQuery().Hostname("bar"))
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
+		st.Expect(t, ret, conch.Devices{})
+	})
+
+	t.Run("FindDevicesByPhase", func(t *testing.T) {
+		api := conch.New(conch.WithBaseURL(API.BaseURL), conch.WithAPIVersion("2.26.0"))
+
+		_, err := api.FindDevices(conch.NewDeviceQuery().Phase("production"))
+		st.Expect(t, errors.Is(err, conch.ErrNotSupported), true)
+	})
+}
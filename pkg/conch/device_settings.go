@@ -7,6 +7,7 @@
 package conch
 
 import (
+	"context"
 	"net/url"
 	"regexp"
 	"strings"
@@ -22,12 +23,20 @@ func isTag(str string) bool {
 // /device/:serial/settings
 // Device settings that begin with 'tag.' are filtered out.
 func (c *Conch) GetDeviceSettings(serial string) (map[string]string, error) {
+	return c.GetDeviceSettingsContext(context.Background(), serial)
+}
+
+// GetDeviceSettingsContext is the context.Context aware version of GetDeviceSettings
+func (c *Conch) GetDeviceSettingsContext(
+	ctx context.Context,
+	serial string,
+) (map[string]string, error) {
 	settings := make(map[string]string)
 	filtered := make(map[string]string)
 
 	escaped := url.PathEscape(serial)
 
-	if err := c.get("/device/"+escaped+"/settings", &settings); err != nil {
+	if err := c.get(ctx, "/device/"+escaped+"/settings", &settings); err != nil {
 		return filtered, err
 	}
 
@@ -44,6 +53,15 @@ func (c *Conch) GetDeviceSettings(serial string) (map[string]string, error) {
 // /device/:serial/settings/:key
 // Device settings that begin with 'tag.' are filtered out.
 func (c *Conch) GetDeviceSetting(serial string, key string) (string, error) {
+	return c.GetDeviceSettingContext(context.Background(), serial, key)
+}
+
+// GetDeviceSettingContext is the context.Context aware version of GetDeviceSetting
+func (c *Conch) GetDeviceSettingContext(
+	ctx context.Context,
+	serial string,
+	key string,
+) (string, error) {
 
 	if isTag(key) {
 		return "", ErrDataNotFound
@@ -53,7 +71,7 @@ func (c *Conch) GetDeviceSetting(serial string, key string) (string, error) {
 	j := make(map[string]string)
 	escaped := url.PathEscape(serial)
 
-	if err := c.get("/device/"+escaped+"/settings/"+key, &j); err != nil {
+	if err := c.get(ctx, "/device/"+escaped+"/settings/"+key, &j); err != nil {
 		return setting, err
 	}
 
@@ -68,6 +86,16 @@ func (c *Conch) GetDeviceSetting(serial string, key string) (string, error) {
 // Settings that begin with "tag." cannot be processed by this routine and will
 // always return ErrDataNotFound
 func (c *Conch) SetDeviceSetting(deviceID string, key string, value string) error {
+	return c.SetDeviceSettingContext(context.Background(), deviceID, key, value)
+}
+
+// SetDeviceSettingContext is the context.Context aware version of SetDeviceSetting
+func (c *Conch) SetDeviceSettingContext(
+	ctx context.Context,
+	deviceID string,
+	key string,
+	value string,
+) error {
 	if isTag(key) {
 		return ErrDataNotFound
 	}
@@ -78,7 +106,7 @@ func (c *Conch) SetDeviceSetting(deviceID string, key string, value string) erro
 	escapedDevice := url.PathEscape(deviceID)
 	escapedKey := url.PathEscape(key)
 
-	return c.post(
+	return c.post(ctx,
 		"/device/"+escapedDevice+"/settings/"+escapedKey,
 		j,
 		nil,
@@ -90,6 +118,15 @@ func (c *Conch) SetDeviceSetting(deviceID string, key string, value string) erro
 // Settings that begin with "tag." cannot be processed by this routine and will
 // always return ErrDataNotFound
 func (c *Conch) DeleteDeviceSetting(deviceID string, key string) error {
+	return c.DeleteDeviceSettingContext(context.Background(), deviceID, key)
+}
+
+// DeleteDeviceSettingContext is the context.Context aware version of DeleteDeviceSetting
+func (c *Conch) DeleteDeviceSettingContext(
+	ctx context.Context,
+	deviceID string,
+	key string,
+) error {
 	if isTag(key) {
 		return ErrDataNotFound
 	}
@@ -97,18 +134,26 @@ func (c *Conch) DeleteDeviceSetting(deviceID string, key string) error {
 	escapedDevice := url.PathEscape(deviceID)
 	escapedKey := url.PathEscape(key)
 
-	return c.httpDelete("/device/" + escapedDevice + "/settings/" + escapedKey)
+	return c.httpDelete(ctx, "/device/"+escapedDevice+"/settings/"+escapedKey)
 }
 
 // GetDeviceTags fetches tags for a device, via /device/:serial/settings
 // Device settings that do NOT begin with 'tag.' are filtered out.
 func (c *Conch) GetDeviceTags(serial string) (map[string]string, error) {
+	return c.GetDeviceTagsContext(context.Background(), serial)
+}
+
+// GetDeviceTagsContext is the context.Context aware version of GetDeviceTags
+func (c *Conch) GetDeviceTagsContext(
+	ctx context.Context,
+	serial string,
+) (map[string]string, error) {
 	settings := make(map[string]string)
 	filtered := make(map[string]string)
 
 	escaped := url.PathEscape(serial)
 
-	if err := c.get("/device/"+escaped+"/settings", &settings); err != nil {
+	if err := c.get(ctx, "/device/"+escaped+"/settings", &settings); err != nil {
 		return filtered, err
 	}
 
@@ -127,6 +172,15 @@ func (c *Conch) GetDeviceTags(serial string) (map[string]string, error) {
 // /device/:serial/settings/:key
 // The key must either begin with 'tag.' or it will be prepended
 func (c *Conch) GetDeviceTag(serial string, key string) (string, error) {
+	return c.GetDeviceTagContext(context.Background(), serial, key)
+}
+
+// GetDeviceTagContext is the context.Context aware version of GetDeviceTag
+func (c *Conch) GetDeviceTagContext(
+	ctx context.Context,
+	serial string,
+	key string,
+) (string, error) {
 
 	if !isTag(key) {
 		key = "tag." + key
@@ -137,7 +191,7 @@ func (c *Conch) GetDeviceTag(serial string, key string) (string, error) {
 
 	escaped := url.PathEscape(serial)
 
-	if err := c.get("/device/"+escaped+"/settings/"+key, &j); err != nil {
+	if err := c.get(ctx, "/device/"+escaped+"/settings/"+key, &j); err != nil {
 		return setting, err
 	}
 
@@ -151,6 +205,16 @@ func (c *Conch) GetDeviceTag(serial string, key string) (string, error) {
 // SetDeviceTag sets a single tag for a device via /device/:deviceID/settings/:key
 // The key must either begin with 'tag.' or it will be prepended
 func (c *Conch) SetDeviceTag(deviceID string, key string, value string) error {
+	return c.SetDeviceTagContext(context.Background(), deviceID, key, value)
+}
+
+// SetDeviceTagContext is the context.Context aware version of SetDeviceTag
+func (c *Conch) SetDeviceTagContext(
+	ctx context.Context,
+	deviceID string,
+	key string,
+	value string,
+) error {
 	if !isTag(key) {
 		key = "tag." + key
 	}
@@ -161,7 +225,7 @@ func (c *Conch) SetDeviceTag(deviceID string, key string, value string) error {
 	escapedDevice := url.PathEscape(deviceID)
 	escapedKey := url.PathEscape(key)
 
-	return c.post("/device/"+escapedDevice+"/settings/"+escapedKey, j, nil)
+	return c.post(ctx, "/device/"+escapedDevice+"/settings/"+escapedKey, j, nil)
 }
 
 // DeleteDeviceTag deletes a single tag for a device via
@@ -169,6 +233,15 @@ func (c *Conch) SetDeviceTag(deviceID string, key string, value string) error {
 // Settings that do NOT begin with "tag." cannot be processed by this routine
 // and will always return ErrDataNotFound
 func (c *Conch) DeleteDeviceTag(deviceID string, key string) error {
+	return c.DeleteDeviceTagContext(context.Background(), deviceID, key)
+}
+
+// DeleteDeviceTagContext is the context.Context aware version of DeleteDeviceTag
+func (c *Conch) DeleteDeviceTagContext(
+	ctx context.Context,
+	deviceID string,
+	key string,
+) error {
 	if !isTag(key) {
 		key = "tag." + key
 	}
@@ -176,14 +249,32 @@ func (c *Conch) DeleteDeviceTag(deviceID string, key string) error {
 	escapedDevice := url.PathEscape(deviceID)
 	escapedKey := url.PathEscape(key)
 
-	return c.httpDelete("/device/" + escapedDevice + "/settings/" + escapedKey)
+	return c.httpDelete(ctx, "/device/"+escapedDevice+"/settings/"+escapedKey)
 }
 
 func (c *Conch) GetDevicesBySetting(key string, value string) (d Devices, err error) {
-	return c.GetDevicesByField(key, value)
+	return c.GetDevicesBySettingContext(context.Background(), key, value)
+}
+
+// GetDevicesBySettingContext is the context.Context aware version of GetDevicesBySetting
+func (c *Conch) GetDevicesBySettingContext(
+	ctx context.Context,
+	key string,
+	value string,
+) (d Devices, err error) {
+	return c.GetDevicesByFieldContext(ctx, key, value)
 }
 
 func (c *Conch) GetDevicesByTag(key string, value string) (Devices, error) {
-	return c.GetDevicesByField("tag."+key, value)
+	return c.GetDevicesByTagContext(context.Background(), key, value)
+}
+
+// GetDevicesByTagContext is the context.Context aware version of GetDevicesByTag
+func (c *Conch) GetDevicesByTagContext(
+	ctx context.Context,
+	key string,
+	value string,
+) (Devices, error) {
+	return c.GetDevicesByFieldContext(ctx, "tag."+key, value)
 
 }
@@ -25,7 +25,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDeviceSettings(serial)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, make(map[string]string))
 	})
 
@@ -37,7 +37,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDeviceSetting(serial, key)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		var setting string
 		st.Expect(t, ret, setting)
 	})
@@ -50,7 +50,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.SetDeviceSetting(serial, key, "val")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteDeviceSetting", func(t *testing.T) {
@@ -61,7 +61,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.DeleteDeviceSetting(serial, key)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetDeviceTags", func(t *testing.T) {
@@ -71,7 +71,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDeviceTags(serial)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, make(map[string]string))
 	})
 
@@ -83,7 +83,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDeviceTag(serial, key)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		var setting string
 		st.Expect(t, ret, setting)
 	})
@@ -96,7 +96,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.SetDeviceTag(serial, key, "val")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteDeviceTag", func(t *testing.T) {
@@ -107,7 +107,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.DeleteDeviceTag(serial, key)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetDevicesBySetting", func(t *testing.T) {
@@ -117,7 +117,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDevicesBySetting("foo", "bar")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, d)
 	})
 
@@ -128,7 +128,7 @@ func TestDeviceSettingsErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDevicesByTag("foo", "bar")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, d)
 	})
 
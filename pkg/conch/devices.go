@@ -8,7 +8,7 @@ package conch
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"net/url"
 	"sort"
 
@@ -17,14 +17,30 @@ import (
 
 // GetDevice returns a Device given a specific serial/id
 func (c *Conch) GetDevice(serial string) (d Device, err error) {
+	return c.GetDeviceContext(context.Background(), serial)
+}
+
+// GetDeviceContext is the context.Context aware version of GetDevice
+func (c *Conch) GetDeviceContext(
+	ctx context.Context,
+	serial string,
+) (d Device, err error) {
 	d.ID = serial
 
-	return c.FillInDevice(d)
+	return c.FillInDeviceContext(ctx, d)
 }
 
 func (c *Conch) GetExtendedDevice(serial string) (ed ExtendedDevice, err error) {
+	return c.GetExtendedDeviceContext(context.Background(), serial)
+}
 
-	d, err := c.GetDevice(serial)
+// GetExtendedDeviceContext is the context.Context aware version of GetExtendedDevice
+func (c *Conch) GetExtendedDeviceContext(
+	ctx context.Context,
+	serial string,
+) (ed ExtendedDevice, err error) {
+
+	d, err := c.GetDeviceContext(ctx, serial)
 	if err != nil {
 		return ExtendedDevice{}, err
 	}
@@ -47,7 +63,7 @@ func (c *Conch) GetExtendedDevice(serial string) (ed ExtendedDevice, err error)
 
 	var role RackRole
 	if !d.Location.Rack.RoleID.IsZero() {
-		role, err = c.GetRackRole(d.Location.Rack.RoleID)
+		role, err = c.GetRackRoleContext(ctx, d.Location.Rack.RoleID)
 		if err != nil {
 			return ed, err
 		}
@@ -67,7 +83,7 @@ func (c *Conch) GetExtendedDevice(serial string) (ed ExtendedDevice, err error)
 	}
 
 	allValidations := make(map[uuid.UUID]Validation)
-	serverValidations, err := c.GetValidations()
+	serverValidations, err := c.GetValidationsContext(ctx)
 	if err != nil {
 		return ed, err
 	}
@@ -75,13 +91,13 @@ func (c *Conch) GetExtendedDevice(serial string) (ed ExtendedDevice, err error)
 		allValidations[v.ID] = v
 	}
 
-	if validationStates, err := c.DeviceValidationStates(d.ID); err == nil {
+	if validationStates, err := c.DeviceValidationStatesContext(ctx, d.ID); err == nil {
 
 		plans := make([]ValidationPlanExecution, 0)
 
 		for _, state := range validationStates {
 			name := "[unknown]"
-			validationPlan, err := c.GetValidationPlan(state.ValidationPlanID)
+			validationPlan, err := c.GetValidationPlanContext(ctx, state.ValidationPlanID)
 			if err == nil {
 				name = validationPlan.Name
 			}
@@ -130,13 +146,13 @@ func (c *Conch) GetExtendedDevice(serial string) (ed ExtendedDevice, err error)
 		ed.Validations = plans
 	}
 
-	ipmi, err := c.GetDeviceIPMI(d.ID)
+	ipmi, err := c.GetDeviceIPMIContext(ctx, d.ID)
 	if err == nil {
 		ed.IPMI = ipmi
 	}
 
 	if !uuid.Equal(d.HardwareProduct, uuid.UUID{}) {
-		hp, err := c.GetHardwareProduct(d.HardwareProduct)
+		hp, err := c.GetHardwareProductContext(ctx, d.HardwareProduct)
 		if err == nil {
 			ed.HardwareName = hp.Name
 			ed.SKU = hp.SKU
@@ -152,15 +168,28 @@ func (c *Conch) GetExtendedDevice(serial string) (ed ExtendedDevice, err error)
 // likely, though, that any client utility will eventually want all the data
 // about a device and not just bits
 func (c *Conch) FillInDevice(d Device) (Device, error) {
+	return c.FillInDeviceContext(context.Background(), d)
+}
+
+// FillInDeviceContext is the context.Context aware version of FillInDevice
+func (c *Conch) FillInDeviceContext(ctx context.Context, d Device) (Device, error) {
 	escaped := url.PathEscape(d.ID)
-	return d, c.get("/device/"+escaped, &d)
+	return d, c.get(ctx, "/device/"+escaped, &d)
 }
 
 // GetDeviceLocation fetches the location for a device, via
 // /device/:serial/location
 func (c *Conch) GetDeviceLocation(serial string) (loc DeviceLocation, err error) {
+	return c.GetDeviceLocationContext(context.Background(), serial)
+}
+
+// GetDeviceLocationContext is the context.Context aware version of GetDeviceLocation
+func (c *Conch) GetDeviceLocationContext(
+	ctx context.Context,
+	serial string,
+) (loc DeviceLocation, err error) {
 	escaped := url.PathEscape(serial)
-	return loc, c.get("/device/"+escaped+"/location", &loc)
+	return loc, c.get(ctx, "/device/"+escaped+"/location", &loc)
 }
 
 // GraduateDevice sets the 'graduated' field for the given device, via
@@ -168,8 +197,13 @@ func (c *Conch) GetDeviceLocation(serial string) (loc DeviceLocation, err error)
 // WARNING: This is a one way operation and cannot currently be undone via the
 // API
 func (c *Conch) GraduateDevice(serial string) error {
+	return c.GraduateDeviceContext(context.Background(), serial)
+}
+
+// GraduateDeviceContext is the context.Context aware version of GraduateDevice
+func (c *Conch) GraduateDeviceContext(ctx context.Context, serial string) error {
 	escaped := url.PathEscape(serial)
-	return c.post("/device/"+escaped+"/graduate", nil, nil)
+	return c.post(ctx, "/device/"+escaped+"/graduate", nil, nil)
 }
 
 // DeviceTritonReboot sets the 'triton_reboot' field for the given device, via
@@ -177,12 +211,26 @@ func (c *Conch) GraduateDevice(serial string) error {
 // WARNING: This is a one way operation and cannot currently be undone via the
 // API
 func (c *Conch) DeviceTritonReboot(serial string) error {
+	return c.DeviceTritonRebootContext(context.Background(), serial)
+}
+
+// DeviceTritonRebootContext is the context.Context aware version of DeviceTritonReboot
+func (c *Conch) DeviceTritonRebootContext(ctx context.Context, serial string) error {
 	escaped := url.PathEscape(serial)
-	return c.post("/device/"+escaped+"/triton_reboot", nil, nil)
+	return c.post(ctx, "/device/"+escaped+"/triton_reboot", nil, nil)
 }
 
 // SetDeviceTritonUUID sets the triton UUID via /device/:serial/triton_uuid
 func (c *Conch) SetDeviceTritonUUID(serial string, id uuid.UUID) error {
+	return c.SetDeviceTritonUUIDContext(context.Background(), serial, id)
+}
+
+// SetDeviceTritonUUIDContext is the context.Context aware version of SetDeviceTritonUUID
+func (c *Conch) SetDeviceTritonUUIDContext(
+	ctx context.Context,
+	serial string,
+	id uuid.UUID,
+) error {
 	j := struct {
 		TritonUUID string `json:"triton_uuid"`
 	}{
@@ -190,7 +238,7 @@ func (c *Conch) SetDeviceTritonUUID(serial string, id uuid.UUID) error {
 	}
 
 	escaped := url.PathEscape(serial)
-	return c.post("/device/"+escaped+"/triton_uuid", j, nil)
+	return c.post(ctx, "/device/"+escaped+"/triton_uuid", j, nil)
 }
 
 // MarkDeviceTritonSetup marks the device as setup for Triton
@@ -198,12 +246,26 @@ func (c *Conch) SetDeviceTritonUUID(serial string, id uuid.UUID) error {
 // marked as rebooted into Triton. If these conditions are not met, this
 // function will return ErrBadInput
 func (c *Conch) MarkDeviceTritonSetup(serial string) error {
+	return c.MarkDeviceTritonSetupContext(context.Background(), serial)
+}
+
+// MarkDeviceTritonSetupContext is the context.Context aware version of MarkDeviceTritonSetup
+func (c *Conch) MarkDeviceTritonSetupContext(ctx context.Context, serial string) error {
 	escaped := url.PathEscape(serial)
-	return c.post("/device/"+escaped+"/triton_setup", nil, nil)
+	return c.post(ctx, "/device/"+escaped+"/triton_setup", nil, nil)
 }
 
 // SetDeviceAssetTag sets the asset tag for the provided serial
 func (c *Conch) SetDeviceAssetTag(serial string, tag string) error {
+	return c.SetDeviceAssetTagContext(context.Background(), serial, tag)
+}
+
+// SetDeviceAssetTagContext is the context.Context aware version of SetDeviceAssetTag
+func (c *Conch) SetDeviceAssetTagContext(
+	ctx context.Context,
+	serial string,
+	tag string,
+) error {
 	j := struct {
 		AssetTag string `json:"asset_tag"`
 	}{
@@ -211,29 +273,57 @@ func (c *Conch) SetDeviceAssetTag(serial string, tag string) error {
 	}
 
 	escaped := url.PathEscape(serial)
-	return c.post("/device/"+escaped+"/asset_tag", j, nil)
+	return c.post(ctx, "/device/"+escaped+"/asset_tag", j, nil)
 }
 
 // GetDeviceIPMI retrieves "/device/:serial/interface/impi1/ipaddr"
 func (c *Conch) GetDeviceIPMI(serial string) (string, error) {
+	return c.GetDeviceIPMIContext(context.Background(), serial)
+}
+
+// GetDeviceIPMIContext is the context.Context aware version of GetDeviceIPMI
+func (c *Conch) GetDeviceIPMIContext(ctx context.Context, serial string) (string, error) {
 	j := make(map[string]string)
 
 	escaped := url.PathEscape(serial)
-	if err := c.get("/device/"+escaped+"/interface/ipmi1/ipaddr", &j); err != nil {
+	if err := c.get(ctx, "/device/"+escaped+"/interface/ipmi1/ipaddr", &j); err != nil {
 		return "", err
 	}
 
 	return j["ipaddr"], nil
 }
 
+// GetDevicesByField searches devices on a single field.
+//
+// Deprecated: Use FindDevices, which takes any number of filters.
 func (c *Conch) GetDevicesByField(key string, value string) (d Devices, err error) {
-	escapedKey := url.PathEscape(key)
-	escapedValue := url.PathEscape(value)
-	url := fmt.Sprintf("/device?%s=%s", escapedKey, escapedValue)
-	return d, c.get(url, &d)
+	return c.GetDevicesByFieldContext(context.Background(), key, value)
+}
+
+// GetDevicesByFieldContext is the context.Context aware version of GetDevicesByField
+//
+// Deprecated: Use FindDevicesContext.
+func (c *Conch) GetDevicesByFieldContext(
+	ctx context.Context,
+	key string,
+	value string,
+) (d Devices, err error) {
+	if d, err = c.FindDevicesContext(ctx, NewDeviceQuery().Field(key, value)); err != nil {
+		return nil, err
+	}
+	return d, nil
 }
 
 func (c *Conch) SubmitDeviceReport(serial string, report string) (state ValidationState, err error) {
+	return c.SubmitDeviceReportContext(context.Background(), serial, report)
+}
+
+// SubmitDeviceReportContext is the context.Context aware version of SubmitDeviceReport
+func (c *Conch) SubmitDeviceReportContext(
+	ctx context.Context,
+	serial string,
+	report string,
+) (state ValidationState, err error) {
 	reportReader := bytes.NewReader([]byte(report))
 
 	escaped := url.PathEscape(serial)
@@ -244,23 +334,48 @@ func (c *Conch) SubmitDeviceReport(serial string, report string) (state Validati
 		return state, err
 	}
 
-	_, err = c.httpDo(req, &state)
+	_, err = c.httpDo(ctx, req, &state)
 	return state, err
 }
 
 func (c *Conch) GetDevicePhase(serial string) (string, error) {
+	return c.GetDevicePhaseContext(context.Background(), serial)
+}
+
+// GetDevicePhaseContext is the context.Context aware version of GetDevicePhase
+func (c *Conch) GetDevicePhaseContext(
+	ctx context.Context,
+	serial string,
+) (string, error) {
+	if err := c.require(CapDevicePhases); err != nil {
+		return "", err
+	}
+
 	ret := struct {
 		DeviceID string `json:"id"`
 		Phase    string `json:"phase"`
 	}{}
 
-	return ret.Phase, c.get("/device/"+url.PathEscape(serial)+"/phase", &ret)
+	return ret.Phase, c.get(ctx, "/device/"+url.PathEscape(serial)+"/phase", &ret)
 }
 
 func (c *Conch) SetDevicePhase(serial string, phase string) error {
+	return c.SetDevicePhaseContext(context.Background(), serial, phase)
+}
+
+// SetDevicePhaseContext is the context.Context aware version of SetDevicePhase
+func (c *Conch) SetDevicePhaseContext(
+	ctx context.Context,
+	serial string,
+	phase string,
+) error {
+	if err := c.require(CapDevicePhases); err != nil {
+		return err
+	}
+
 	data := struct {
 		Phase string `json:"phase"`
 	}{phase}
 
-	return c.post("/device/"+url.PathEscape(serial)+"/phase", data, nil)
+	return c.post(ctx, "/device/"+url.PathEscape(serial)+"/phase", data, nil)
 }
@@ -34,7 +34,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Get("/device/" + serial).Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDevice(serial)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Device{ID: serial})
 	})
 
@@ -45,7 +45,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Get("/device/" + serial).Reply(400).JSON(ErrApi)
 
 		ret, err := API.FillInDevice(d)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, d)
 	})
 
@@ -55,7 +55,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Get("/device/" + serial + "/location").Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDeviceLocation(serial)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.DeviceLocation{})
 	})
 
@@ -64,7 +64,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Post("/device/" + serial + "/graduate").Reply(400).JSON(ErrApi)
 
 		err := API.GraduateDevice(serial)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeviceTritonRebootErrors", func(t *testing.T) {
@@ -72,7 +72,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Post("/device/" + serial + "/triton_reboot").Reply(400).JSON(ErrApi)
 
 		err := API.DeviceTritonReboot(serial)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("SetDeviceTritonUUIDErrors", func(t *testing.T) {
@@ -81,7 +81,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Post("/device/" + serial + "/triton_uuid").Reply(400).JSON(ErrApi)
 
 		err := API.SetDeviceTritonUUID(serial, id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("MarkDeviceTritonSetupErrors", func(t *testing.T) {
@@ -89,7 +89,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Post("/device/" + serial + "/triton_setup").Reply(400).JSON(ErrApi)
 
 		err := API.MarkDeviceTritonSetup(serial)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("SetDeviceAssetTagErrors", func(t *testing.T) {
@@ -98,7 +98,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Post("/device/" + serial + "/asset_tag").Reply(400).JSON(ErrApi)
 
 		err := API.SetDeviceAssetTag(serial, tag)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetDevicesByField", func(t *testing.T) {
@@ -108,7 +108,7 @@ func TestDevices(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDevicesByField("hostname", "bar")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, d)
 	})
 
@@ -117,7 +117,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Post("/device/" + serial).Reply(400).JSON(ErrApi)
 
 		ret, err := API.SubmitDeviceReport(serial, "")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.ValidationState{})
 	})
 
@@ -126,7 +126,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Get("/device/" + serial + "/phase").Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetDevicePhase(serial)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, "")
 	})
 
@@ -135,7 +135,7 @@ func TestDevices(t *testing.T) {
 		gock.New(API.BaseURL).Post("/device/" + serial + "/phase").Reply(400).JSON(ErrApi)
 
 		err := API.SetDevicePhase(serial, "production")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 }
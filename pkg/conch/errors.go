@@ -7,7 +7,12 @@
 package conch
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 )
 
 var (
@@ -31,7 +36,8 @@ var (
 	ErrBadInput = errors.New("incomplete data passed to the routine")
 
 	// ErrNotSupported indicates that the API server does not support this
-	// command. This is typically determined via checks on conch.apiVersion
+	// command. This is typically determined via checks on the server's
+	// version. See Capabilities.
 	ErrNotSupported = errors.New("this function is not supported")
 
 	// ErrNotAuthorized indicates that the API server returned a 401
@@ -47,3 +53,165 @@ var (
 
 	ErrMalformedJWT = errors.New("server sent a malformed auth token")
 )
+
+// APIError is returned when the API responds with a non-2xx status code. The
+// legacy sentinels still match via errors.Is: a 401 is ErrNotAuthorized, a 403
+// is ErrForbidden, a 404 is ErrDataNotFound and everything else is
+// ErrHTTPNotOk.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+
+	// RequestID is the request identifier the server sent back, if any. It is
+	// the thing to quote when asking the Conch team about a failure.
+	RequestID string
+
+	// Message is the "error" field of the response body, if the server sent
+	// one
+	Message string
+
+	// Details holds every field of a JSON response body, including Message
+	Details map[string]interface{}
+
+	Body []byte
+}
+
+func newAPIError(req *http.Request, res *http.Response, body []byte) *APIError {
+	aerr := &APIError{
+		StatusCode: res.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Body:       body,
+	}
+
+	aerr.RequestID = res.Header.Get("Request-Id")
+	if aerr.RequestID == "" {
+		aerr.RequestID = res.Header.Get("X-Request-Id")
+	}
+
+	if err := json.Unmarshal(body, &aerr.Details); err == nil {
+		if msg, ok := aerr.Details["error"].(string); ok {
+			aerr.Message = msg
+		}
+	}
+
+	return aerr
+}
+
+// Error keeps the historical error strings: the sentinel text for 401, 403
+// and 404, otherwise the server's message if it sent one.
+func (e *APIError) Error() string {
+	sentinel := e.Unwrap()
+	if sentinel != ErrHTTPNotOk {
+		return sentinel.Error()
+	}
+
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf(
+		"%s %s: HTTP %d %s",
+		e.Method,
+		e.Path,
+		e.StatusCode,
+		http.StatusText(e.StatusCode),
+	)
+}
+
+// Unwrap provides the legacy sentinel error matching the status code
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrNotAuthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrDataNotFound
+	}
+	return ErrHTTPNotOk
+}
+
+// CapabilityError is returned when the API server is known to run a version
+// that lacks the Capability a method needs. It matches ErrNotSupported via
+// errors.Is.
+type CapabilityError struct {
+	Capability Capability
+	APIVersion string
+
+	// Supported is the range of API versions that have the Capability, as
+	// listed in Capabilities
+	Supported string
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf(
+		"%s: %s requires API version %s, the server runs %s",
+		ErrNotSupported,
+		e.Capability,
+		e.Supported,
+		e.APIVersion,
+	)
+}
+
+// Unwrap provides ErrNotSupported
+func (e *CapabilityError) Unwrap() error {
+	return ErrNotSupported
+}
+
+// DecodeError is returned, in strict decoding mode, when a successful API
+// response does not fit the structure we expected. This usually means the API
+// changed a field's type out from under us.
+type DecodeError struct {
+	Method string
+	Path   string
+
+	// Field is the dotted path to the offending value in the response, if
+	// the decoder knows it
+	Field string
+
+	// Offset is where in the body the decoder gave up
+	Offset int64
+
+	// Snippet is the part of the response body around Offset
+	Snippet string
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	msg := fmt.Sprintf("failed to decode response to %s %s", e.Method, e.Path)
+	if e.Field != "" {
+		msg = msg + fmt.Sprintf(" (field '%s')", e.Field)
+	}
+	return fmt.Sprintf("%s: %s, near: %s", msg, e.Err, e.Snippet)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError is returned by the batch calls, like GetDevices, when some of the
+// items could not be fetched. It maps each failed ID to its error. The items
+// that succeeded are returned as usual.
+type BatchError map[string]error
+
+func (e BatchError) Error() string {
+	ids := make([]string, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, id+": "+e[id].Error())
+	}
+
+	return fmt.Sprintf(
+		"%d of the requested items failed: %s",
+		len(e),
+		strings.Join(msgs, "; "),
+	)
+}
@@ -7,6 +7,7 @@
 package conch_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/joyent/conch-shell/pkg/conch"
@@ -21,21 +22,69 @@ func TestErrors(t *testing.T) {
 	url := "/user/me/settings"
 	gock.New(API.BaseURL).Get(url).Reply(404).JSON(ErrApi)
 	_, err := API.GetUserSettings()
-	st.Expect(t, err, conch.ErrDataNotFound)
+	st.Expect(t, errors.Is(err, conch.ErrDataNotFound), true)
 
 	gock.New(API.BaseURL).Get(url).Reply(403).JSON(ErrApi)
 	_, err = API.GetUserSettings()
-	st.Expect(t, err, conch.ErrForbidden)
+	st.Expect(t, errors.Is(err, conch.ErrForbidden), true)
 
 	gock.New(API.BaseURL).Get(url).Reply(401).JSON(ErrApi)
 	_, err = API.GetUserSettings()
-	st.Expect(t, err, conch.ErrNotAuthorized)
+	st.Expect(t, errors.Is(err, conch.ErrNotAuthorized), true)
 
 	gock.New(API.BaseURL).Get(url).Reply(400).JSON(ErrApi)
 	_, err = API.GetUserSettings()
-	st.Expect(t, err, ErrApiUnpacked)
+	st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
+	st.Expect(t, errors.Is(err, conch.ErrHTTPNotOk), true)
 
 	gock.New(API.BaseURL).Get(url).Reply(500).JSON(ErrApi)
 	_, err = API.GetUserSettings()
-	st.Expect(t, err, ErrApiUnpacked)
+	st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
+}
+
+func TestAPIError(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	t.Run("Conflict", func(t *testing.T) {
+		gock.New(API.BaseURL).Post("/rack_role").
+			Reply(409).
+			SetHeader("Request-Id", "abc123").
+			JSON(map[string]interface{}{
+				"error":   "a role with that name already exists",
+				"role_id": "wat",
+			})
+
+		err := API.SaveRackRole(&conch.RackRole{Name: "wat", RackSize: 42})
+
+		var aerr *conch.APIError
+		st.Expect(t, errors.As(err, &aerr), true)
+		st.Expect(t, aerr.StatusCode, 409)
+		st.Expect(t, aerr.Method, "POST")
+		st.Expect(t, aerr.Path, "/rack_role")
+		st.Expect(t, aerr.RequestID, "abc123")
+		st.Expect(t, aerr.Message, "a role with that name already exists")
+		st.Expect(t, aerr.Details["role_id"], "wat")
+		st.Expect(t, err.Error(), "a role with that name already exists")
+	})
+
+	t.Run("NotFoundKeepsSentinelText", func(t *testing.T) {
+		gock.New(API.BaseURL).Get("/rack_role").Reply(404).JSON(ErrApi)
+
+		_, err := API.GetRackRoles()
+		st.Expect(t, err.Error(), conch.ErrDataNotFound.Error())
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
+	})
+
+	t.Run("NoErrorBody", func(t *testing.T) {
+		gock.New(API.BaseURL).Get("/rack_role").Reply(500).BodyString("<html>")
+
+		_, err := API.GetRackRoles()
+
+		var aerr *conch.APIError
+		st.Expect(t, errors.As(err, &aerr), true)
+		st.Expect(t, aerr.Body, []byte("<html>"))
+		st.Expect(t, err.Error(), "GET /rack_role: HTTP 500 Internal Server Error")
+		st.Expect(t, errors.Is(err, conch.ErrHTTPNotOk), true)
+	})
 }
@@ -7,6 +7,7 @@
 package conch
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -21,7 +22,15 @@ import (
 func (c *Conch) GetHardwareProduct(
 	hardwareProductUUID fmt.Stringer,
 ) (hp HardwareProduct, err error) {
-	return hp, c.get(
+	return c.GetHardwareProductContext(context.Background(), hardwareProductUUID)
+}
+
+// GetHardwareProductContext is the context.Context aware version of GetHardwareProduct
+func (c *Conch) GetHardwareProductContext(
+	ctx context.Context,
+	hardwareProductUUID fmt.Stringer,
+) (hp HardwareProduct, err error) {
+	return hp, c.get(ctx,
 		"/hardware_product/"+url.PathEscape(hardwareProductUUID.String()),
 		&hp,
 	)
@@ -30,13 +39,26 @@ func (c *Conch) GetHardwareProduct(
 // GetHardwareProducts fetches a single hardware product via
 // /hardware_product
 func (c *Conch) GetHardwareProducts() ([]HardwareProduct, error) {
+	return c.GetHardwareProductsContext(context.Background())
+}
+
+// GetHardwareProductsContext is the context.Context aware version of GetHardwareProducts
+func (c *Conch) GetHardwareProductsContext(ctx context.Context) ([]HardwareProduct, error) {
 	prods := make([]HardwareProduct, 0)
-	return prods, c.get("/hardware_product", &prods)
+	return prods, c.get(ctx, "/hardware_product", &prods)
 }
 
 // SaveHardwareProduct creates or saves s hardware product, based
 // on the presence of an ID
 func (c *Conch) SaveHardwareProduct(h *HardwareProduct) error {
+	return c.SaveHardwareProductContext(context.Background(), h)
+}
+
+// SaveHardwareProductContext is the context.Context aware version of SaveHardwareProduct
+func (c *Conch) SaveHardwareProductContext(
+	ctx context.Context,
+	h *HardwareProduct,
+) error {
 	if h.Name == "" {
 		return ErrBadInput
 	}
@@ -87,9 +109,9 @@ func (c *Conch) SaveHardwareProduct(h *HardwareProduct) error {
 	}
 
 	if uuid.Equal(h.ID, uuid.UUID{}) {
-		return c.post("/hardware_product", out, &h)
+		return c.post(ctx, "/hardware_product", out, &h)
 	} else {
-		return c.post(
+		return c.post(ctx,
 			"/hardware_product/"+url.PathEscape(h.ID.String()),
 			out,
 			&h,
@@ -100,31 +122,70 @@ func (c *Conch) SaveHardwareProduct(h *HardwareProduct) error {
 // DeleteHardwareProduct deletes a hardware product by marking it as
 // deactivated
 func (c *Conch) DeleteHardwareProduct(hwUUID fmt.Stringer) error {
-	return c.httpDelete("/hardware_product/" + url.PathEscape(hwUUID.String()))
+	return c.DeleteHardwareProductContext(context.Background(), hwUUID)
+}
+
+// DeleteHardwareProductContext is the context.Context aware version of DeleteHardwareProduct
+func (c *Conch) DeleteHardwareProductContext(
+	ctx context.Context,
+	hwUUID fmt.Stringer,
+) error {
+	return c.httpDelete(ctx, "/hardware_product/"+url.PathEscape(hwUUID.String()))
 }
 
 // GetHardwareVendor ...
 func (c *Conch) GetHardwareVendor(name string) (v HardwareVendor, err error) {
-	return v, c.get("/hardware_vendor/"+url.PathEscape(name), &v)
+	return c.GetHardwareVendorContext(context.Background(), name)
+}
+
+// GetHardwareVendorContext is the context.Context aware version of GetHardwareVendor
+func (c *Conch) GetHardwareVendorContext(
+	ctx context.Context,
+	name string,
+) (v HardwareVendor, err error) {
+	return v, c.get(ctx, "/hardware_vendor/"+url.PathEscape(name), &v)
 }
 
 func (c *Conch) GetHardwareVendorByID(id fmt.Stringer) (v HardwareVendor, err error) {
-	return v, c.get("/hardware_vendor/"+url.PathEscape(id.String()), &v)
+	return c.GetHardwareVendorByIDContext(context.Background(), id)
+}
+
+// GetHardwareVendorByIDContext is the context.Context aware version of GetHardwareVendorByID
+func (c *Conch) GetHardwareVendorByIDContext(
+	ctx context.Context,
+	id fmt.Stringer,
+) (v HardwareVendor, err error) {
+	return v, c.get(ctx, "/hardware_vendor/"+url.PathEscape(id.String()), &v)
 }
 
 // GetHardwareVendors ...
 func (c *Conch) GetHardwareVendors() ([]HardwareVendor, error) {
+	return c.GetHardwareVendorsContext(context.Background())
+}
+
+// GetHardwareVendorsContext is the context.Context aware version of GetHardwareVendors
+func (c *Conch) GetHardwareVendorsContext(ctx context.Context) ([]HardwareVendor, error) {
 	vendors := make([]HardwareVendor, 0)
-	return vendors, c.get("/hardware_vendor", &vendors)
+	return vendors, c.get(ctx, "/hardware_vendor", &vendors)
 }
 
 // DeleteHardwareVendor ...
 func (c *Conch) DeleteHardwareVendor(name string) error {
-	return c.httpDelete("/hardware_vendor/" + url.PathEscape(name))
+	return c.DeleteHardwareVendorContext(context.Background(), name)
+}
+
+// DeleteHardwareVendorContext is the context.Context aware version of DeleteHardwareVendor
+func (c *Conch) DeleteHardwareVendorContext(ctx context.Context, name string) error {
+	return c.httpDelete(ctx, "/hardware_vendor/"+url.PathEscape(name))
 }
 
 // SaveHardwareVendor ...
 func (c *Conch) SaveHardwareVendor(v *HardwareVendor) error {
+	return c.SaveHardwareVendorContext(context.Background(), v)
+}
+
+// SaveHardwareVendorContext is the context.Context aware version of SaveHardwareVendor
+func (c *Conch) SaveHardwareVendorContext(ctx context.Context, v *HardwareVendor) error {
 	if v.Name == "" {
 		return ErrBadInput
 	}
@@ -133,7 +194,7 @@ func (c *Conch) SaveHardwareVendor(v *HardwareVendor) error {
 		return ErrBadInput
 	}
 
-	return c.postString(
+	return c.postString(ctx,
 		"/hardware_vendor/"+url.PathEscape(v.Name),
 		strings.NewReader(""),
 		&v,
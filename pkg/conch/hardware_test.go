@@ -25,7 +25,7 @@ func TestHardwareErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetHardwareVendor(name)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.HardwareVendor{})
 	})
 
@@ -33,7 +33,7 @@ func TestHardwareErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/hardware_vendor").Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetHardwareVendors()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.HardwareVendor{})
 	})
 
@@ -42,7 +42,7 @@ func TestHardwareErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.DeleteHardwareVendor(name)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("SaveHardwareVendor", func(t *testing.T) {
@@ -54,14 +54,14 @@ func TestHardwareErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.SaveHardwareVendor(&v)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetHardwareProducts", func(t *testing.T) {
 		gock.New(API.BaseURL).Get("/hardware_product").Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetHardwareProducts()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.HardwareProduct{})
 	})
 
@@ -73,7 +73,7 @@ func TestHardwareErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetHardwareProduct(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.HardwareProduct{})
 	})
 
@@ -96,12 +96,12 @@ func TestHardwareErrors(t *testing.T) {
 		hp.HardwareVendorID = uuid.NewV4()
 
 		err = API.SaveHardwareProduct(&hp)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 		hp.ID = uuid.NewV4()
 
 		err = API.SaveHardwareProduct(&hp)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 		gock.Flush()
 	})
@@ -116,7 +116,7 @@ func TestHardwareErrors(t *testing.T) {
 
 		hv.Name = "test"
 		err = API.SaveHardwareVendor(&hv)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 		hv2 := conch.HardwareVendor{ID: uuid.NewV4()}
 		err = API.SaveHardwareVendor(&hv2)
@@ -130,7 +130,7 @@ func TestHardwareErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.DeleteHardwareProduct(uuid.NewV4())
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteHardwareVendor", func(t *testing.T) {
@@ -138,7 +138,7 @@ func TestHardwareErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.DeleteHardwareVendor("vendor")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 }
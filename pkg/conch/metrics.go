@@ -0,0 +1,266 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives an observation for every HTTP request the client makes.
+// Retries and redirects are observed individually.
+type Metrics interface {
+	// ObserveRequest is called once the response headers arrive or the
+	// request fails. route is the RouteTemplate of the request path.
+	// statusClass is one of "2xx", "3xx", "4xx", "5xx", or "error" if no
+	// response arrived at all, in which case err is set.
+	ObserveRequest(
+		method string,
+		route string,
+		statusClass string,
+		duration time.Duration,
+		err error,
+	)
+}
+
+// StatusClass turns an HTTP status code into "2xx", "4xx" and friends
+func StatusClass(code int) string {
+	if (code < 100) || (code > 599) {
+		return "error"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// metricsMiddleware times every request as it goes out on the wire
+func (c *Conch) metricsMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		res, err := next.RoundTrip(req)
+
+		class := "error"
+		if (err == nil) && (res != nil) {
+			class = StatusClass(res.StatusCode)
+		}
+
+		c.Metrics.ObserveRequest(
+			req.Method,
+			RouteTemplate(req.URL.Path),
+			class,
+			time.Since(start),
+			err,
+		)
+
+		return res, err
+	})
+}
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency
+// histogram kept by PrometheusMetrics
+var DefaultLatencyBuckets = []float64{
+	0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30,
+}
+
+// PrometheusMetrics is a Metrics implementation that keeps counters and a
+// latency histogram in memory and renders them in the Prometheus text
+// exposition format. It is an http.Handler so it can be mounted on a
+// "/metrics" endpoint directly.
+//
+// The following series are kept, all labeled by method, route, and
+// status_class:
+//
+//	conch_client_requests_total
+//	conch_client_errors_total (transport failures and 5xx responses)
+//	conch_client_request_duration_seconds (histogram)
+type PrometheusMetrics struct {
+	// Namespace prefixes every metric name. Defaults to "conch_client".
+	Namespace string
+
+	// Buckets are the histogram's upper bounds, in seconds and in
+	// increasing order. Defaults to DefaultLatencyBuckets.
+	Buckets []float64
+
+	mu     sync.Mutex
+	series map[metricLabels]*metricSeries
+}
+
+type metricLabels struct {
+	method      string
+	route       string
+	statusClass string
+}
+
+type metricSeries struct {
+	requests int64
+	errors   int64
+	sum      float64
+	buckets  []int64
+}
+
+// NewPrometheusMetrics returns a PrometheusMetrics with the default
+// namespace and buckets
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		Namespace: "conch_client",
+		Buckets:   DefaultLatencyBuckets,
+	}
+}
+
+func (p *PrometheusMetrics) namespace() string {
+	if p.Namespace == "" {
+		return "conch_client"
+	}
+	return p.Namespace
+}
+
+func (p *PrometheusMetrics) buckets() []float64 {
+	if len(p.Buckets) == 0 {
+		return DefaultLatencyBuckets
+	}
+	return p.Buckets
+}
+
+// ObserveRequest records a single request
+func (p *PrometheusMetrics) ObserveRequest(
+	method string,
+	route string,
+	statusClass string,
+	duration time.Duration,
+	err error,
+) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.series == nil {
+		p.series = make(map[metricLabels]*metricSeries)
+	}
+
+	buckets := p.buckets()
+	key := metricLabels{method, route, statusClass}
+	s, ok := p.series[key]
+	if !ok {
+		s = &metricSeries{buckets: make([]int64, len(buckets))}
+		p.series[key] = s
+	}
+
+	s.requests++
+	if (statusClass == "error") || (statusClass == "5xx") {
+		s.errors++
+	}
+
+	secs := duration.Seconds()
+	s.sum += secs
+	for i, le := range buckets {
+		if secs <= le {
+			s.buckets[i]++
+		}
+	}
+}
+
+// WriteTo renders every series in the Prometheus text exposition format
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]metricLabels, 0, len(p.series))
+	for k := range p.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.statusClass < b.statusClass
+	})
+
+	cw := &countingWriter{w: w}
+	out := bufio.NewWriter(cw)
+	ns := p.namespace()
+
+	name := ns + "_requests_total"
+	fmt.Fprintf(out, "# HELP %s Total number of requests made to the Conch API.\n", name)
+	fmt.Fprintf(out, "# TYPE %s counter\n", name)
+	for _, k := range keys {
+		fmt.Fprintf(out, "%s{%s} %d\n", name, k.labels(), p.series[k].requests)
+	}
+
+	name = ns + "_errors_total"
+	fmt.Fprintf(out, "# HELP %s Total number of requests that failed outright or returned a 5xx.\n", name)
+	fmt.Fprintf(out, "# TYPE %s counter\n", name)
+	for _, k := range keys {
+		fmt.Fprintf(out, "%s{%s} %d\n", name, k.labels(), p.series[k].errors)
+	}
+
+	name = ns + "_request_duration_seconds"
+	fmt.Fprintf(out, "# HELP %s Latency of requests made to the Conch API.\n", name)
+	fmt.Fprintf(out, "# TYPE %s histogram\n", name)
+	for _, k := range keys {
+		s := p.series[k]
+		labels := k.labels()
+		for i, le := range p.buckets() {
+			if i >= len(s.buckets) {
+				break
+			}
+			fmt.Fprintf(
+				out,
+				"%s_bucket{%s,le=\"%s\"} %d\n",
+				name,
+				labels,
+				strconv.FormatFloat(le, 'g', -1, 64),
+				s.buckets[i],
+			)
+		}
+		fmt.Fprintf(out, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, s.requests)
+		fmt.Fprintf(out, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(s.sum, 'g', -1, 64))
+		fmt.Fprintf(out, "%s_count{%s} %d\n", name, labels, s.requests)
+	}
+
+	err := out.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP serves the metrics to a Prometheus scraper
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = p.WriteTo(w)
+}
+
+func (k metricLabels) labels() string {
+	return fmt.Sprintf(
+		"method=\"%s\",route=\"%s\",status_class=\"%s\"",
+		escapeLabel(k.method),
+		escapeLabel(k.route),
+		escapeLabel(k.statusClass),
+	)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,109 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestMetrics(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	t.Run("Observations", func(t *testing.T) {
+		metrics := conch.NewPrometheusMetrics()
+		api := &conch.Conch{
+			BaseURL:     "http://localhost",
+			HTTPClient:  http.DefaultClient,
+			RetryPolicy: &conch.NoRetries,
+			Metrics:     metrics,
+		}
+
+		gock.New(api.BaseURL).Get("/device/AB123/phase").Reply(200).
+			JSON(map[string]string{"phase": "production"})
+		gock.New(api.BaseURL).Get("/device/CD456/phase").Reply(500).
+			JSON(map[string]string{"error": "oops"})
+		gock.New(api.BaseURL).Get("/device/EF789").Reply(404).
+			JSON(map[string]string{"error": "Not Found"})
+
+		_, err := api.GetDevicePhase("AB123")
+		st.Expect(t, err, nil)
+		_, err = api.GetDevicePhase("CD456")
+		st.Reject(t, err, nil)
+		_, err = api.GetDevice("EF789")
+		st.Reject(t, err, nil)
+
+		buf := new(bytes.Buffer)
+		_, err = metrics.WriteTo(buf)
+		st.Expect(t, err, nil)
+		out := buf.String()
+
+		for _, line := range []string{
+			`conch_client_requests_total{method="GET",route="/device/:id/phase",status_class="2xx"} 1`,
+			`conch_client_requests_total{method="GET",route="/device/:id/phase",status_class="5xx"} 1`,
+			`conch_client_requests_total{method="GET",route="/device/:id",status_class="4xx"} 1`,
+			`conch_client_errors_total{method="GET",route="/device/:id/phase",status_class="5xx"} 1`,
+			`conch_client_errors_total{method="GET",route="/device/:id",status_class="4xx"} 0`,
+			`conch_client_request_duration_seconds_count{method="GET",route="/device/:id/phase",status_class="2xx"} 1`,
+			`conch_client_request_duration_seconds_bucket{method="GET",route="/device/:id/phase",status_class="2xx",le="+Inf"} 1`,
+			"# TYPE conch_client_request_duration_seconds histogram",
+		} {
+			if !strings.Contains(out, line+"\n") {
+				t.Errorf("missing %q in:\n%s", line, out)
+			}
+		}
+	})
+
+	t.Run("TransportErrors", func(t *testing.T) {
+		metrics := conch.NewPrometheusMetrics()
+		api := &conch.Conch{
+			BaseURL:     "http://localhost",
+			HTTPClient:  http.DefaultClient,
+			RetryPolicy: &conch.NoRetries,
+			Metrics:     metrics,
+		}
+		gock.New(api.BaseURL).Get("/version").ReplyError(errors.New("reset"))
+
+		_, err := api.GetVersion()
+		st.Reject(t, err, nil)
+
+		rec := httptest.NewRecorder()
+		metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		st.Expect(t, strings.Contains(
+			rec.Body.String(),
+			`conch_client_errors_total{method="GET",route="/version",status_class="error"} 1`,
+		), true)
+	})
+
+	t.Run("Buckets", func(t *testing.T) {
+		metrics := &conch.PrometheusMetrics{
+			Namespace: "test",
+			Buckets:   []float64{0.1, 1},
+		}
+		metrics.ObserveRequest("GET", "/version", "2xx", 500*time.Millisecond, nil)
+
+		buf := new(bytes.Buffer)
+		_, err := metrics.WriteTo(buf)
+		st.Expect(t, err, nil)
+		out := buf.String()
+
+		labels := `method="GET",route="/version",status_class="2xx"`
+		st.Expect(t, strings.Contains(out, `test_request_duration_seconds_bucket{`+labels+`,le="0.1"} 0`), true)
+		st.Expect(t, strings.Contains(out, `test_request_duration_seconds_bucket{`+labels+`,le="1"} 1`), true)
+		st.Expect(t, strings.Contains(out, `test_request_duration_seconds_sum{`+labels+`} 0.5`), true)
+	})
+}
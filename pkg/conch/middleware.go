@@ -0,0 +1,158 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Middleware wraps the http.RoundTripper that carries every request to the
+// API. It may alter the request on the way out, the response on the way
+// back, or skip the network altogether.
+//
+// Middleware run once per attempt, so a request that is retried or
+// redirected passes through the chain again. Per the http.RoundTripper
+// contract, a Middleware must not modify the request it is handed. Use
+// CloneRequest first.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc turns a function into an http.RoundTripper, which makes
+// most Middleware a one liner
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// CloneRequest returns a shallow copy of the request with its own headers,
+// safe for a Middleware to modify
+func CloneRequest(req *http.Request) *http.Request {
+	r := req.WithContext(req.Context())
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string{}, v...)
+	}
+	return r
+}
+
+// HeaderMiddleware sets a static header on every request that doesn't
+// already carry it
+func HeaderMiddleware(name string, value string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(name) != "" {
+				return next.RoundTrip(req)
+			}
+			r := CloneRequest(req)
+			r.Header.Set(name, value)
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+// authMiddleware adds the API token or JWT to requests headed for the API.
+//
+// Since the chain is run for every hop, this also covers redirects.
+// Previously, the client's CheckRedirect copied the Authorization header
+// from the original request because go wasn't doing it on its own. Here,
+// the header is only ever sent to the API's host so a redirect elsewhere
+// can't leak it.
+func (c *Conch) authMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		auth := c.authorization()
+		if (auth == "") || !c.isAPIHost(req.URL) {
+			return next.RoundTrip(req)
+		}
+
+		r := CloneRequest(req)
+		r.Header.Set("Authorization", auth)
+		return next.RoundTrip(r)
+	})
+}
+
+// authorization returns the value of the Authorization header, preferring
+// the API token over the JWT
+func (c *Conch) authorization() string {
+	if c.Token != "" {
+		return "Bearer " + c.Token
+	}
+	if jwt := c.CurrentJWT(); (jwt.Token != "") && (jwt.Signature != "") {
+		return "Bearer " + jwt.FullToken()
+	}
+	return ""
+}
+
+// traceMiddleware logs the headers as they go out on the wire, after every
+// other middleware had its way with them
+func (c *Conch) traceMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		c.traceLog(
+			"request headers",
+			"method", req.Method,
+			"url", req.URL.String(),
+			"headers", redactHeaders(req.Header),
+		)
+		return next.RoundTrip(req)
+	})
+}
+
+func (c *Conch) isAPIHost(u *url.URL) bool {
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return false
+	}
+	return u.Host == base.Host
+}
+
+// transport builds the RoundTripper chain around the base transport. The
+// cache is outermost, so a hit never touches anything else. Auth comes next,
+// so the user's Middleware see requests as they will hit the wire. Tracing
+// and metrics are innermost, so they reflect what the user's Middleware did.
+// The recorder sits right on top of the base transport, which a Cassette
+// replaces.
+func (c *Conch) transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		// http.DefaultTransport is looked up for every request, as an
+		// http.Client with no Transport would, since it may be swapped out
+		base = RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return http.DefaultTransport.RoundTrip(req)
+		})
+	}
+	if c.Cassette != nil {
+		base = c.Cassette
+	}
+
+	rt := base
+	if c.Recorder != nil {
+		rt = c.recordMiddleware(rt)
+	}
+	if c.Metrics != nil {
+		rt = c.metricsMiddleware(rt)
+	}
+	rt = c.traceMiddleware(rt)
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		rt = c.Middleware[i](rt)
+	}
+
+	rt = c.authMiddleware(rt)
+
+	if c.Cache != nil {
+		rt = c.cacheMiddleware(rt)
+	}
+
+	return rt
+}
+
+// client returns the copy of HTTPClient whose transport runs the middleware
+// chain. setup builds it once. It shares the cookie jar and connection pool
+// with the original.
+func (c *Conch) client() *http.Client {
+	c.setup()
+	return c.httpClient
+}
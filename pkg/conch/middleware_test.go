@@ -0,0 +1,147 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestMiddleware(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	newAPI := func(mw ...conch.Middleware) *conch.Conch {
+		return &conch.Conch{
+			BaseURL:     "http://localhost",
+			HTTPClient:  http.DefaultClient,
+			RetryPolicy: &conch.NoRetries,
+			Middleware:  mw,
+		}
+	}
+
+	t.Run("Headers", func(t *testing.T) {
+		api := newAPI(conch.HeaderMiddleware("X-Request-Id", "abc123"))
+
+		gock.New(api.BaseURL).Get("/version").
+			MatchHeader("X-Request-Id", "abc123").
+			Reply(200).JSON(map[string]string{"version": "v2.20.0"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("Order", func(t *testing.T) {
+		order := make([]string, 0)
+		mark := func(name string) conch.Middleware {
+			return func(next http.RoundTripper) http.RoundTripper {
+				return conch.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					order = append(order, name)
+					return next.RoundTrip(req)
+				})
+			}
+		}
+		api := newAPI(mark("first"), mark("second"))
+
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "v2.20.0"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, order, []string{"first", "second"})
+	})
+
+	t.Run("SeesAuthorization", func(t *testing.T) {
+		seen := ""
+		api := newAPI(func(next http.RoundTripper) http.RoundTripper {
+			return conch.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				seen = req.Header.Get("Authorization")
+				return next.RoundTrip(req)
+			})
+		})
+		api.Token = "token"
+
+		gock.New(api.BaseURL).Get("/version").
+			MatchHeader("Authorization", "Bearer token").
+			Reply(200).JSON(map[string]string{"version": "v2.20.0"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, seen, "Bearer token")
+	})
+
+	t.Run("ShortCircuit", func(t *testing.T) {
+		boom := errors.New("injected fault")
+		api := newAPI(func(next http.RoundTripper) http.RoundTripper {
+			return conch.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, boom
+			})
+		})
+
+		_, err := api.GetVersion()
+		st.Expect(t, errors.Is(err, boom), true)
+	})
+
+	t.Run("RawRequests", func(t *testing.T) {
+		api := newAPI(conch.HeaderMiddleware("X-Audit", "yes"))
+
+		gock.New(api.BaseURL).Get("/version").
+			MatchHeader("X-Audit", "yes").
+			Reply(200).JSON(map[string]string{"version": "v2.20.0"})
+
+		res, err := api.RawGet("/version")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, 200)
+	})
+
+	t.Run("BuiltOnce", func(t *testing.T) {
+		built := 0
+		api := newAPI(func(next http.RoundTripper) http.RoundTripper {
+			built++
+			return next
+		})
+
+		gock.New(api.BaseURL).Get("/version").Times(2).Reply(200).
+			JSON(map[string]string{"version": "v2.20.0"})
+
+		for i := 0; i < 2; i++ {
+			_, err := api.GetVersion()
+			st.Expect(t, err, nil)
+		}
+		st.Expect(t, built, 1)
+	})
+
+	t.Run("AuthStaysOnAPIHost", func(t *testing.T) {
+		seen := make(map[string]string)
+		api := newAPI(func(next http.RoundTripper) http.RoundTripper {
+			return conch.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				seen[req.URL.Host] = req.Header.Get("Authorization")
+				return next.RoundTrip(req)
+			})
+		})
+		api.Token = "token"
+
+		gock.New(api.BaseURL).Get("/version").
+			Reply(302).SetHeader("Location", "http://elsewhere/version")
+
+		gock.New("http://elsewhere").Get("/version").
+			Reply(200).JSON(map[string]string{"version": "v2.20.0"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, seen, map[string]string{
+			"localhost": "Bearer token",
+			"elsewhere": "",
+		})
+	})
+}
@@ -7,22 +7,38 @@
 package conch
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/joyent/conch-shell/pkg/conch/uuid"
 )
 
 func (c *Conch) GetRacks() ([]Rack, error) {
+	return c.GetRacksContext(context.Background())
+}
+
+// GetRacksContext is the context.Context aware version of GetRacks
+func (c *Conch) GetRacksContext(ctx context.Context) ([]Rack, error) {
 	r := make([]Rack, 0)
-	return r, c.get("/rack", &r)
+	return r, c.get(ctx, "/rack", &r)
 }
 
 func (c *Conch) GetRack(id uuid.UUID) (r Rack, err error) {
+	return c.GetRackContext(context.Background(), id)
+}
+
+// GetRackContext is the context.Context aware version of GetRack
+func (c *Conch) GetRackContext(ctx context.Context, id uuid.UUID) (r Rack, err error) {
 	escaped := url.PathEscape(id.String())
-	return r, c.get("/rack/"+escaped, &r)
+	return r, c.get(ctx, "/rack/"+escaped, &r)
 }
 
 func (c *Conch) SaveRack(r *Rack) error {
+	return c.SaveRackContext(context.Background(), r)
+}
+
+// SaveRackContext is the context.Context aware version of SaveRack
+func (c *Conch) SaveRackContext(ctx context.Context, r *Rack) error {
 	if uuid.Equal(r.DatacenterRoomID, uuid.UUID{}) {
 		return ErrBadInput
 	}
@@ -48,26 +64,53 @@ func (c *Conch) SaveRack(r *Rack) error {
 	}
 
 	if uuid.Equal(r.ID, uuid.UUID{}) {
-		return c.post("/rack", j, &r)
+		return c.post(ctx, "/rack", j, &r)
 	} else {
 		escaped := url.PathEscape(r.ID.String())
-		return c.post("/rack/"+escaped, j, &r)
+		return c.post(ctx, "/rack/"+escaped, j, &r)
 	}
 
 }
 
 func (c *Conch) DeleteRack(id uuid.UUID) error {
+	return c.DeleteRackContext(context.Background(), id)
+}
+
+// DeleteRackContext is the context.Context aware version of DeleteRack
+func (c *Conch) DeleteRackContext(ctx context.Context, id uuid.UUID) error {
 	escaped := url.PathEscape(id.String())
-	return c.httpDelete("/rack/" + escaped)
+	return c.httpDelete(ctx, "/rack/"+escaped)
 }
 
 // GetRackLayout fetches the layout entries for a rack in the global domain
 func (c *Conch) GetRackLayout(r Rack) (RackLayoutSlots, error) {
+	return c.GetRackLayoutContext(context.Background(), r)
+}
+
+// GetRackLayoutContext is the context.Context aware version of GetRackLayout
+func (c *Conch) GetRackLayoutContext(
+	ctx context.Context,
+	r Rack,
+) (RackLayoutSlots, error) {
 	rs := make([]RackLayoutSlot, 0)
 	escaped := url.PathEscape(r.ID.String())
-	return rs, c.get("/rack/"+escaped+"/layouts", &rs)
+	return rs, c.get(ctx, "/rack/"+escaped+"/layouts", &rs)
 }
 func (c *Conch) SetRackPhase(id uuid.UUID, phase string, withDevices bool) error {
+	return c.SetRackPhaseContext(context.Background(), id, phase, withDevices)
+}
+
+// SetRackPhaseContext is the context.Context aware version of SetRackPhase
+func (c *Conch) SetRackPhaseContext(
+	ctx context.Context,
+	id uuid.UUID,
+	phase string,
+	withDevices bool,
+) error {
+	if err := c.require(CapRackPhases); err != nil {
+		return err
+	}
+
 	data := struct {
 		Phase string `json:"phase"`
 	}{phase}
@@ -78,18 +121,39 @@ func (c *Conch) SetRackPhase(id uuid.UUID, phase string, withDevices bool) error
 		url = url + "?rack_only=1"
 	}
 
-	return c.post(url, data, nil)
+	return c.post(ctx, url, data, nil)
 }
 
 func (c *Conch) GetRackPhase(id uuid.UUID) (string, error) {
-	r, err := c.GetRack(id)
+	return c.GetRackPhaseContext(context.Background(), id)
+}
+
+// GetRackPhaseContext is the context.Context aware version of GetRackPhase
+func (c *Conch) GetRackPhaseContext(ctx context.Context, id uuid.UUID) (string, error) {
+	if err := c.require(CapRackPhases); err != nil {
+		return "", err
+	}
+
+	r, err := c.GetRackContext(ctx, id)
 	return r.Phase, err
 }
 
 func (c *Conch) GetRackAssignments(rackID uuid.UUID) (ResponseRackAssignments, error) {
+	return c.GetRackAssignmentsContext(context.Background(), rackID)
+}
+
+// GetRackAssignmentsContext is the context.Context aware version of GetRackAssignments
+func (c *Conch) GetRackAssignmentsContext(
+	ctx context.Context,
+	rackID uuid.UUID,
+) (ResponseRackAssignments, error) {
+	if err := c.require(CapRackAssignments); err != nil {
+		return nil, err
+	}
+
 	assignments := make(ResponseRackAssignments, 0)
 
-	return assignments, c.get(
+	return assignments, c.get(ctx,
 		"/rack/"+
 			url.PathEscape(rackID.String())+
 			"/assignment",
@@ -101,7 +165,20 @@ func (c *Conch) AssignDevicesToRackSlots(
 	rackID uuid.UUID,
 	assignments RequestRackAssignmentUpdates,
 ) error {
-	return c.post(
+	return c.AssignDevicesToRackSlotsContext(context.Background(), rackID, assignments)
+}
+
+// AssignDevicesToRackSlotsContext is the context.Context aware version of AssignDevicesToRackSlots
+func (c *Conch) AssignDevicesToRackSlotsContext(
+	ctx context.Context,
+	rackID uuid.UUID,
+	assignments RequestRackAssignmentUpdates,
+) error {
+	if err := c.require(CapRackAssignments); err != nil {
+		return err
+	}
+
+	return c.post(ctx,
 		"/rack/"+
 			url.PathEscape(rackID.String())+
 			"/assignment",
@@ -114,7 +191,20 @@ func (c *Conch) DeleteDevicesFromRackSlots(
 	rackID uuid.UUID,
 	deletions RequestRackAssignmentDeletes,
 ) error {
-	return c.httpDeleteWithPayload(
+	return c.DeleteDevicesFromRackSlotsContext(context.Background(), rackID, deletions)
+}
+
+// DeleteDevicesFromRackSlotsContext is the context.Context aware version of DeleteDevicesFromRackSlots
+func (c *Conch) DeleteDevicesFromRackSlotsContext(
+	ctx context.Context,
+	rackID uuid.UUID,
+	deletions RequestRackAssignmentDeletes,
+) error {
+	if err := c.require(CapRackAssignments); err != nil {
+		return err
+	}
+
+	return c.httpDeleteWithPayload(ctx,
 		"/rack/"+
 			url.PathEscape(rackID.String())+
 			"/assignment",
@@ -7,23 +7,42 @@
 package conch
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/joyent/conch-shell/pkg/conch/uuid"
 )
 
 func (c *Conch) GetRackLayoutSlots() (RackLayoutSlots, error) {
+	return c.GetRackLayoutSlotsContext(context.Background())
+}
+
+// GetRackLayoutSlotsContext is the context.Context aware version of GetRackLayoutSlots
+func (c *Conch) GetRackLayoutSlotsContext(ctx context.Context) (RackLayoutSlots, error) {
 	r := make([]RackLayoutSlot, 0)
-	return r, c.get("/layout", &r)
+	return r, c.get(ctx, "/layout", &r)
 }
 
 func (c *Conch) GetRackLayoutSlot(id uuid.UUID) (*RackLayoutSlot, error) {
+	return c.GetRackLayoutSlotContext(context.Background(), id)
+}
+
+// GetRackLayoutSlotContext is the context.Context aware version of GetRackLayoutSlot
+func (c *Conch) GetRackLayoutSlotContext(
+	ctx context.Context,
+	id uuid.UUID,
+) (*RackLayoutSlot, error) {
 	r := &RackLayoutSlot{}
 	escaped := url.PathEscape(id.String())
-	return r, c.get("/layout/"+escaped, &r)
+	return r, c.get(ctx, "/layout/"+escaped, &r)
 }
 
 func (c *Conch) SaveRackLayoutSlot(r *RackLayoutSlot) error {
+	return c.SaveRackLayoutSlotContext(context.Background(), r)
+}
+
+// SaveRackLayoutSlotContext is the context.Context aware version of SaveRackLayoutSlot
+func (c *Conch) SaveRackLayoutSlotContext(ctx context.Context, r *RackLayoutSlot) error {
 	if uuid.Equal(r.RackID, uuid.UUID{}) {
 		return ErrBadInput
 	}
@@ -45,14 +64,19 @@ func (c *Conch) SaveRackLayoutSlot(r *RackLayoutSlot) error {
 	}
 
 	if uuid.Equal(r.ID, uuid.UUID{}) {
-		return c.post("/layout", j, &r)
+		return c.post(ctx, "/layout", j, &r)
 	} else {
 		escaped := url.PathEscape(r.ID.String())
-		return c.post("/layout/"+escaped, j, &r)
+		return c.post(ctx, "/layout/"+escaped, j, &r)
 	}
 }
 
 func (c *Conch) DeleteRackLayoutSlot(id uuid.UUID) error {
+	return c.DeleteRackLayoutSlotContext(context.Background(), id)
+}
+
+// DeleteRackLayoutSlotContext is the context.Context aware version of DeleteRackLayoutSlot
+func (c *Conch) DeleteRackLayoutSlotContext(ctx context.Context, id uuid.UUID) error {
 	escaped := url.PathEscape(id.String())
-	return c.httpDelete("/layout/" + escaped)
+	return c.httpDelete(ctx, "/layout/"+escaped)
 }
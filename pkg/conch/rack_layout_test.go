@@ -23,7 +23,7 @@ func TestRackLayoutSlotErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/layout").Persist().Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRackLayoutSlots()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.RackLayoutSlots{})
 	})
 
@@ -33,7 +33,7 @@ func TestRackLayoutSlotErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/layout/" + id.String()).Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRackLayoutSlot(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, &conch.RackLayoutSlot{})
 	})
 
@@ -47,7 +47,7 @@ func TestRackLayoutSlotErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/layout").Reply(400).JSON(ErrApi)
 
 		err := API.SaveRackLayoutSlot(&r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("UpdateRackLayoutSlot", func(t *testing.T) {
@@ -62,7 +62,7 @@ func TestRackLayoutSlotErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/layout/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.SaveRackLayoutSlot(&r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteRackLayoutSlot", func(t *testing.T) {
@@ -71,7 +71,7 @@ func TestRackLayoutSlotErrors(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/layout/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.DeleteRackLayoutSlot(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 }
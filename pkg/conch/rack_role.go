@@ -7,21 +7,40 @@
 package conch
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/joyent/conch-shell/pkg/conch/uuid"
 )
 
 func (c *Conch) GetRackRoles() ([]RackRole, error) {
+	return c.GetRackRolesContext(context.Background())
+}
+
+// GetRackRolesContext is the context.Context aware version of GetRackRoles
+func (c *Conch) GetRackRolesContext(ctx context.Context) ([]RackRole, error) {
 	r := make([]RackRole, 0)
-	return r, c.get("/rack_role", &r)
+	return r, c.get(ctx, "/rack_role", &r)
 }
 
 func (c *Conch) GetRackRole(id uuid.UUID) (r RackRole, err error) {
-	return r, c.get("/rack_role/"+url.PathEscape(id.String()), &r)
+	return c.GetRackRoleContext(context.Background(), id)
+}
+
+// GetRackRoleContext is the context.Context aware version of GetRackRole
+func (c *Conch) GetRackRoleContext(
+	ctx context.Context,
+	id uuid.UUID,
+) (r RackRole, err error) {
+	return r, c.get(ctx, "/rack_role/"+url.PathEscape(id.String()), &r)
 }
 
 func (c *Conch) SaveRackRole(r *RackRole) error {
+	return c.SaveRackRoleContext(context.Background(), r)
+}
+
+// SaveRackRoleContext is the context.Context aware version of SaveRackRole
+func (c *Conch) SaveRackRoleContext(ctx context.Context, r *RackRole) error {
 	if r.Name == "" {
 		return ErrBadInput
 	}
@@ -39,13 +58,18 @@ func (c *Conch) SaveRackRole(r *RackRole) error {
 	}
 
 	if uuid.Equal(r.ID, uuid.UUID{}) {
-		return c.post("/rack_role", j, &r)
+		return c.post(ctx, "/rack_role", j, &r)
 	} else {
-		return c.post("/rack_role/"+url.PathEscape(r.ID.String()), j, &r)
+		return c.post(ctx, "/rack_role/"+url.PathEscape(r.ID.String()), j, &r)
 	}
 
 }
 
 func (c *Conch) DeleteRackRole(id uuid.UUID) error {
-	return c.httpDelete("/rack_role/" + url.PathEscape(id.String()))
+	return c.DeleteRackRoleContext(context.Background(), id)
+}
+
+// DeleteRackRoleContext is the context.Context aware version of DeleteRackRole
+func (c *Conch) DeleteRackRoleContext(ctx context.Context, id uuid.UUID) error {
+	return c.httpDelete(ctx, "/rack_role/"+url.PathEscape(id.String()))
 }
@@ -25,7 +25,7 @@ func TestRackRoleErrors(t *testing.T) {
 		defer gock.Flush()
 
 		ret, err := API.GetRackRoles()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.RackRole{})
 	})
 
@@ -35,7 +35,7 @@ func TestRackRoleErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/rack_role/" + id.String()).Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRackRole(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.RackRole{})
 	})
 
@@ -48,7 +48,7 @@ func TestRackRoleErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/rack_role").Reply(400).JSON(ErrApi)
 
 		err := API.SaveRackRole(&r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("UpdateRackRole", func(t *testing.T) {
@@ -62,7 +62,7 @@ func TestRackRoleErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/rack_role/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.SaveRackRole(&r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteRackRole", func(t *testing.T) {
@@ -71,7 +71,7 @@ func TestRackRoleErrors(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/rack_role/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.DeleteRackRole(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 }
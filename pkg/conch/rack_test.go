@@ -23,7 +23,7 @@ func TestRackErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/rack").Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRacks()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.Rack{})
 	})
 
@@ -33,7 +33,7 @@ func TestRackErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/rack/" + id.String()).Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRack(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Rack{})
 	})
 
@@ -47,7 +47,7 @@ func TestRackErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/rack").Reply(400).JSON(ErrApi)
 
 		err := API.SaveRack(&r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("UpdateRack", func(t *testing.T) {
@@ -62,7 +62,7 @@ func TestRackErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/rack/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.SaveRack(&r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteRack", func(t *testing.T) {
@@ -71,7 +71,7 @@ func TestRackErrors(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/rack/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.DeleteRack(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetRackLayout", func(t *testing.T) {
@@ -87,7 +87,7 @@ func TestRackErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRackLayout(r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.RackLayoutSlots{})
 	})
 
@@ -98,13 +98,13 @@ func TestRackErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.SetRackPhase(id, "wat", true)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 		gock.New(API.BaseURL).Post("/rack/"+id.String()+"/phase").
 			MatchParam("rack_only", "1").Reply(400).JSON(ErrApi)
 
 		err = API.SetRackPhase(id, "wat", false)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetRackPhase", func(t *testing.T) {
@@ -113,7 +113,7 @@ func TestRackErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/rack/" + id.String()).Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRackPhase(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, "")
 	})
 
@@ -124,7 +124,7 @@ func TestRackErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRackAssignments(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.ResponseRackAssignments{})
 
 	})
@@ -139,7 +139,7 @@ func TestRackErrors(t *testing.T) {
 			rackID,
 			make(conch.RequestRackAssignmentUpdates, 0),
 		)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 	})
 
@@ -153,7 +153,7 @@ func TestRackErrors(t *testing.T) {
 			rackID,
 			make(conch.RequestRackAssignmentDeletes, 0),
 		)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 	})
 }
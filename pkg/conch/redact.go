@@ -0,0 +1,117 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Redacted replaces credentials in anything the library writes out
+const Redacted = "--REDACTED--"
+
+// sensitiveHeaders carry credentials, be it the API token, the JWT, or the
+// JWT's signature cookie
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// sensitiveFields are JSON keys whose values are credentials. Matching is
+// case insensitive.
+var sensitiveFields = []string{
+	"password",
+	"token",
+	"jwt_token",
+}
+
+// redactHeaders returns a copy of the headers with every credential replaced
+func redactHeaders(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		out[k] = append([]string{}, v...)
+	}
+
+	for _, k := range sensitiveHeaders {
+		if _, ok := out[k]; !ok {
+			continue
+		}
+		vals := out[k]
+		for i, v := range vals {
+			// Leave the auth scheme in place. "Bearer" vs "Basic" is
+			// useful when debugging.
+			if bits := strings.SplitN(v, " ", 2); (k == "Authorization") && (len(bits) == 2) {
+				vals[i] = bits[0] + " " + Redacted
+			} else {
+				vals[i] = Redacted
+			}
+		}
+	}
+
+	return out
+}
+
+// redactBody blanks out credentials in a JSON body. Bodies that aren't JSON
+// come back as is since the API only speaks JSON.
+func redactBody(body []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// redactData produces a version of any structure that is safe to log, by way
+// of its JSON representation
+func redactData(data interface{}) interface{} {
+	j, err := json.Marshal(data)
+	if err != nil {
+		return Redacted
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(j, &v); err != nil {
+		return Redacted
+	}
+	return redactValue(v)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if isSensitiveField(k) {
+				t[k] = Redacted
+			} else {
+				t[k] = redactValue(val)
+			}
+		}
+		return t
+	case []interface{}:
+		for i, val := range t {
+			t[i] = redactValue(val)
+		}
+		return t
+	}
+	return v
+}
+
+func isSensitiveField(name string) bool {
+	for _, f := range sensitiveFields {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
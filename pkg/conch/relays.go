@@ -7,6 +7,7 @@
 package conch
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -16,6 +17,15 @@ import (
 func (c *Conch) GetActiveWorkspaceRelays(
 	workspaceUUID fmt.Stringer,
 	minutes int,
+) ([]WorkspaceRelay, error) {
+	return c.GetActiveWorkspaceRelaysContext(context.Background(), workspaceUUID, minutes)
+}
+
+// GetActiveWorkspaceRelaysContext is the context.Context aware version of GetActiveWorkspaceRelays
+func (c *Conch) GetActiveWorkspaceRelaysContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+	minutes int,
 ) ([]WorkspaceRelay, error) {
 	if minutes == 0 {
 		minutes = 5
@@ -28,15 +38,23 @@ func (c *Conch) GetActiveWorkspaceRelays(
 		url.PathEscape(strconv.Itoa(minutes)),
 	)
 
-	return relays, c.get(url, &relays)
+	return relays, c.get(ctx, url, &relays)
 }
 
 // GetWorkspaceRelays returns all Relays associated with the given workspace
 func (c *Conch) GetWorkspaceRelays(workspaceUUID fmt.Stringer) (WorkspaceRelays, error) {
+	return c.GetWorkspaceRelaysContext(context.Background(), workspaceUUID)
+}
+
+// GetWorkspaceRelaysContext is the context.Context aware version of GetWorkspaceRelays
+func (c *Conch) GetWorkspaceRelaysContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+) (WorkspaceRelays, error) {
 	relays := make([]WorkspaceRelay, 0)
 
 	url := "/workspace/" + url.PathEscape(workspaceUUID.String()) + "/relay"
-	return relays, c.get(url, &relays)
+	return relays, c.get(ctx, url, &relays)
 }
 
 // GetWorkspaceRelayDevices ...
@@ -44,6 +62,15 @@ func (c *Conch) GetWorkspaceRelayDevices(
 	workspaceUUID fmt.Stringer,
 	relayName string,
 ) ([]Device, error) {
+	return c.GetWorkspaceRelayDevicesContext(context.Background(), workspaceUUID, relayName)
+}
+
+// GetWorkspaceRelayDevicesContext is the context.Context aware version of GetWorkspaceRelayDevices
+func (c *Conch) GetWorkspaceRelayDevicesContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+	relayName string,
+) ([]Device, error) {
 
 	devices := make([]Device, 0)
 	url := fmt.Sprintf("/workspace/%s/relay/%s/device",
@@ -51,13 +78,18 @@ func (c *Conch) GetWorkspaceRelayDevices(
 		url.PathEscape(relayName),
 	)
 
-	return devices, c.get(url, &devices)
+	return devices, c.get(ctx, url, &devices)
 }
 
 // RegisterRelay registers/updates a Relay via /relay/:serial/register
 // If the provided relay does not have an IP, SSHPort, and Version, ErrBadInput
 // will be returned
 func (c *Conch) RegisterRelay(r WorkspaceRelay) error {
+	return c.RegisterRelayContext(context.Background(), r)
+}
+
+// RegisterRelayContext is the context.Context aware version of RegisterRelay
+func (c *Conch) RegisterRelayContext(ctx context.Context, r WorkspaceRelay) error {
 	if (r.ID == "") || (r.SSHPort == 0) || (r.Version == "") {
 		return ErrBadInput
 	}
@@ -74,7 +106,7 @@ func (c *Conch) RegisterRelay(r WorkspaceRelay) error {
 		r.Version,
 	}
 
-	return c.post(
+	return c.post(ctx,
 		"/relay/"+url.PathEscape(r.ID)+"/register",
 		d,
 		nil,
@@ -84,6 +116,11 @@ func (c *Conch) RegisterRelay(r WorkspaceRelay) error {
 // GetAllRelays uses the /relay endpoint to get a list of all
 // relays, but without their assigned devices.
 func (c *Conch) GetAllRelays() (WorkspaceRelays, error) {
+	return c.GetAllRelaysContext(context.Background())
+}
+
+// GetAllRelaysContext is the context.Context aware version of GetAllRelays
+func (c *Conch) GetAllRelaysContext(ctx context.Context) (WorkspaceRelays, error) {
 	relays := make([]WorkspaceRelay, 0)
-	return relays, c.get("/relay?no_devices=1", &relays)
+	return relays, c.get(ctx, "/relay?no_devices=1", &relays)
 }
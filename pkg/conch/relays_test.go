@@ -26,7 +26,7 @@ func TestRelayErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetWorkspaceRelays(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.WorkspaceRelays{})
 	})
 
@@ -38,7 +38,7 @@ func TestRelayErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.RegisterRelay(r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetAllRelays", func(t *testing.T) {
@@ -46,7 +46,7 @@ func TestRelayErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetAllRelays()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.WorkspaceRelays{})
 	})
 
@@ -0,0 +1,236 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides if, when, and how often a failed request is tried
+// again. Both transport failures (dial timeouts, resets) and the listed HTTP
+// status codes count as failures.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. A
+	// value of 1 or less disables retries entirely.
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry. Every retry after that
+	// waits twice as long as the one before, never more than MaxBackoff.
+	// If the server asks, via Retry-After, to wait longer than MaxBackoff,
+	// the request is not retried at all.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction, between 0 and 1, of each wait that is
+	// randomized so that a fleet of clients doesn't retry in lockstep
+	Jitter float64
+
+	// StatusCodes lists the HTTP response codes that are worth another try
+	StatusCodes []int
+
+	// Methods lists the HTTP methods that may be retried. Only idempotent
+	// methods are retried by default. Add "POST" or "DELETE" to opt in for
+	// those, knowing that the server may then see the request twice.
+	Methods []string
+}
+
+// DefaultRetryPolicy is used by any Conch that does not set its own
+// RetryPolicy. It retries GETs a couple times when the load balancer or the
+// network hiccups.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+	Jitter:      0.2,
+	StatusCodes: []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+	Methods: []string{http.MethodGet, http.MethodHead},
+}
+
+// NoRetries is a RetryPolicy that makes exactly one attempt per request
+var NoRetries = RetryPolicy{MaxAttempts: 1}
+
+// doWithRetries executes the request, trying again as often as the
+// RetryPolicy allows. The response body is read in full and closed before
+// returning.
+func (c *Conch) doWithRetries(
+	ctx context.Context,
+	req *http.Request,
+) (*http.Response, []byte, error) {
+	policy := c.retryPolicy()
+
+	for attempt := 1; ; attempt++ {
+		res, body, err := c.doOnce(req)
+
+		if (attempt >= policy.MaxAttempts) || !policy.retryable(req, res, err) {
+			return res, body, err
+		}
+
+		wait, ok := policy.backoff(attempt, res)
+		if !ok {
+			c.debugLog(
+				"not retrying request",
+				"method", req.Method,
+				"url", req.URL.String(),
+				"reason", "Retry-After exceeds the maximum backoff",
+			)
+			return res, body, err
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = fmt.Sprintf("HTTP %d", res.StatusCode)
+		}
+		c.debugLog(
+			"retrying request",
+			"method", req.Method,
+			"url", req.URL.String(),
+			"wait", wait,
+			"reason", reason,
+			"attempt", attempt+1,
+			"max_attempts", policy.MaxAttempts,
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, body, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			b, err := req.GetBody()
+			if err != nil {
+				return res, body, err
+			}
+			req.Body = b
+		}
+	}
+}
+
+func (c *Conch) doOnce(req *http.Request) (*http.Response, []byte, error) {
+	res, err := c.client().Do(req)
+	if (res == nil) || (err != nil) {
+		return res, nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	return res, body, err
+}
+
+func (c *Conch) retryPolicy() RetryPolicy {
+	if c.RetryPolicy == nil {
+		return DefaultRetryPolicy
+	}
+	return *c.RetryPolicy
+}
+
+// retryable decides if a request that ended in res/err should be tried
+// again. It does not account for the number of attempts already made.
+func (p RetryPolicy) retryable(req *http.Request, res *http.Response, err error) bool {
+	methodOK := false
+	for _, m := range p.Methods {
+		if m == req.Method {
+			methodOK = true
+			break
+		}
+	}
+	if !methodOK {
+		return false
+	}
+
+	// Without a way to rewind the body, a second attempt would send an empty
+	// request
+	if (req.Body != nil) && (req.GetBody == nil) {
+		return false
+	}
+
+	if err != nil {
+		// A cassette won't grow a new answer by asking again
+		if errors.Is(err, ErrCassetteMiss) {
+			return false
+		}
+		// The caller gave up. That's not something to try again.
+		return req.Context().Err() == nil
+	}
+
+	for _, code := range p.StatusCodes {
+		if code == res.StatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait before the given retry, counting from 1.
+// A Retry-After header from the server wins if it asks for a longer wait. ok
+// is false if that wait would be longer than MaxBackoff, in which case the
+// request shouldn't be retried.
+func (p RetryPolicy) backoff(retry int, res *http.Response) (wait time.Duration, ok bool) {
+	wait = p.MinBackoff
+	for i := 1; i < retry; i++ {
+		wait *= 2
+		if (p.MaxBackoff > 0) && (wait >= p.MaxBackoff) {
+			break
+		}
+	}
+	if (p.MaxBackoff > 0) && (wait > p.MaxBackoff) {
+		wait = p.MaxBackoff
+	}
+
+	if (p.Jitter > 0) && (wait > 0) {
+		spread := float64(wait) * p.Jitter
+		wait = wait - time.Duration(spread) + time.Duration(rand.Float64()*2*spread)
+	}
+
+	if res != nil {
+		if after := retryAfter(res.Header.Get("Retry-After")); after > wait {
+			if (p.MaxBackoff > 0) && (after > p.MaxBackoff) {
+				return 0, false
+			}
+			wait = after
+		}
+	}
+
+	return wait, true
+}
+
+// retryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if when, err := http.ParseTime(v); err == nil {
+		return time.Until(when)
+	}
+
+	return 0
+}
@@ -0,0 +1,150 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestRetries(t *testing.T) {
+	gock.Flush()
+	defer gock.Flush()
+
+	policy := conch.DefaultRetryPolicy
+	policy.MinBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+
+	api := &conch.Conch{
+		BaseURL:     API.BaseURL,
+		HTTPClient:  http.DefaultClient,
+		RetryPolicy: &policy,
+	}
+
+	t.Run("GetRetriesBadGateway", func(t *testing.T) {
+		gock.New(api.BaseURL).Get("/version").Times(2).Reply(502)
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "1.2.3"})
+
+		ret, err := api.GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, ret, "1.2.3")
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("GetGivesUpAfterMaxAttempts", func(t *testing.T) {
+		gock.New(api.BaseURL).Get("/version").Times(3).Reply(503)
+
+		_, err := api.GetVersion()
+
+		var aerr *conch.APIError
+		st.Expect(t, errors.As(err, &aerr), true)
+		st.Expect(t, aerr.StatusCode, 503)
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("GetDoesNotRetryClientErrors", func(t *testing.T) {
+		gock.New(api.BaseURL).Get("/version").Reply(400).JSON(ErrApi)
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "1.2.3"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
+		st.Expect(t, gock.IsPending(), true)
+		gock.Flush()
+	})
+
+	t.Run("PostIsNotRetriedByDefault", func(t *testing.T) {
+		gock.New(api.BaseURL).Post("/device/test/graduate").Reply(502)
+		gock.New(api.BaseURL).Post("/device/test/graduate").Reply(204)
+
+		err := api.GraduateDevice("test")
+		st.Expect(t, errors.Is(err, conch.ErrHTTPNotOk), true)
+		st.Expect(t, gock.IsPending(), true)
+		gock.Flush()
+	})
+
+	t.Run("PostIsRetriedWhenOptedIn", func(t *testing.T) {
+		posting := policy
+		posting.Methods = append([]string{http.MethodPost}, policy.Methods...)
+		api := &conch.Conch{
+			BaseURL:     API.BaseURL,
+			HTTPClient:  http.DefaultClient,
+			RetryPolicy: &posting,
+		}
+
+		gock.New(api.BaseURL).Post("/device/test/asset_tag").
+			JSON(map[string]string{"asset_tag": "wat"}).Reply(502)
+		gock.New(api.BaseURL).Post("/device/test/asset_tag").
+			JSON(map[string]string{"asset_tag": "wat"}).Reply(204)
+
+		err := api.SetDeviceAssetTag("test", "wat")
+		st.Expect(t, err, nil)
+		st.Expect(t, gock.IsDone(), true)
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		patient := policy
+		patient.MaxBackoff = 2 * time.Second
+		api := &conch.Conch{
+			BaseURL:     API.BaseURL,
+			HTTPClient:  http.DefaultClient,
+			RetryPolicy: &patient,
+		}
+
+		gock.New(api.BaseURL).Get("/version").Reply(429).
+			SetHeader("Retry-After", "1")
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "1.2.3"})
+
+		start := time.Now()
+		ret, err := api.GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, ret, "1.2.3")
+		st.Expect(t, time.Since(start) >= time.Second, true)
+	})
+
+	t.Run("RetryAfterPastMaxBackoffGivesUp", func(t *testing.T) {
+		gock.New(api.BaseURL).Get("/version").Reply(429).
+			SetHeader("Retry-After", "3600")
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "1.2.3"})
+
+		start := time.Now()
+		_, err := api.GetVersion()
+
+		var aerr *conch.APIError
+		st.Expect(t, errors.As(err, &aerr), true)
+		st.Expect(t, aerr.StatusCode, 429)
+		st.Expect(t, time.Since(start) < time.Second, true)
+		st.Expect(t, gock.IsPending(), true)
+		gock.Flush()
+	})
+
+	t.Run("NoRetries", func(t *testing.T) {
+		api := &conch.Conch{
+			BaseURL:     API.BaseURL,
+			HTTPClient:  http.DefaultClient,
+			RetryPolicy: &conch.NoRetries,
+		}
+
+		gock.New(api.BaseURL).Get("/version").Reply(502)
+		gock.New(api.BaseURL).Get("/version").Reply(200).
+			JSON(map[string]string{"version": "1.2.3"})
+
+		_, err := api.GetVersion()
+		st.Expect(t, errors.Is(err, conch.ErrHTTPNotOk), true)
+		st.Expect(t, gock.IsPending(), true)
+		gock.Flush()
+	})
+}
@@ -7,21 +7,37 @@
 package conch
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/joyent/conch-shell/pkg/conch/uuid"
 )
 
 func (c *Conch) GetRooms() ([]Room, error) {
+	return c.GetRoomsContext(context.Background())
+}
+
+// GetRoomsContext is the context.Context aware version of GetRooms
+func (c *Conch) GetRoomsContext(ctx context.Context) ([]Room, error) {
 	r := make([]Room, 0)
-	return r, c.get("/room", &r)
+	return r, c.get(ctx, "/room", &r)
 }
 
 func (c *Conch) GetRoom(id uuid.UUID) (r Room, err error) {
-	return r, c.get("/room/"+url.PathEscape(id.String()), &r)
+	return c.GetRoomContext(context.Background(), id)
+}
+
+// GetRoomContext is the context.Context aware version of GetRoom
+func (c *Conch) GetRoomContext(ctx context.Context, id uuid.UUID) (r Room, err error) {
+	return r, c.get(ctx, "/room/"+url.PathEscape(id.String()), &r)
 }
 
 func (c *Conch) SaveRoom(r *Room) error {
+	return c.SaveRoomContext(context.Background(), r)
+}
+
+// SaveRoomContext is the context.Context aware version of SaveRoom
+func (c *Conch) SaveRoomContext(ctx context.Context, r *Room) error {
 	if uuid.Equal(r.DatacenterID, uuid.UUID{}) {
 		return ErrBadInput
 	}
@@ -41,17 +57,27 @@ func (c *Conch) SaveRoom(r *Room) error {
 	}{r.DatacenterID.String(), r.AZ, r.Alias, r.VendorName}
 
 	if uuid.Equal(r.ID, uuid.UUID{}) {
-		return c.post("/room", j, &r)
+		return c.post(ctx, "/room", j, &r)
 	} else {
-		return c.post("/room/"+url.PathEscape(r.ID.String()), j, &r)
+		return c.post(ctx, "/room/"+url.PathEscape(r.ID.String()), j, &r)
 	}
 }
 
 func (c *Conch) DeleteRoom(id uuid.UUID) error {
-	return c.httpDelete("/room/" + url.PathEscape(id.String()))
+	return c.DeleteRoomContext(context.Background(), id)
+}
+
+// DeleteRoomContext is the context.Context aware version of DeleteRoom
+func (c *Conch) DeleteRoomContext(ctx context.Context, id uuid.UUID) error {
+	return c.httpDelete(ctx, "/room/"+url.PathEscape(id.String()))
 }
 
 func (c *Conch) GetRoomRacks(r Room) ([]Rack, error) {
+	return c.GetRoomRacksContext(context.Background(), r)
+}
+
+// GetRoomRacksContext is the context.Context aware version of GetRoomRacks
+func (c *Conch) GetRoomRacksContext(ctx context.Context, r Room) ([]Rack, error) {
 	rs := make([]Rack, 0)
-	return rs, c.get("/room/"+url.PathEscape(r.ID.String())+"/racks", &rs)
+	return rs, c.get(ctx, "/room/"+url.PathEscape(r.ID.String())+"/racks", &rs)
 }
@@ -23,7 +23,7 @@ func TestRoomErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/room").Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRooms()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.Room{})
 	})
 
@@ -33,7 +33,7 @@ func TestRoomErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/room/" + id.String()).Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRoom(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Room{})
 	})
 
@@ -48,7 +48,7 @@ func TestRoomErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/room").Reply(400).JSON(ErrApi)
 
 		err := API.SaveRoom(&r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("UpdateRoom", func(t *testing.T) {
@@ -65,7 +65,7 @@ func TestRoomErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/room/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.SaveRoom(&r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteRoom", func(t *testing.T) {
@@ -74,7 +74,7 @@ func TestRoomErrors(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/room/" + id.String()).Reply(400).JSON(ErrApi)
 
 		err := API.DeleteRoom(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetRoomRacks", func(t *testing.T) {
@@ -92,7 +92,7 @@ func TestRoomErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetRoomRacks(r)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.Rack{})
 	})
 
@@ -0,0 +1,76 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"strings"
+)
+
+// routeLiterals are the fixed path segments in the API's routes. Every other
+// segment is an identifier of some sort: a UUID, a serial, a name, a setting
+// key, or an "email=" lookup.
+var routeLiterals = map[string]bool{
+	"asset_tag":        true,
+	"assignment":       true,
+	"child":            true,
+	"dc":               true,
+	"device":           true,
+	"graduate":         true,
+	"hardware_product": true,
+	"hardware_vendor":  true,
+	"interface":        true,
+	"ipaddr":           true,
+	"ipmi1":            true,
+	"layout":           true,
+	"layouts":          true,
+	"location":         true,
+	"login":            true,
+	"me":               true,
+	"password":         true,
+	"phase":            true,
+	"rack":             true,
+	"rack_role":        true,
+	"racks":            true,
+	"refresh_token":    true,
+	"register":         true,
+	"relay":            true,
+	"revoke":           true,
+	"room":             true,
+	"rooms":            true,
+	"settings":         true,
+	"token":            true,
+	"triton_reboot":    true,
+	"triton_setup":     true,
+	"triton_uuid":      true,
+	"user":             true,
+	"validation":       true,
+	"validation_plan":  true,
+	"validation_state": true,
+	"version":          true,
+	"workspace":        true,
+}
+
+// RouteTemplate reduces a request path to the API route it hits, replacing
+// identifiers with ":id". For instance, "/device/AB123/phase" becomes
+// "/device/:id/phase". The query string, if any, is dropped.
+//
+// The result has a small, fixed number of possible values which makes it
+// suitable as a metrics label or a key for per-route settings.
+func RouteTemplate(path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		if (s != "") && !routeLiterals[s] {
+			segments[i] = ":id"
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
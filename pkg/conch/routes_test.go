@@ -0,0 +1,32 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+)
+
+func TestRouteTemplate(t *testing.T) {
+	tests := map[string]string{
+		"/version":                                  "/version",
+		"/device/AB123/phase":                       "/device/:id/phase",
+		"/device/AB123/settings/tag.foo":            "/device/:id/settings/:id",
+		"/device?hostname=foo":                      "/device",
+		"/user/me/token/laptop":                     "/user/me/token/:id",
+		"/user/email=foo@bar.bat/revoke?api_only=1": "/user/:id/revoke",
+		"/workspace/abc/rack/def/layout":            "/workspace/:id/rack/:id/layout",
+		"/device/AB123/interface/ipmi1/ipaddr":      "/device/:id/interface/ipmi1/ipaddr",
+		"/":                                         "/",
+	}
+
+	for path, want := range tests {
+		st.Expect(t, conch.RouteTemplate(path), want)
+	}
+}
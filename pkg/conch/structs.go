@@ -7,12 +7,15 @@
 package conch
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"net/http"
 	"net/http/cookiejar"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/blang/semver"
 	"github.com/joyent/conch-shell/pkg/conch/uuid"
 )
 
@@ -23,7 +26,8 @@ const (
 	ValidationReportStatusOK   = 1
 )
 
-// Conch contains auth and configuration data
+// Conch contains auth and configuration data. Use New to build one that is
+// safe for concurrent use.
 type Conch struct {
 	BaseURL string
 	UA      string
@@ -34,6 +38,72 @@ type Conch struct {
 
 	HTTPClient *http.Client
 	CookieJar  *cookiejar.Jar
+
+	// TLSConfig, if set, replaces the TLS configuration of the default
+	// transport. See TLSOptions. It has no effect on an HTTPClient provided
+	// by the caller, whose transport is the caller's to configure.
+	TLSConfig *tls.Config
+
+	// TransportOptions, if set, tune the timeouts, proxy, and connection
+	// pool of the default transport. Like TLSConfig, they have no effect on
+	// an HTTPClient provided by the caller.
+	TransportOptions *TransportOptions
+
+	// RetryPolicy controls retries of failed requests. DefaultRetryPolicy is
+	// used if this is nil.
+	RetryPolicy *RetryPolicy
+
+	// StrictDecoding turns a response body that doesn't fit the expected
+	// structure into a *DecodeError. Otherwise, such failures are ignored and
+	// the caller gets whatever could be decoded.
+	StrictDecoding bool
+
+	// WarnUnknownFields logs a warning whenever a response carries fields
+	// the expected structure has no room for. It is a cheap way to notice
+	// API drift early.
+	WarnUnknownFields bool
+
+	// Logger receives debug, trace, and warning output. If nil, everything
+	// goes to stderr via StderrLogger. The Debug and Trace flags still decide
+	// what gets logged.
+	Logger Logger
+
+	// Middleware wrap the transport of every request, in order, with the
+	// first entry being outermost. See Middleware.
+	Middleware []Middleware
+
+	// Metrics, if set, is told about every request made. See
+	// PrometheusMetrics for a ready made implementation.
+	Metrics Metrics
+
+	// BatchConcurrency caps the number of requests in flight for batch calls
+	// like GetDevices. Defaults to DefaultBatchConcurrency.
+	BatchConcurrency int
+
+	// Cache, if set, keeps GET responses around for the duration listed in
+	// CacheTTLs and revalidates them via ETags. See MemoryCache and
+	// DiskCache.
+	Cache Cache
+
+	// CacheTTLs maps a RouteTemplate to how long its responses are cached.
+	// Defaults to DefaultCacheTTLs.
+	CacheTTLs map[string]time.Duration
+
+	// Recorder, if set, gets a copy of every request that goes out on the
+	// wire and its response, with credentials redacted. Cache hits never
+	// reach the wire, so turn the Cache off for a complete recording.
+	Recorder *Recorder
+
+	// Cassette, if set, answers every request in place of the network. See
+	// ReadCassette.
+	Cassette *Cassette
+
+	setupOnce  sync.Once
+	httpClient *http.Client // HTTPClient wrapped in the middleware chain
+	jwtMu      sync.RWMutex // guards JWT
+	refreshMu  sync.Mutex   // serializes JWT refreshes
+	versionMu  sync.RWMutex // guards apiVersion
+	apiVersion *semver.Version
 }
 
 type ConchJWT struct {
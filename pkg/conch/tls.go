@@ -0,0 +1,102 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// TLSOptions describe how to verify the API server and how to identify to
+// it. The zero value verifies the server against the system's roots, as
+// go does by default.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of certificate authorities to trust, on top of
+	// the system's roots
+	CAFile string
+
+	// ClientCert and ClientKey are PEM files holding a certificate, and its
+	// key, to present to servers that ask for one. They go together.
+	ClientCert string
+	ClientKey  string
+
+	// MinVersion is the lowest TLS version to accept: "1.0", "1.1", "1.2",
+	// or "1.3". Defaults to go's default.
+	MinVersion string
+
+	// ServerName overrides the name sent via SNI and checked against the
+	// server's certificate. Defaults to the host in the API's URL.
+	ServerName string
+
+	// InsecureSkipVerify turns off verification of the server's certificate,
+	// leaving the connection open to anyone in the middle. Only ever use it
+	// for local development.
+	InsecureSkipVerify bool
+}
+
+// IsZero reports whether the options are all defaults
+func (o TLSOptions) IsZero() bool {
+	return o == TLSOptions{}
+}
+
+// tlsVersions maps the names accepted by TLSOptions.MinVersion to go's
+// constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Config builds a *tls.Config from the options, reading any files named
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.MinVersion != "" {
+		v, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(o.MinVersion), "tls")]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS version '%s'", o.MinVersion)
+		}
+		cfg.MinVersion = v
+	}
+
+	if o.CAFile != "" {
+		pem, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (o.ClientCert != "") || (o.ClientKey != "") {
+		if (o.ClientCert == "") || (o.ClientKey == "") {
+			return nil, errors.New("a client certificate and its key must be provided together")
+		}
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
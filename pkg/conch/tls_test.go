@@ -0,0 +1,116 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+)
+
+func TestTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/whoami" {
+			if len(r.TLS.PeerCertificates) == 0 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		w.Write([]byte(`{"version":"v2.31.0"}`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "conch-tls")
+	st.Expect(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	// The test server's certificate doubles as the CA and, with its key, as
+	// the client certificate
+	cert := srv.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	st.Expect(t, err, nil)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	st.Expect(t, ioutil.WriteFile(
+		certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}),
+		0600,
+	), nil)
+	st.Expect(t, ioutil.WriteFile(
+		keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}),
+		0600,
+	), nil)
+
+	newAPI := func(t *testing.T, opts conch.TLSOptions, logger *recordingLogger) *conch.Conch {
+		cfg, err := opts.Config()
+		st.Assert(t, err, nil)
+		return conch.New(
+			conch.WithBaseURL(srv.URL),
+			conch.WithRetryPolicy(conch.NoRetries),
+			conch.WithTLSConfig(cfg),
+			conch.WithLogger(logger),
+		)
+	}
+
+	t.Run("UnknownCA", func(t *testing.T) {
+		_, err := newAPI(t, conch.TLSOptions{MinVersion: "1.2"}, &recordingLogger{}).GetVersion()
+		st.Reject(t, err, nil)
+	})
+
+	t.Run("CAFile", func(t *testing.T) {
+		v, err := newAPI(t, conch.TLSOptions{CAFile: certFile}, &recordingLogger{}).GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, v, "v2.31.0")
+	})
+
+	t.Run("ClientCert", func(t *testing.T) {
+		opts := conch.TLSOptions{CAFile: certFile}
+		res, err := newAPI(t, opts, &recordingLogger{}).RawGet("/whoami")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, http.StatusUnauthorized)
+
+		opts.ClientCert = certFile
+		opts.ClientKey = keyFile
+		res, err = newAPI(t, opts, &recordingLogger{}).RawGet("/whoami")
+		st.Expect(t, err, nil)
+		st.Expect(t, res.StatusCode, http.StatusOK)
+	})
+
+	t.Run("InsecureSkipVerify", func(t *testing.T) {
+		logger := &recordingLogger{}
+		_, err := newAPI(t, conch.TLSOptions{InsecureSkipVerify: true}, logger).GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, len(logger.lines) > 0, true)
+		st.Expect(t, logger.lines[0].level, "warn")
+	})
+
+	t.Run("BadOptions", func(t *testing.T) {
+		_, err := conch.TLSOptions{MinVersion: "0.9"}.Config()
+		st.Reject(t, err, nil)
+
+		_, err = conch.TLSOptions{ClientCert: certFile}.Config()
+		st.Reject(t, err, nil)
+
+		_, err = conch.TLSOptions{CAFile: keyFile}.Config()
+		st.Reject(t, err, nil)
+	})
+}
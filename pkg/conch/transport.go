@@ -0,0 +1,171 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TransportOptions tune the connections the client makes. Anything left at
+// zero keeps the default.
+type TransportOptions struct {
+	// DialTimeout caps how long a connection may take to establish.
+	// Defaults to 5 seconds.
+	DialTimeout time.Duration
+
+	// KeepAlive is the interval between TCP keep-alive probes. Defaults to
+	// 5 seconds.
+	KeepAlive time.Duration
+
+	// TLSHandshakeTimeout caps how long the TLS handshake may take. Defaults
+	// to 5 seconds.
+	TLSHandshakeTimeout time.Duration
+
+	// ResponseHeaderTimeout caps how long to wait for the server to start
+	// answering once the request is sent. Unlimited by default.
+	ResponseHeaderTimeout time.Duration
+
+	// Timeout caps each attempt at a request as a whole, including reading
+	// the response body. A retried request gets a fresh Timeout for each
+	// attempt. Unlimited by default.
+	Timeout time.Duration
+
+	// Proxy is used for every request instead of whatever HTTP_PROXY and
+	// HTTPS_PROXY say
+	Proxy *url.URL
+
+	// NoProxy lists hosts reached directly, never through a proxy. It takes
+	// the same forms as the NO_PROXY environment variable: a host name,
+	// which also covers its subdomains, an IP address, a CIDR block, or "*"
+	// for everything. It applies to the proxy from the environment as well
+	// as to Proxy.
+	NoProxy []string
+
+	// MaxIdleConns caps the number of idle connections kept around, across
+	// all hosts. Defaults to go's default.
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost caps the number of idle connections kept for the
+	// API. Raise it to BatchConcurrency so batch calls reuse connections
+	// rather than open new ones. Defaults to MaxConnsPerHost if that is set,
+	// and go's default of 2 otherwise.
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost caps the number of connections to the API, idle or
+	// not. Unlimited by default.
+	MaxConnsPerHost int
+}
+
+// transport returns an http.Transport built from the options, on top of the
+// library's defaults. Without any options or TLS configuration, the shared
+// default transport comes back so that its connection pool is reused.
+func (o *TransportOptions) transport(tlsConfig *tls.Config) *http.Transport {
+	if (o == nil) && (tlsConfig == nil) {
+		return defaultTransport
+	}
+	if o == nil {
+		o = &TransportOptions{}
+	}
+
+	t := defaultTransport.Clone()
+	t.TLSClientConfig = tlsConfig
+
+	dialTimeout, keepAlive := 5*time.Second, 5*time.Second
+	if o.DialTimeout > 0 {
+		dialTimeout = o.DialTimeout
+	}
+	if o.KeepAlive > 0 {
+		keepAlive = o.KeepAlive
+	}
+	t.Dial = nil
+	t.DialContext = (&net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: keepAlive,
+	}).DialContext
+
+	if o.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = o.TLSHandshakeTimeout
+	}
+	if o.ResponseHeaderTimeout > 0 {
+		t.ResponseHeaderTimeout = o.ResponseHeaderTimeout
+	}
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	} else if o.MaxConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = o.MaxConnsPerHost
+	}
+	if o.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+
+	if (o.Proxy != nil) || (len(o.NoProxy) > 0) {
+		t.Proxy = o.proxy
+	}
+
+	return t
+}
+
+// proxy picks the proxy for a request, if any
+func (o *TransportOptions) proxy(req *http.Request) (*url.URL, error) {
+	if bypassProxy(req.URL.Host, o.NoProxy) {
+		return nil, nil
+	}
+	if o.Proxy != nil {
+		return o.Proxy, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// bypassProxy reports whether a host, with or without a port, matches one
+// of the NoProxy patterns
+func bypassProxy(host string, patterns []string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if p == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(p); err == nil {
+			p = h
+		}
+
+		if _, block, err := net.ParseCIDR(p); err == nil {
+			if (ip != nil) && block.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if pip := net.ParseIP(p); pip != nil {
+			if (ip != nil) && pip.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		p = strings.TrimPrefix(strings.TrimPrefix(p, "*"), ".")
+		if (host == p) || strings.HasSuffix(host, "."+p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package conch_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+)
+
+func TestTransportOptions(t *testing.T) {
+	versionHandler := func(version string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				time.Sleep(200 * time.Millisecond)
+			}
+			w.Write([]byte(`{"version":"` + version + `"}`))
+		}
+	}
+
+	target := httptest.NewServer(versionHandler("target"))
+	defer target.Close()
+
+	// A plain HTTP proxy sees the request as it would go to the target, so
+	// it can just answer it itself
+	proxy := httptest.NewServer(versionHandler("proxy"))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	newAPI := func(o conch.TransportOptions) *conch.Conch {
+		return conch.New(
+			conch.WithBaseURL(target.URL),
+			conch.WithRetryPolicy(conch.NoRetries),
+			conch.WithTransportOptions(o),
+		)
+	}
+
+	t.Run("Timeout", func(t *testing.T) {
+		_, err := newAPI(conch.TransportOptions{Timeout: 50 * time.Millisecond}).RawGet("/slow")
+		st.Reject(t, err, nil)
+
+		_, err = newAPI(conch.TransportOptions{ResponseHeaderTimeout: 50 * time.Millisecond}).RawGet("/slow")
+		st.Reject(t, err, nil)
+
+		_, err = newAPI(conch.TransportOptions{Timeout: time.Second}).RawGet("/slow")
+		st.Expect(t, err, nil)
+	})
+
+	t.Run("Proxy", func(t *testing.T) {
+		v, err := newAPI(conch.TransportOptions{Proxy: proxyURL}).GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, v, "proxy")
+	})
+
+	t.Run("NoProxy", func(t *testing.T) {
+		for _, np := range []string{"*", "127.0.0.1", "127.0.0.0/8", target.Listener.Addr().String()} {
+			v, err := newAPI(conch.TransportOptions{
+				Proxy:   proxyURL,
+				NoProxy: []string{"conch.example.com", np},
+			}).GetVersion()
+			st.Expect(t, err, nil)
+			st.Expect(t, v, "target")
+		}
+
+		v, err := newAPI(conch.TransportOptions{
+			Proxy:   proxyURL,
+			NoProxy: []string{".example.com", "10.0.0.0/8"},
+		}).GetVersion()
+		st.Expect(t, err, nil)
+		st.Expect(t, v, "proxy")
+	})
+
+	t.Run("Pool", func(t *testing.T) {
+		api := newAPI(conch.TransportOptions{MaxConnsPerHost: 8})
+		st.Expect(t, api.HTTPClient.Transport.(*http.Transport).MaxConnsPerHost, 8)
+		st.Expect(t, api.HTTPClient.Transport.(*http.Transport).MaxIdleConnsPerHost, 8)
+	})
+}
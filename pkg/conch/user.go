@@ -7,6 +7,7 @@
 package conch
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 
@@ -14,17 +15,50 @@ import (
 )
 
 func (c *Conch) GetMyTokens() (UserTokens, error) {
+	return c.GetMyTokensContext(context.Background())
+}
+
+// GetMyTokensContext is the context.Context aware version of GetMyTokens
+func (c *Conch) GetMyTokensContext(ctx context.Context) (UserTokens, error) {
+	if err := c.require(CapUserTokens); err != nil {
+		return nil, err
+	}
+
 	u := make(UserTokens, 0)
-	return u, c.get("/user/me/token", &u)
+	return u, c.get(ctx, "/user/me/token", &u)
 }
 
 func (c *Conch) GetMyToken(name string) (u UserToken, err error) {
+	return c.GetMyTokenContext(context.Background(), name)
+}
+
+// GetMyTokenContext is the context.Context aware version of GetMyToken
+func (c *Conch) GetMyTokenContext(
+	ctx context.Context,
+	name string,
+) (u UserToken, err error) {
+	if err := c.require(CapUserTokens); err != nil {
+		return u, err
+	}
+
 	escapedName := url.PathEscape(name)
-	return u, c.get("/user/me/token/"+escapedName, &u)
+	return u, c.get(ctx, "/user/me/token/"+escapedName, &u)
 }
 
 func (c *Conch) CreateMyToken(name string) (u NewUserToken, err error) {
-	return u, c.post(
+	return c.CreateMyTokenContext(context.Background(), name)
+}
+
+// CreateMyTokenContext is the context.Context aware version of CreateMyToken
+func (c *Conch) CreateMyTokenContext(
+	ctx context.Context,
+	name string,
+) (u NewUserToken, err error) {
+	if err := c.require(CapUserTokens); err != nil {
+		return u, err
+	}
+
+	return u, c.post(ctx,
 		"/user/me/token",
 		CreateNewUserToken{Name: name},
 		&u,
@@ -32,28 +66,65 @@ func (c *Conch) CreateMyToken(name string) (u NewUserToken, err error) {
 }
 
 func (c *Conch) DeleteMyToken(name string) error {
+	return c.DeleteMyTokenContext(context.Background(), name)
+}
+
+// DeleteMyTokenContext is the context.Context aware version of DeleteMyToken
+func (c *Conch) DeleteMyTokenContext(ctx context.Context, name string) error {
+	if err := c.require(CapUserTokens); err != nil {
+		return err
+	}
+
 	escapedName := url.PathEscape(name)
-	return c.httpDelete("/user/me/token/" + escapedName)
+	return c.httpDelete(ctx, "/user/me/token/"+escapedName)
 }
 
 func (c *Conch) RevokeMyLogins() error {
-	return c.post("/user/me/revoke?auth_only=1", nil, nil)
+	return c.RevokeMyLoginsContext(context.Background())
+}
+
+// RevokeMyLoginsContext is the context.Context aware version of RevokeMyLogins
+func (c *Conch) RevokeMyLoginsContext(ctx context.Context) error {
+	return c.post(ctx, "/user/me/revoke?auth_only=1", nil, nil)
 }
 
 func (c *Conch) RevokeMyTokens() error {
-	return c.post("/user/me/revoke?api_only=1", nil, nil)
+	return c.RevokeMyTokensContext(context.Background())
+}
+
+// RevokeMyTokensContext is the context.Context aware version of RevokeMyTokens
+func (c *Conch) RevokeMyTokensContext(ctx context.Context) error {
+	if err := c.require(CapUserTokens); err != nil {
+		return err
+	}
+
+	return c.post(ctx, "/user/me/revoke?api_only=1", nil, nil)
 }
 
 func (c *Conch) RevokeMyTokensAndLogins() error {
-	if err := c.post("/user/me/revoke", nil, nil); err != nil {
+	return c.RevokeMyTokensAndLoginsContext(context.Background())
+}
+
+// RevokeMyTokensAndLoginsContext is the context.Context aware version of RevokeMyTokensAndLogins
+func (c *Conch) RevokeMyTokensAndLoginsContext(ctx context.Context) error {
+	if err := c.post(ctx, "/user/me/revoke", nil, nil); err != nil {
 		return err
 	}
 
-	c.JWT = ConchJWT{}
+	c.setJWT(ConchJWT{})
 	return nil
 }
 
 func (c *Conch) ChangeMyPassword(password string, revokeTokens bool) error {
+	return c.ChangeMyPasswordContext(context.Background(), password, revokeTokens)
+}
+
+// ChangeMyPasswordContext is the context.Context aware version of ChangeMyPassword
+func (c *Conch) ChangeMyPasswordContext(
+	ctx context.Context,
+	password string,
+	revokeTokens bool,
+) error {
 	b := struct {
 		Password string `json:"password"`
 	}{password}
@@ -71,7 +142,7 @@ func (c *Conch) ChangeMyPassword(password string, revokeTokens bool) error {
 		url = url + "clear_tokens=login_only"
 	}
 
-	return c.post(url, b, nil)
+	return c.post(ctx, url, b, nil)
 
 }
 
@@ -80,8 +151,13 @@ func (c *Conch) ChangeMyPassword(password string, revokeTokens bool) error {
 // string name and a jsonb data field.  There is no way for this library to
 // know in advanace what's in that data so here there be dragons.
 func (c *Conch) GetUserSettings() (map[string]interface{}, error) {
+	return c.GetUserSettingsContext(context.Background())
+}
+
+// GetUserSettingsContext is the context.Context aware version of GetUserSettings
+func (c *Conch) GetUserSettingsContext(ctx context.Context) (map[string]interface{}, error) {
 	settings := make(map[string]interface{})
-	return settings, c.get("/user/me/settings", &settings)
+	return settings, c.get(ctx, "/user/me/settings", &settings)
 }
 
 // GetUserSetting returns the results of /user/me/settings/:key
@@ -89,33 +165,72 @@ func (c *Conch) GetUserSettings() (map[string]interface{}, error) {
 // and a jsonb data field.  There is no way for this library to know in
 // advanace what's in that data so here there be dragons.
 func (c *Conch) GetUserSetting(key string) (setting interface{}, err error) {
-	return setting, c.get("/user/me/settings/"+url.PathEscape(key), &setting)
+	return c.GetUserSettingContext(context.Background(), key)
+}
+
+// GetUserSettingContext is the context.Context aware version of GetUserSetting
+func (c *Conch) GetUserSettingContext(
+	ctx context.Context,
+	key string,
+) (setting interface{}, err error) {
+	return setting, c.get(ctx, "/user/me/settings/"+url.PathEscape(key), &setting)
 }
 
 // SetUserSettings sets the value of *all* user settings via /user/me/settings
 func (c *Conch) SetUserSettings(settings map[string]interface{}) error {
-	return c.post("/user/me/settings", settings, nil)
+	return c.SetUserSettingsContext(context.Background(), settings)
+}
+
+// SetUserSettingsContext is the context.Context aware version of SetUserSettings
+func (c *Conch) SetUserSettingsContext(
+	ctx context.Context,
+	settings map[string]interface{},
+) error {
+	return c.post(ctx, "/user/me/settings", settings, nil)
 }
 
 // SetUserSetting sets the value of a user setting via /user/me/settings/:name
 func (c *Conch) SetUserSetting(name string, value interface{}) error {
-	return c.post("/user/me/settings/"+url.PathEscape(name), value, nil)
+	return c.SetUserSettingContext(context.Background(), name, value)
+}
+
+// SetUserSettingContext is the context.Context aware version of SetUserSetting
+func (c *Conch) SetUserSettingContext(
+	ctx context.Context,
+	name string,
+	value interface{},
+) error {
+	return c.post(ctx, "/user/me/settings/"+url.PathEscape(name), value, nil)
 }
 
 // DeleteUserSetting deletes a user setting via /user/me/settings/:name
 func (c *Conch) DeleteUserSetting(name string) error {
-	return c.httpDelete("/user/me/settings/" + url.PathEscape(name))
+	return c.DeleteUserSettingContext(context.Background(), name)
+}
+
+// DeleteUserSettingContext is the context.Context aware version of DeleteUserSetting
+func (c *Conch) DeleteUserSettingContext(ctx context.Context, name string) error {
+	return c.httpDelete(ctx, "/user/me/settings/"+url.PathEscape(name))
 }
 
 // DeleteUser deletes a user and, optionally, clears their JWT credentials
 func (c *Conch) DeleteUser(emailAddress string, clearTokens bool) error {
+	return c.DeleteUserContext(context.Background(), emailAddress, clearTokens)
+}
+
+// DeleteUserContext is the context.Context aware version of DeleteUser
+func (c *Conch) DeleteUserContext(
+	ctx context.Context,
+	emailAddress string,
+	clearTokens bool,
+) error {
 	url := "/user/email=" + url.PathEscape(emailAddress)
 
 	if clearTokens {
 		url = url + "?clear_tokens=1"
 	}
 
-	return c.httpDelete(url)
+	return c.httpDelete(ctx, url)
 }
 
 // CreateUser creates a new user. They are *not* added to a workspace.
@@ -124,6 +239,17 @@ func (c *Conch) DeleteUser(emailAddress string, clearTokens bool) error {
 // The 'password' argument is optional
 // The 'isAdmin' argument sets the user to be an admin. Defaults to false.
 func (c *Conch) CreateUser(email string, password string, name string, isAdmin bool) error {
+	return c.CreateUserContext(context.Background(), email, password, name, isAdmin)
+}
+
+// CreateUserContext is the context.Context aware version of CreateUser
+func (c *Conch) CreateUserContext(
+	ctx context.Context,
+	email string,
+	password string,
+	name string,
+	isAdmin bool,
+) error {
 	if email == "" {
 		return ErrBadInput
 	}
@@ -135,12 +261,21 @@ func (c *Conch) CreateUser(email string, password string, name string, isAdmin b
 		IsAdmin  bool   `json:"is_admin"`
 	}{email, password, name, isAdmin}
 
-	return c.post("/user", u, nil)
+	return c.post(ctx, "/user", u, nil)
 }
 
 // ResetUserPassword resets the password for the provided user, causing an
 // email to be sent
 func (c *Conch) ResetUserPassword(email string, revokeTokens bool) error {
+	return c.ResetUserPasswordContext(context.Background(), email, revokeTokens)
+}
+
+// ResetUserPasswordContext is the context.Context aware version of ResetUserPassword
+func (c *Conch) ResetUserPasswordContext(
+	ctx context.Context,
+	email string,
+	revokeTokens bool,
+) error {
 	url := "/user/email=" + url.PathEscape(email) + "/password?"
 	if revokeTokens {
 		url = url + "clear_tokens=all"
@@ -155,26 +290,52 @@ func (c *Conch) ResetUserPassword(email string, revokeTokens bool) error {
 		url = url + "clear_tokens=login_only"
 	}
 
-	return c.httpDelete(url)
+	return c.httpDelete(ctx, url)
 }
 
 // GetAllUsers retrieves a list of all users, if the user has the right
 // permissions, in the system. Returns UserDetailed structs
 func (c *Conch) GetAllUsers() (UsersDetailed, error) {
+	return c.GetAllUsersContext(context.Background())
+}
+
+// GetAllUsersContext is the context.Context aware version of GetAllUsers
+func (c *Conch) GetAllUsersContext(ctx context.Context) (UsersDetailed, error) {
 	u := make(UsersDetailed, 0)
-	return u, c.get("/user", &u)
+	return u, c.get(ctx, "/user", &u)
 }
 
 func (c *Conch) GetUserProfile() (profile UserProfile, err error) {
-	return profile, c.get("/user/me", &profile)
+	return c.GetUserProfileContext(context.Background())
+}
+
+// GetUserProfileContext is the context.Context aware version of GetUserProfile
+func (c *Conch) GetUserProfileContext(ctx context.Context) (profile UserProfile, err error) {
+	return profile, c.get(ctx, "/user/me", &profile)
 }
 
 func (c *Conch) GetUser(id uuid.UUID) (user UserDetailed, err error) {
-	return user, c.get("/user/"+url.PathEscape(id.String()), &user)
+	return c.GetUserContext(context.Background(), id)
+}
+
+// GetUserContext is the context.Context aware version of GetUser
+func (c *Conch) GetUserContext(
+	ctx context.Context,
+	id uuid.UUID,
+) (user UserDetailed, err error) {
+	return user, c.get(ctx, "/user/"+url.PathEscape(id.String()), &user)
 }
 
 func (c *Conch) GetUserByEmail(email string) (user UserDetailed, err error) {
-	return user, c.get("/user/email="+url.PathEscape(email), &user)
+	return c.GetUserByEmailContext(context.Background(), email)
+}
+
+// GetUserByEmailContext is the context.Context aware version of GetUserByEmail
+func (c *Conch) GetUserByEmailContext(
+	ctx context.Context,
+	email string,
+) (user UserDetailed, err error) {
+	return user, c.get(ctx, "/user/email="+url.PathEscape(email), &user)
 }
 
 // UpdateUser updates properties of a user. No workspace permissions are
@@ -188,6 +349,17 @@ func (c *Conch) UpdateUser(
 	email string,
 	name string,
 	isAdmin bool,
+) error {
+	return c.UpdateUserContext(context.Background(), userID, email, name, isAdmin)
+}
+
+// UpdateUserContext is the context.Context aware version of UpdateUser
+func (c *Conch) UpdateUserContext(
+	ctx context.Context,
+	userID uuid.UUID,
+	email string,
+	name string,
+	isAdmin bool,
 ) error {
 	if uuid.Equal(userID, uuid.UUID{}) {
 		return ErrBadInput
@@ -199,7 +371,7 @@ func (c *Conch) UpdateUser(
 		IsAdmin bool   `json:"is_admin"`
 	}{email, name, isAdmin}
 
-	return c.post(
+	return c.post(ctx,
 		fmt.Sprintf("/user/%s", url.PathEscape(userID.String())),
 		u,
 		nil,
@@ -21,7 +21,7 @@ func TestUserErrors(t *testing.T) {
 	t.Run("GetUserSettings", func(t *testing.T) {
 		gock.New(API.BaseURL).Get("/user/me/settings").Reply(400).JSON(ErrApi)
 		ret, err := API.GetUserSettings()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, make(map[string]interface{}))
 	})
 
@@ -30,7 +30,7 @@ func TestUserErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetUserSetting("test")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		var f interface{}
 		st.Expect(t, ret, f)
 	})
@@ -42,7 +42,7 @@ func TestUserErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.SetUserSettings(s)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("SetUserSetting", func(t *testing.T) {
@@ -50,31 +50,31 @@ func TestUserErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.SetUserSetting("test", "wat")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteUserSetting", func(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/user/me/settings/test").
 			Reply(400).JSON(ErrApi)
 		err := API.DeleteUserSetting("test")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteUser", func(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/user/email=foo@bar.bat").
 			Reply(400).JSON(ErrApi)
 		err := API.DeleteUser("foo@bar.bat", false)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("CreateUser", func(t *testing.T) {
 		gock.New(API.BaseURL).Post("/user").Reply(400).JSON(ErrApi)
 		err := API.CreateUser("foo@bar.bat", "", "", false)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 		gock.New(API.BaseURL).Post("/user").Reply(400).JSON(ErrApi)
 		err = API.CreateUser("foo@bar.bat", "", "", true)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("ResetUserPassword", func(t *testing.T) {
@@ -82,13 +82,13 @@ func TestUserErrors(t *testing.T) {
 			MatchParam("clear_tokens", "login_only").Reply(400).JSON(ErrApi)
 
 		err := API.ResetUserPassword("foo@bar.bat", false)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 		gock.New(API.BaseURL).Delete("/user/email=foo@bar.bat").
 			MatchParam("clear_tokens", "all").Reply(400).JSON(ErrApi)
 
 		err = API.ResetUserPassword("foo@bar.bat", true)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 	})
 
@@ -96,7 +96,7 @@ func TestUserErrors(t *testing.T) {
 		gock.New(API.BaseURL).Get("/user").Reply(400).JSON(ErrApi)
 		users, err := API.GetAllUsers()
 		st.Expect(t, users, make(conch.UsersDetailed, 0))
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetUserProfile", func(t *testing.T) {
@@ -104,14 +104,14 @@ func TestUserErrors(t *testing.T) {
 		profile, err := API.GetUserProfile()
 
 		st.Expect(t, profile, conch.UserProfile{})
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetMyTokens", func(t *testing.T) {
 		gock.New(API.BaseURL).Get("/user/me/token").Reply(400).JSON(ErrApi)
 		tokens, err := API.GetMyTokens()
 		st.Expect(t, tokens, make(conch.UserTokens, 0))
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 	})
 
@@ -121,7 +121,7 @@ func TestUserErrors(t *testing.T) {
 
 		token, err := API.GetMyToken(tokenName)
 		st.Expect(t, token, conch.UserToken{})
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("CreateMyToken", func(t *testing.T) {
@@ -130,7 +130,7 @@ func TestUserErrors(t *testing.T) {
 
 		token, err := API.CreateMyToken(tokenName)
 		st.Expect(t, token, conch.NewUserToken{})
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteMyToken", func(t *testing.T) {
@@ -138,7 +138,7 @@ func TestUserErrors(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/user/me/token/" + tokenName).Reply(400).JSON(ErrApi)
 
 		err := API.DeleteMyToken(tokenName)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("RevokeMyLogins", func(t *testing.T) {
@@ -146,7 +146,7 @@ func TestUserErrors(t *testing.T) {
 			MatchParam("auth_only", "1").Reply(400).JSON(ErrApi)
 
 		err := API.RevokeMyLogins()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("RevokeMyTokens", func(t *testing.T) {
@@ -154,25 +154,25 @@ func TestUserErrors(t *testing.T) {
 			MatchParam("api_only", "1").Reply(400).JSON(ErrApi)
 
 		err := API.RevokeMyTokens()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("RevokeMyTokensAndLogins", func(t *testing.T) {
 		gock.New(API.BaseURL).Post("/user/me/revoke").Reply(400).JSON(ErrApi)
 		err := API.RevokeMyTokensAndLogins()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("ChangeMyPassword", func(t *testing.T) {
 		gock.New(API.BaseURL).Post("/user/me/password").
 			MatchParam("clear_tokens", "login_only").Reply(400).JSON(ErrApi)
 		err := API.ChangeMyPassword("pants", false)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 		gock.New(API.BaseURL).Post("/user/me/password").
 			MatchParam("clear_tokens", "all").Reply(400).JSON(ErrApi)
 		err = API.ChangeMyPassword("pants", true)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 
 	})
 
@@ -7,6 +7,7 @@
 package conch
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -15,18 +16,36 @@ import (
 // GetValidations returns the contents of /validation, getting the list of all
 // validations loaded in the system
 func (c *Conch) GetValidations() (Validations, error) {
+	return c.GetValidationsContext(context.Background())
+}
+
+// GetValidationsContext is the context.Context aware version of GetValidations
+func (c *Conch) GetValidationsContext(ctx context.Context) (Validations, error) {
 	validations := make(Validations, 0)
-	return validations, c.get("/validation", &validations)
+	return validations, c.get(ctx, "/validation", &validations)
 }
 func (c *Conch) GetValidation(id fmt.Stringer) (v Validation, err error) {
-	return v, c.get("/validation/"+url.PathEscape(id.String()), &v)
+	return c.GetValidationContext(context.Background(), id)
+}
+
+// GetValidationContext is the context.Context aware version of GetValidation
+func (c *Conch) GetValidationContext(
+	ctx context.Context,
+	id fmt.Stringer,
+) (v Validation, err error) {
+	return v, c.get(ctx, "/validation/"+url.PathEscape(id.String()), &v)
 }
 
 // GetValidationPlans returns the contents of /validation_plan, getting the
 // list of all validations plans loaded in the system
 func (c *Conch) GetValidationPlans() ([]ValidationPlan, error) {
+	return c.GetValidationPlansContext(context.Background())
+}
+
+// GetValidationPlansContext is the context.Context aware version of GetValidationPlans
+func (c *Conch) GetValidationPlansContext(ctx context.Context) ([]ValidationPlan, error) {
 	validationPlans := make([]ValidationPlan, 0)
-	return validationPlans, c.get("/validation_plan", &validationPlans)
+	return validationPlans, c.get(ctx, "/validation_plan", &validationPlans)
 }
 
 // GetValidationPlan returns the contents of /validation_plan/:uuid, getting information
@@ -34,8 +53,16 @@ func (c *Conch) GetValidationPlans() ([]ValidationPlan, error) {
 func (c *Conch) GetValidationPlan(
 	validationPlanUUID fmt.Stringer,
 ) (vp ValidationPlan, err error) {
+	return c.GetValidationPlanContext(context.Background(), validationPlanUUID)
+}
 
-	return vp, c.get(
+// GetValidationPlanContext is the context.Context aware version of GetValidationPlan
+func (c *Conch) GetValidationPlanContext(
+	ctx context.Context,
+	validationPlanUUID fmt.Stringer,
+) (vp ValidationPlan, err error) {
+
+	return vp, c.get(ctx,
 		"/validation_plan/"+url.PathEscape(validationPlanUUID.String()),
 		&vp,
 	)
@@ -45,9 +72,17 @@ func (c *Conch) GetValidationPlan(
 func (c *Conch) GetValidationPlanValidations(
 	validationPlanUUID fmt.Stringer,
 ) (Validations, error) {
+	return c.GetValidationPlanValidationsContext(context.Background(), validationPlanUUID)
+}
+
+// GetValidationPlanValidationsContext is the context.Context aware version of GetValidationPlanValidations
+func (c *Conch) GetValidationPlanValidationsContext(
+	ctx context.Context,
+	validationPlanUUID fmt.Stringer,
+) (Validations, error) {
 
 	validations := make(Validations, 0)
-	return validations, c.get(
+	return validations, c.get(ctx,
 		"/validation_plan/"+url.PathEscape(validationPlanUUID.String())+"/validation",
 		&validations,
 	)
@@ -59,10 +94,20 @@ func (c *Conch) RunDeviceValidation(
 	validationUUID fmt.Stringer,
 	body string,
 ) ([]ValidationResult, error) {
+	return c.RunDeviceValidationContext(context.Background(), deviceSerial, validationUUID, body)
+}
+
+// RunDeviceValidationContext is the context.Context aware version of RunDeviceValidation
+func (c *Conch) RunDeviceValidationContext(
+	ctx context.Context,
+	deviceSerial string,
+	validationUUID fmt.Stringer,
+	body string,
+) ([]ValidationResult, error) {
 
 	results := make([]ValidationResult, 0)
 
-	return results, c.post(
+	return results, c.post(ctx,
 		"/device/"+deviceSerial+"/validation/"+url.PathEscape(validationUUID.String()),
 		body,
 		&results,
@@ -75,6 +120,16 @@ func (c *Conch) RunDeviceValidationPlan(
 	validationPlanUUID fmt.Stringer,
 	body string,
 ) ([]ValidationResult, error) {
+	return c.RunDeviceValidationPlanContext(context.Background(), deviceSerial, validationPlanUUID, body)
+}
+
+// RunDeviceValidationPlanContext is the context.Context aware version of RunDeviceValidationPlan
+func (c *Conch) RunDeviceValidationPlanContext(
+	ctx context.Context,
+	deviceSerial string,
+	validationPlanUUID fmt.Stringer,
+	body string,
+) ([]ValidationResult, error) {
 
 	results := make([]ValidationResult, 0)
 
@@ -84,7 +139,7 @@ func (c *Conch) RunDeviceValidationPlan(
 		return results, err
 	}
 
-	return results, c.post(
+	return results, c.post(ctx,
 		"/device/"+deviceSerial+"/validation_plan/"+url.PathEscape(validationPlanUUID.String()),
 		j,
 		&results,
@@ -95,18 +150,34 @@ func (c *Conch) RunDeviceValidationPlan(
 func (c *Conch) DeviceValidationStates(
 	deviceSerial string,
 ) ([]ValidationState, error) {
+	return c.DeviceValidationStatesContext(context.Background(), deviceSerial)
+}
+
+// DeviceValidationStatesContext is the context.Context aware version of DeviceValidationStates
+func (c *Conch) DeviceValidationStatesContext(
+	ctx context.Context,
+	deviceSerial string,
+) ([]ValidationState, error) {
 
 	states := make([]ValidationState, 0)
-	return states, c.get("/device/"+url.PathEscape(deviceSerial)+"/validation_state", &states)
+	return states, c.get(ctx, "/device/"+url.PathEscape(deviceSerial)+"/validation_state", &states)
 }
 
 // WorkspaceValidationStates returns the stored validation states for all devices in a workspace
 func (c *Conch) WorkspaceValidationStates(
 	workspaceUUID fmt.Stringer,
 ) ([]ValidationState, error) {
+	return c.WorkspaceValidationStatesContext(context.Background(), workspaceUUID)
+}
+
+// WorkspaceValidationStatesContext is the context.Context aware version of WorkspaceValidationStates
+func (c *Conch) WorkspaceValidationStatesContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+) ([]ValidationState, error) {
 
 	states := make([]ValidationState, 0)
-	return states, c.get(
+	return states, c.get(ctx,
 		"/workspace/"+url.PathEscape(workspaceUUID.String())+"/validation_state",
 		&states,
 	)
@@ -23,7 +23,7 @@ func TestValidationErrors(t *testing.T) {
 		url := "/validation"
 		gock.New(API.BaseURL).Get(url).Reply(400).JSON(ErrApi)
 		ret, err := API.GetValidations()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Validations{})
 	})
 
@@ -32,7 +32,7 @@ func TestValidationErrors(t *testing.T) {
 
 		gock.New(API.BaseURL).Get(url).Reply(400).JSON(ErrApi)
 		ret, err := API.GetValidationPlans()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.ValidationPlan{})
 	})
 
@@ -42,7 +42,7 @@ func TestValidationErrors(t *testing.T) {
 
 		gock.New(API.BaseURL).Get(url).Reply(400).JSON(ErrApi)
 		ret, err := API.GetValidationPlan(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.ValidationPlan{})
 	})
 
@@ -53,7 +53,7 @@ func TestValidationErrors(t *testing.T) {
 
 		gock.New(API.BaseURL).Post(url).Reply(400).JSON(ErrApi)
 		ret, err := API.RunDeviceValidationPlan(dID, vpID, "{}")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.ValidationResult{})
 	})
 
@@ -64,7 +64,7 @@ func TestValidationErrors(t *testing.T) {
 
 		gock.New(API.BaseURL).Post(url).Reply(400).JSON(ErrApi)
 		ret, err := API.RunDeviceValidation(dID, vpID, "{}")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.ValidationResult{})
 	})
 
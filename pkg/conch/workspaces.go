@@ -7,6 +7,7 @@
 package conch
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 
@@ -19,10 +20,18 @@ import (
 // datacenter/az. This routine copies that key into the Datacenter field in the
 // Rack struct.
 func (c *Conch) GetWorkspaceRacks(workspaceUUID fmt.Stringer) ([]WorkspaceRack, error) {
+	return c.GetWorkspaceRacksContext(context.Background(), workspaceUUID)
+}
+
+// GetWorkspaceRacksContext is the context.Context aware version of GetWorkspaceRacks
+func (c *Conch) GetWorkspaceRacksContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+) ([]WorkspaceRack, error) {
 	racks := make([]WorkspaceRack, 0)
 	j := make(map[string][]WorkspaceRack)
 
-	if err := c.get("/workspace/"+url.PathEscape(workspaceUUID.String())+"/rack", &j); err != nil {
+	if err := c.get(ctx, "/workspace/"+url.PathEscape(workspaceUUID.String())+"/rack", &j); err != nil {
 		return racks, err
 	}
 
@@ -42,7 +51,16 @@ func (c *Conch) GetWorkspaceRack(
 	workspaceUUID fmt.Stringer,
 	rackUUID fmt.Stringer,
 ) (rack WorkspaceRack, err error) {
-	return rack, c.get(
+	return c.GetWorkspaceRackContext(context.Background(), workspaceUUID, rackUUID)
+}
+
+// GetWorkspaceRackContext is the context.Context aware version of GetWorkspaceRack
+func (c *Conch) GetWorkspaceRackContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+	rackUUID fmt.Stringer,
+) (rack WorkspaceRack, err error) {
+	return rack, c.get(ctx,
 		"/workspace/"+
 			url.PathEscape(workspaceUUID.String())+
 			"/rack/"+
@@ -63,6 +81,18 @@ func (c *Conch) GetWorkspaceDevices(
 	health string,
 	validated string,
 ) (Devices, error) {
+	return c.GetWorkspaceDevicesContext(context.Background(), workspaceUUID, idsOnly, graduated, health, validated)
+}
+
+// GetWorkspaceDevicesContext is the context.Context aware version of GetWorkspaceDevices
+func (c *Conch) GetWorkspaceDevicesContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+	idsOnly bool,
+	graduated string,
+	health string,
+	validated string,
+) (Devices, error) {
 
 	devices := make([]Device, 0)
 
@@ -82,7 +112,7 @@ func (c *Conch) GetWorkspaceDevices(
 	if idsOnly {
 		ids := make([]string, 0)
 
-		if err := c.getWithQuery(url, opts, &ids); err != nil {
+		if err := c.getWithQuery(ctx, url, opts, &ids); err != nil {
 			return devices, err
 		}
 
@@ -92,34 +122,63 @@ func (c *Conch) GetWorkspaceDevices(
 		}
 		return devices, nil
 	}
-	return devices, c.getWithQuery(url, opts, &devices)
+	return devices, c.getWithQuery(ctx, url, opts, &devices)
 }
 
 // GetWorkspaces returns the contents of /workspace, getting the list of all
 // workspaces that the user has access to
 func (c *Conch) GetWorkspaces() (Workspaces, error) {
+	return c.GetWorkspacesContext(context.Background())
+}
+
+// GetWorkspacesContext is the context.Context aware version of GetWorkspaces
+func (c *Conch) GetWorkspacesContext(ctx context.Context) (Workspaces, error) {
 	workspaces := make([]Workspace, 0)
-	return workspaces, c.get("/workspace", &workspaces)
+	return workspaces, c.get(ctx, "/workspace", &workspaces)
 }
 
 // GetWorkspace returns the contents of /workspace/:uuid, getting information
 // about a single workspace
 func (c *Conch) GetWorkspace(workspaceUUID fmt.Stringer) (w Workspace, e error) {
-	return w, c.get("/workspace/"+url.PathEscape(workspaceUUID.String()), &w)
+	return c.GetWorkspaceContext(context.Background(), workspaceUUID)
+}
+
+// GetWorkspaceContext is the context.Context aware version of GetWorkspace
+func (c *Conch) GetWorkspaceContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+) (w Workspace, e error) {
+	return w, c.get(ctx, "/workspace/"+url.PathEscape(workspaceUUID.String()), &w)
 }
 
 // GetWorkspaceByName returns the contents of /workspace/:name, getting
 // information about a single workspace
 func (c *Conch) GetWorkspaceByName(name string) (w Workspace, e error) {
+	return c.GetWorkspaceByNameContext(context.Background(), name)
+}
 
-	return w, c.get("/workspace/"+url.PathEscape(name), &w)
+// GetWorkspaceByNameContext is the context.Context aware version of GetWorkspaceByName
+func (c *Conch) GetWorkspaceByNameContext(
+	ctx context.Context,
+	name string,
+) (w Workspace, e error) {
+
+	return w, c.get(ctx, "/workspace/"+url.PathEscape(name), &w)
 }
 
 // GetSubWorkspaces returns the contents of /workspace/:uuid/child, getting
 // a list of subworkspaces for the given workspace id
 func (c *Conch) GetSubWorkspaces(workspaceUUID fmt.Stringer) (Workspaces, error) {
+	return c.GetSubWorkspacesContext(context.Background(), workspaceUUID)
+}
+
+// GetSubWorkspacesContext is the context.Context aware version of GetSubWorkspaces
+func (c *Conch) GetSubWorkspacesContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+) (Workspaces, error) {
 	workspaces := make(Workspaces, 0)
-	return workspaces, c.get(
+	return workspaces, c.get(ctx,
 		"/workspace/"+url.PathEscape(workspaceUUID.String())+"/child",
 		&workspaces,
 	)
@@ -128,8 +187,16 @@ func (c *Conch) GetSubWorkspaces(workspaceUUID fmt.Stringer) (Workspaces, error)
 // GetWorkspaceUsers returns the contents of /workspace/:uuid/users, getting
 // a list of users for the given workspace id
 func (c *Conch) GetWorkspaceUsers(workspaceUUID fmt.Stringer) ([]WorkspaceUser, error) {
+	return c.GetWorkspaceUsersContext(context.Background(), workspaceUUID)
+}
+
+// GetWorkspaceUsersContext is the context.Context aware version of GetWorkspaceUsers
+func (c *Conch) GetWorkspaceUsersContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+) ([]WorkspaceUser, error) {
 	users := make([]WorkspaceUser, 0)
-	return users, c.get(
+	return users, c.get(ctx,
 		"/workspace/"+url.PathEscape(workspaceUUID.String())+"/user",
 		&users,
 	)
@@ -142,6 +209,15 @@ func (c *Conch) GetWorkspaceUsers(workspaceUUID fmt.Stringer) ([]WorkspaceUser,
 // happens, the API returns a 500 rather than something useful. The routine
 // will return ErrHTTPNotOk in that case.
 func (c *Conch) CreateSubWorkspace(parent Workspace, sub Workspace) (Workspace, error) {
+	return c.CreateSubWorkspaceContext(context.Background(), parent, sub)
+}
+
+// CreateSubWorkspaceContext is the context.Context aware version of CreateSubWorkspace
+func (c *Conch) CreateSubWorkspaceContext(
+	ctx context.Context,
+	parent Workspace,
+	sub Workspace,
+) (Workspace, error) {
 	if uuid.Equal(parent.ID, uuid.UUID{}) {
 		return sub, ErrBadInput
 	}
@@ -153,7 +229,7 @@ func (c *Conch) CreateSubWorkspace(parent Workspace, sub Workspace) (Workspace,
 		sub.Description,
 	}
 
-	return sub, c.post(
+	return sub, c.post(ctx,
 		"/workspace/"+url.PathEscape(parent.ID.String())+"/child",
 		j,
 		&sub,
@@ -163,28 +239,56 @@ func (c *Conch) CreateSubWorkspace(parent Workspace, sub Workspace) (Workspace,
 // AddRackToWorkspace adds an existing rack to an existing workspace, via
 // /workspace/:uuid/rack
 func (c *Conch) AddRackToWorkspace(workspaceUUID fmt.Stringer, rackUUID fmt.Stringer) error {
+	return c.AddRackToWorkspaceContext(context.Background(), workspaceUUID, rackUUID)
+}
+
+// AddRackToWorkspaceContext is the context.Context aware version of AddRackToWorkspace
+func (c *Conch) AddRackToWorkspaceContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+	rackUUID fmt.Stringer,
+) error {
 	j := struct {
 		ID string `json:"id"`
 	}{
 		rackUUID.String(),
 	}
 
-	return c.post("/workspace/"+url.PathEscape(workspaceUUID.String())+"/rack", j, nil)
+	return c.post(ctx, "/workspace/"+url.PathEscape(workspaceUUID.String())+"/rack", j, nil)
 }
 
 // DeleteRackFromWorkspace removes an existing rack from an existing workplace,
 // via /workspace/:uuid/rack/:uuid
 func (c *Conch) DeleteRackFromWorkspace(workspaceUUID fmt.Stringer, rackUUID fmt.Stringer) error {
-	return c.httpDelete(
-		"/workspace/" +
-			url.PathEscape(workspaceUUID.String()) +
-			"/rack/" +
+	return c.DeleteRackFromWorkspaceContext(context.Background(), workspaceUUID, rackUUID)
+}
+
+// DeleteRackFromWorkspaceContext is the context.Context aware version of DeleteRackFromWorkspace
+func (c *Conch) DeleteRackFromWorkspaceContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+	rackUUID fmt.Stringer,
+) error {
+	return c.httpDelete(ctx,
+		"/workspace/"+
+			url.PathEscape(workspaceUUID.String())+
+			"/rack/"+
 			url.PathEscape(rackUUID.String()),
 	)
 }
 
 // AddUserToWorkspace adds a user to a workspace via /workspace/:uuid/user
 func (c *Conch) AddUserToWorkspace(workspaceUUID fmt.Stringer, user string, role string) error {
+	return c.AddUserToWorkspaceContext(context.Background(), workspaceUUID, user, role)
+}
+
+// AddUserToWorkspaceContext is the context.Context aware version of AddUserToWorkspace
+func (c *Conch) AddUserToWorkspaceContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+	user string,
+	role string,
+) error {
 	body := struct {
 		User string `json:"user"`
 		Role string `json:"role"`
@@ -193,14 +297,23 @@ func (c *Conch) AddUserToWorkspace(workspaceUUID fmt.Stringer, user string, role
 		role,
 	}
 
-	return c.post("/workspace/"+url.PathEscape(workspaceUUID.String())+"/user", body, nil)
+	return c.post(ctx, "/workspace/"+url.PathEscape(workspaceUUID.String())+"/user", body, nil)
 }
 
 // RemoveUserFromWorkspace ...
 func (c *Conch) RemoveUserFromWorkspace(workspaceUUID fmt.Stringer, email string) error {
-	return c.httpDelete("/workspace/" +
-		url.PathEscape(workspaceUUID.String()) +
-		"/user/email=" +
+	return c.RemoveUserFromWorkspaceContext(context.Background(), workspaceUUID, email)
+}
+
+// RemoveUserFromWorkspaceContext is the context.Context aware version of RemoveUserFromWorkspace
+func (c *Conch) RemoveUserFromWorkspaceContext(
+	ctx context.Context,
+	workspaceUUID fmt.Stringer,
+	email string,
+) error {
+	return c.httpDelete(ctx, "/workspace/"+
+		url.PathEscape(workspaceUUID.String())+
+		"/user/email="+
 		url.PathEscape(email),
 	)
 }
@@ -210,7 +323,17 @@ func (c *Conch) AssignWorkspaceDevicesToRackSlots(
 	rackID fmt.Stringer,
 	assignments WorkspaceRackLayoutAssignments,
 ) error {
-	return c.post(
+	return c.AssignWorkspaceDevicesToRackSlotsContext(context.Background(), workspaceID, rackID, assignments)
+}
+
+// AssignWorkspaceDevicesToRackSlotsContext is the context.Context aware version of AssignWorkspaceDevicesToRackSlots
+func (c *Conch) AssignWorkspaceDevicesToRackSlotsContext(
+	ctx context.Context,
+	workspaceID fmt.Stringer,
+	rackID fmt.Stringer,
+	assignments WorkspaceRackLayoutAssignments,
+) error {
+	return c.post(ctx,
 		"/workspace/"+
 			url.PathEscape(workspaceID.String())+
 			"/rack/"+
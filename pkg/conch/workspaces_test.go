@@ -22,7 +22,7 @@ func TestWorkspaceErrors(t *testing.T) {
 	t.Run("GetWorkspaces", func(t *testing.T) {
 		gock.New(API.BaseURL).Get("/workspace").Reply(400).JSON(ErrApi)
 		ret, err := API.GetWorkspaces()
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Workspaces{})
 	})
 
@@ -32,7 +32,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetWorkspace(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Workspace{})
 	})
 
@@ -41,7 +41,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetWorkspaceByName("GLOBAL")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Workspace{})
 	})
 
@@ -51,7 +51,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetSubWorkspaces(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Workspaces{})
 	})
 
@@ -61,7 +61,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetWorkspaceUsers(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.WorkspaceUser{})
 	})
 
@@ -75,7 +75,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.CreateSubWorkspace(w, s)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, s)
 	})
 
@@ -87,7 +87,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.AddRackToWorkspace(id, id2)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("DeleteRackFromWorkspace", func(t *testing.T) {
@@ -98,7 +98,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		err := API.DeleteRackFromWorkspace(id, id2)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("AddUserToWorkspace", func(t *testing.T) {
@@ -106,7 +106,7 @@ func TestWorkspaceErrors(t *testing.T) {
 		gock.New(API.BaseURL).Post("/workspace/" + id.String() + "/user").
 			Reply(400).JSON(ErrApi)
 		err := API.AddUserToWorkspace(id, "user", "role")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("RemoveUserFromWorkspace", func(t *testing.T) {
@@ -114,7 +114,7 @@ func TestWorkspaceErrors(t *testing.T) {
 		gock.New(API.BaseURL).Delete("/workspace/" + id.String() + "/user").
 			Reply(400).JSON(ErrApi)
 		err := API.RemoveUserFromWorkspace(id, "user")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 	})
 
 	t.Run("GetWorkspaceDevices", func(t *testing.T) {
@@ -124,19 +124,19 @@ func TestWorkspaceErrors(t *testing.T) {
 			Persist().Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetWorkspaceDevices(id, false, "g", "h", "T")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Devices{})
 
 		ret, err = API.GetWorkspaceDevices(id, true, "g", "h", "T")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Devices{})
 
 		ret, err = API.GetWorkspaceDevices(id, true, "g", "h", "T")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Devices{})
 
 		ret, err = API.GetWorkspaceDevices(id, true, "g", "h", "F")
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.Devices{})
 
 		gock.Flush()
@@ -150,7 +150,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetWorkspaceRacks(id)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, []conch.WorkspaceRack{})
 	})
 
@@ -163,7 +163,7 @@ func TestWorkspaceErrors(t *testing.T) {
 			Reply(400).JSON(ErrApi)
 
 		ret, err := API.GetWorkspaceRack(id, rID)
-		st.Expect(t, err, ErrApiUnpacked)
+		st.Expect(t, apiErrorMessage(err), ErrApi.ErrorMsg)
 		st.Expect(t, ret, conch.WorkspaceRack{})
 	})
 
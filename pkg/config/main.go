@@ -14,12 +14,14 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/joyent/conch-shell/pkg/conch"
 	"github.com/joyent/conch-shell/pkg/conch/uuid"
 	"github.com/joyent/conch-shell/pkg/config/obfuscate"
+	"github.com/joyent/conch-shell/pkg/config/secrets"
 )
 
 const (
@@ -41,11 +43,22 @@ var ErrConfigNoPath = errors.New("no path found in config data")
 type ConchConfig struct {
 	Path     string                   `json:"path"`
 	Profiles map[string]*ConchProfile `json:"profiles"`
+
+	// SecretStore, if set, names the secret store where every profile keeps
+	// its token and JWT. See package secrets for the choices.
+	SecretStore string `json:"secret_store,omitempty"`
+
+	// Secrets opens the stores named by SecretStore and the profiles'
+	// TokenRef
+	Secrets *secrets.Stores `json:"-"`
+
+	stale []ConchProfile
 }
 
 // We're going to obfuscate the token itself. I'm aware this is krypto and not
 // even remotely secure. But it will prevent the tokens from being just c&p'd
-// out of the configs on a remote box.
+// out of the configs on a remote box. For real protection, keep the token in
+// a secret store instead. See ConchProfile.TokenRef.
 type Token string
 
 func (t Token) String() string {
@@ -85,6 +98,147 @@ type ConchProfile struct {
 	JWT           conch.ConchJWT `json:"jwt"`               // TODO(sungo): DEPRECATED
 	Expires       time.Time      `json:"expires,omitempty"` // TODO(sungo): DEPRECATED
 	Token         Token          `json:"token"`
+
+	// TokenName and TokenExpires describe the API token, if the shell
+	// created it, so that it can be rotated before it expires
+	TokenName    string    `json:"token_name,omitempty"`
+	TokenExpires time.Time `json:"token_expires,omitempty"`
+
+	// TokenRef, if set, points to where the token and JWT are kept instead
+	// of the config file, as "<store>:<key>". See ConchConfig.LoadSecrets.
+	TokenRef string `json:"token_ref,omitempty"`
+
+	// CredentialHelper, if set, is a command that keeps the token and JWT
+	// instead, like "pass show conch/prod". It wins over TokenRef. See
+	// secrets.Helper for the protocol.
+	CredentialHelper string `json:"credential_helper,omitempty"`
+
+	// TLS settings for reaching this profile's API. See conch.TLSOptions.
+	CAFile             string `json:"ca_file,omitempty"`
+	ClientCert         string `json:"client_cert,omitempty"`
+	ClientKey          string `json:"client_key,omitempty"`
+	TLSMinVersion      string `json:"tls_min_version,omitempty"`
+	TLSServerName      string `json:"tls_server_name,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+
+	TransportSettings
+
+	secretsLoaded bool
+	storedSecrets string // as last loaded from or saved to the store
+}
+
+// TransportSettings tune how the shell connects to an API. Durations are in
+// the form understood by time.ParseDuration, like "30s" or "2m". See
+// conch.TransportOptions for what each one does.
+type TransportSettings struct {
+	ConnectTimeout  string   `json:"connect_timeout,omitempty"`
+	KeepAlive       string   `json:"keepalive,omitempty"`
+	TLSTimeout      string   `json:"tls_timeout,omitempty"`
+	ResponseTimeout string   `json:"response_timeout,omitempty"`
+	Timeout         string   `json:"timeout,omitempty"`
+	Proxy           string   `json:"proxy,omitempty"`
+	NoProxy         []string `json:"no_proxy,omitempty"`
+	MaxIdleConns    int      `json:"max_idle_conns,omitempty"`
+	MaxConnsPerHost int      `json:"max_conns_per_host,omitempty"`
+	MaxIdlePerHost  int      `json:"max_idle_conns_per_host,omitempty"`
+}
+
+// Merge returns a copy of the settings with anything set in o replacing what
+// was there
+func (t TransportSettings) Merge(o TransportSettings) TransportSettings {
+	str := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	num := func(dst *int, src int) {
+		if src != 0 {
+			*dst = src
+		}
+	}
+
+	str(&t.ConnectTimeout, o.ConnectTimeout)
+	str(&t.KeepAlive, o.KeepAlive)
+	str(&t.TLSTimeout, o.TLSTimeout)
+	str(&t.ResponseTimeout, o.ResponseTimeout)
+	str(&t.Timeout, o.Timeout)
+	str(&t.Proxy, o.Proxy)
+	if len(o.NoProxy) > 0 {
+		t.NoProxy = o.NoProxy
+	}
+	num(&t.MaxIdleConns, o.MaxIdleConns)
+	num(&t.MaxConnsPerHost, o.MaxConnsPerHost)
+	num(&t.MaxIdlePerHost, o.MaxIdlePerHost)
+	return t
+}
+
+// IsZero reports whether nothing is set
+func (t TransportSettings) IsZero() bool {
+	return (t.ConnectTimeout == "") && (t.KeepAlive == "") &&
+		(t.TLSTimeout == "") && (t.ResponseTimeout == "") &&
+		(t.Timeout == "") && (t.Proxy == "") && (len(t.NoProxy) == 0) &&
+		(t.MaxIdleConns == 0) && (t.MaxConnsPerHost == 0) &&
+		(t.MaxIdlePerHost == 0)
+}
+
+// TransportOptions parses the settings into conch.TransportOptions
+func (t TransportSettings) TransportOptions() (conch.TransportOptions, error) {
+	o := conch.TransportOptions{
+		NoProxy:             t.NoProxy,
+		MaxIdleConns:        t.MaxIdleConns,
+		MaxConnsPerHost:     t.MaxConnsPerHost,
+		MaxIdleConnsPerHost: t.MaxIdlePerHost,
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"connect_timeout", t.ConnectTimeout, &o.DialTimeout},
+		{"keepalive", t.KeepAlive, &o.KeepAlive},
+		{"tls_timeout", t.TLSTimeout, &o.TLSHandshakeTimeout},
+		{"response_timeout", t.ResponseTimeout, &o.ResponseHeaderTimeout},
+		{"timeout", t.Timeout, &o.Timeout},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return o, fmt.Errorf("%s: %s", d.name, err)
+		}
+		if v < 0 {
+			return o, fmt.Errorf("%s: must not be negative", d.name)
+		}
+		*d.dst = v
+	}
+
+	if t.Proxy != "" {
+		u, err := url.Parse(t.Proxy)
+		if err != nil {
+			return o, fmt.Errorf("proxy: %s", err)
+		}
+		if (u.Scheme == "") || (u.Host == "") {
+			return o, fmt.Errorf("proxy: '%s' is not a URL like http://proxy.example.com:3128", t.Proxy)
+		}
+		o.Proxy = u
+	}
+
+	return o, nil
+}
+
+// TLSOptions collects the profile's TLS settings
+func (p *ConchProfile) TLSOptions() conch.TLSOptions {
+	return conch.TLSOptions{
+		CAFile:             p.CAFile,
+		ClientCert:         p.ClientCert,
+		ClientKey:          p.ClientKey,
+		MinVersion:         p.TLSMinVersion,
+		ServerName:         p.TLSServerName,
+		InsecureSkipVerify: p.InsecureSkipVerify,
+	}
 }
 
 // New provides an initialized struct with default values geared towards a
@@ -213,20 +367,29 @@ func (c *ConchConfig) Serialize() (s string, err error) {
 }
 
 // SerializeToFile marshals a ConchConfig struct into a JSON string and
-// writes it out to the provided path
+// writes it out to the provided path, readable only by the user. Profile
+// secrets that belong in a secret store are saved there first.
 func (c *ConchConfig) SerializeToFile(path string) (err error) {
 	if c.Path == "" {
 		return ErrConfigNoPath
 	}
 
+	if err := c.storeSecrets(); err != nil {
+		return err
+	}
+
 	j, err := json.MarshalIndent(c, "", "	")
 
 	if err != nil {
 		return err
 	}
 
-	err = ioutil.WriteFile(path, j, 0644)
-	return err
+	if err := secrets.WriteFile(path, j); err != nil {
+		return err
+	}
+
+	c.dropStaleSecrets()
+	return nil
 }
 
 // BUG(sungo): entirely for config backcompat
@@ -0,0 +1,331 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/joyent/conch-shell/pkg/config/secrets"
+)
+
+// profileSecrets is what a profile keeps in a secret store
+type profileSecrets struct {
+	Token string         `json:"token,omitempty"`
+	JWT   conch.ConchJWT `json:"jwt"`
+}
+
+// MarshalJSON leaves out the token and JWT of a profile that keeps them
+// elsewhere, so they never end up on disk or on screen
+func (p ConchProfile) MarshalJSON() ([]byte, error) {
+	type profile ConchProfile
+	out := profile(p)
+	if p.keepsSecretsElsewhere() {
+		out.Token = ""
+		out.JWT = conch.ConchJWT{}
+	}
+	return json.Marshal(out)
+}
+
+func (p *ConchProfile) keepsSecretsElsewhere() bool {
+	return (p.CredentialHelper != "") || (p.TokenRef != "")
+}
+
+func (p *ConchProfile) helperRequest() secrets.HelperRequest {
+	return secrets.HelperRequest{
+		Profile: p.Name,
+		URL:     p.BaseURL,
+		User:    p.User,
+	}
+}
+
+// credentials converts the profile's secrets for a credential helper
+func (p *ConchProfile) credentials() secrets.Credentials {
+	creds := secrets.Credentials{Token: string(p.Token)}
+	if p.JWT.Token != "" {
+		creds.JWT = p.JWT.FullToken()
+	}
+	return creds
+}
+
+// splitTokenRef splits a reference like "secret-service:production" into
+// the store name and the key within it
+func splitTokenRef(ref string) (string, string, error) {
+	bits := strings.SplitN(ref, ":", 2)
+	if (len(bits) != 2) || (bits[0] == "") || (bits[1] == "") {
+		return "", "", fmt.Errorf("malformed token_ref '%s'", ref)
+	}
+	return bits[0], bits[1], nil
+}
+
+func (c *ConchConfig) secretStore(name string) (secrets.Store, error) {
+	if c.Secrets == nil {
+		return nil, errors.New("no secret stores are configured")
+	}
+	return c.Secrets.Open(name)
+}
+
+// LoadSecrets fills in the token and JWT of a profile that keeps them in a
+// secret store or with a credential helper. Profiles that keep them in the
+// config file are left alone.
+func (c *ConchConfig) LoadSecrets(p *ConchProfile) error {
+	if !p.keepsSecretsElsewhere() || p.secretsLoaded {
+		return nil
+	}
+
+	raw, err := c.fetchSecrets(p)
+	if err == secrets.ErrNotFound {
+		// Nothing to load, but the profile may still get new secrets
+		// from a login
+		p.secretsLoaded = true
+		return fmt.Errorf("profile '%s': no secrets found. Please log in again", p.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("profile '%s': %w", p.Name, err)
+	}
+
+	var s profileSecrets
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return fmt.Errorf("profile '%s': %s", p.Name, err)
+	}
+	p.Token = Token(s.Token)
+	p.JWT = s.JWT
+	p.secretsLoaded = true
+	p.storedSecrets = raw
+	return nil
+}
+
+// fetchSecrets gets the profile's secrets, as stored in a secret store
+func (c *ConchConfig) fetchSecrets(p *ConchProfile) (string, error) {
+	if p.CredentialHelper != "" {
+		creds, err := secrets.Helper{Command: p.CredentialHelper}.Get(p.helperRequest())
+		if err != nil {
+			return "", err
+		}
+
+		s := profileSecrets{Token: creds.Token}
+		if creds.JWT != "" {
+			bits := strings.Split(creds.JWT, ".")
+			if len(bits) != 3 {
+				return "", conch.ErrMalformedJWT
+			}
+			if s.JWT, err = parseJWT(bits[0]+"."+bits[1], bits[2]); err != nil {
+				return "", err
+			}
+		}
+
+		j, err := json.Marshal(s)
+		return string(j), err
+	}
+
+	name, key, err := splitTokenRef(p.TokenRef)
+	if err != nil {
+		return "", err
+	}
+	store, err := c.secretStore(name)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(key)
+}
+
+// MoveSecrets moves the token and JWT of a profile into the named store, or
+// back into the config file if store is empty. The copy left behind is
+// removed when the config is next written. Profiles using a credential
+// helper stop doing so.
+func (c *ConchConfig) MoveSecrets(p *ConchProfile, store string) error {
+	if err := c.LoadSecrets(p); err != nil {
+		return err
+	}
+
+	ref := ""
+	if store != "" {
+		if c.Secrets == nil {
+			return errors.New("no secret stores are configured")
+		}
+		ref = c.Secrets.Resolve(store) + ":" + p.Name
+	}
+	if (ref == p.TokenRef) && (p.CredentialHelper == "") {
+		return nil
+	}
+
+	old := *p
+	p.CredentialHelper = ""
+	p.TokenRef = ref
+	p.storedSecrets = ""
+	if err := c.saveSecrets(p); err != nil {
+		*p = old
+		return err
+	}
+
+	c.forget(old)
+	p.secretsLoaded = p.keepsSecretsElsewhere()
+	return nil
+}
+
+// UseCredentialHelper hands the token and JWT of a profile over to a
+// credential helper. If the helper already has credentials for the
+// profile, they replace the profile's own. Otherwise, the helper is given
+// the profile's credentials to keep on the next write of the config.
+func (c *ConchConfig) UseCredentialHelper(p *ConchProfile, command string) error {
+	if command == "" {
+		return c.MoveSecrets(p, c.SecretStore)
+	}
+
+	// Failing to load the old secrets is fine, the helper may have them
+	_ = c.LoadSecrets(p)
+
+	old := *p
+	p.CredentialHelper = command
+	p.TokenRef = ""
+	p.secretsLoaded = false
+	p.storedSecrets = ""
+
+	err := c.LoadSecrets(p)
+	if (err != nil) && (p.secretsLoaded) {
+		// The helper has nothing for us yet. Keep what we had.
+		p.Token = old.Token
+		p.JWT = old.JWT
+		err = nil
+	}
+	if err != nil {
+		*p = old
+		return err
+	}
+
+	if (old.TokenRef != "") || ((old.CredentialHelper != "") && (old.CredentialHelper != command)) {
+		c.forget(old)
+	}
+	return nil
+}
+
+// ForgetSecrets drops the stored token and JWT of a profile that is about
+// to get new ones, without asking the store for the old ones. The new ones
+// go to SecretStore, or the config file, on the next write.
+func (c *ConchConfig) ForgetSecrets(p *ConchProfile) {
+	if p.keepsSecretsElsewhere() {
+		c.forget(*p)
+	}
+	p.TokenRef = ""
+	p.CredentialHelper = ""
+	p.Token = ""
+	p.JWT = conch.ConchJWT{}
+	p.secretsLoaded = false
+	p.storedSecrets = ""
+}
+
+// DeleteProfile removes a profile. Its secrets, if kept elsewhere, are
+// removed when the config is next written.
+func (c *ConchConfig) DeleteProfile(name string) {
+	if p, ok := c.Profiles[name]; ok && p.keepsSecretsElsewhere() {
+		c.forget(*p)
+	}
+	delete(c.Profiles, name)
+}
+
+// forget queues the secrets of a profile, as it was, for removal
+func (c *ConchConfig) forget(p ConchProfile) {
+	if p.keepsSecretsElsewhere() {
+		c.stale = append(c.stale, p)
+	}
+}
+
+// saveSecrets saves the profile's secrets wherever it keeps them, unless
+// they haven't changed
+func (c *ConchConfig) saveSecrets(p *ConchProfile) error {
+	if !p.keepsSecretsElsewhere() {
+		return nil
+	}
+
+	j, err := json.Marshal(profileSecrets{Token: string(p.Token), JWT: p.JWT})
+	if err != nil {
+		return err
+	}
+	if string(j) == p.storedSecrets {
+		return nil
+	}
+
+	if p.CredentialHelper != "" {
+		err = secrets.Helper{Command: p.CredentialHelper}.Store(p.helperRequest(), p.credentials())
+	} else {
+		var name, key string
+		var store secrets.Store
+
+		if name, key, err = splitTokenRef(p.TokenRef); err != nil {
+			return err
+		}
+		if store, err = c.secretStore(name); err != nil {
+			return err
+		}
+		err = store.Set(key, string(j))
+	}
+	if err != nil {
+		return fmt.Errorf("profile '%s': %w", p.Name, err)
+	}
+
+	p.storedSecrets = string(j)
+	return nil
+}
+
+// storeSecrets puts the secrets of every profile where they belong before
+// the config is written. With SecretStore set, profiles that still keep
+// their secrets in the config file are moved there first.
+func (c *ConchConfig) storeSecrets() error {
+	for _, p := range c.Profiles {
+		if !p.keepsSecretsElsewhere() && (c.SecretStore != "") &&
+			((p.Token != "") || (p.JWT.Token != "")) {
+			if err := c.MoveSecrets(p, c.SecretStore); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Secrets that were never loaded can't have changed. Those that
+		// were are only saved if they did.
+		if p.secretsLoaded {
+			if err := c.saveSecrets(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dropStaleSecrets removes secrets left behind by MoveSecrets,
+// ForgetSecrets, and DeleteProfile, once the config no longer points at
+// them
+func (c *ConchConfig) dropStaleSecrets() {
+	inUse := make(map[string]bool)
+	for _, p := range c.Profiles {
+		inUse[p.TokenRef] = true
+		inUse["helper:"+p.Name+":"+p.CredentialHelper] = true
+	}
+
+	for _, p := range c.stale {
+		if p.CredentialHelper != "" {
+			if !inUse["helper:"+p.Name+":"+p.CredentialHelper] {
+				_ = secrets.Helper{Command: p.CredentialHelper}.Erase(p.helperRequest())
+			}
+			continue
+		}
+
+		if inUse[p.TokenRef] {
+			continue
+		}
+		name, key, err := splitTokenRef(p.TokenRef)
+		if err != nil {
+			continue
+		}
+		if store, err := c.secretStore(name); err == nil {
+			_ = store.Delete(key)
+		}
+	}
+	c.stale = nil
+}
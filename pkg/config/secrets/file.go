@@ -0,0 +1,231 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters for new files, as recommended for interactive logins
+const (
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+)
+
+// Bounds on the scrypt parameters of existing files. A file asking for more
+// would take minutes, or gigabytes, to open, and one asking for less is not
+// worth the passphrase.
+const (
+	minScryptN = 1 << 14
+	maxScryptN = 1 << 20
+	maxScryptR = 32
+	maxScryptP = 16
+	minSaltLen = 16
+)
+
+// fileContents is what the encrypted file holds on disk. Data is the JSON
+// map of keys to secrets, sealed with AES-256-GCM under a key derived from
+// the passphrase via scrypt.
+type fileContents struct {
+	KDF   string `json:"kdf"`
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// encryptedFile keeps secrets in a file encrypted with a passphrase, for
+// machines without a Secret Service. The whole file is decrypted on first
+// use and rewritten on every change.
+type encryptedFile struct {
+	path       string
+	passphrase func(create bool) (string, error)
+
+	mu      sync.Mutex
+	loaded  bool
+	aead    cipher.AEAD
+	header  fileContents
+	secrets map[string]string
+}
+
+func (f *encryptedFile) Name() string {
+	return File
+}
+
+func (f *encryptedFile) Get(key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return "", err
+	}
+	secret, ok := f.secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (f *encryptedFile) Set(key string, secret string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return err
+	}
+	f.secrets[key] = secret
+	return f.save()
+}
+
+func (f *encryptedFile) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return err
+	}
+	if _, ok := f.secrets[key]; !ok {
+		return nil
+	}
+	delete(f.secrets, key)
+	return f.save()
+}
+
+// load reads and decrypts the file, or sets up a new one if there is none
+func (f *encryptedFile) load() error {
+	if f.loaded {
+		return nil
+	}
+	if f.passphrase == nil {
+		return errors.New("no way to ask for the passphrase of the secrets file")
+	}
+
+	raw, err := ioutil.ReadFile(f.path)
+	create := os.IsNotExist(err)
+	if err != nil && !create {
+		return err
+	}
+
+	if create {
+		f.header = fileContents{KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP}
+		f.header.Salt = make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, f.header.Salt); err != nil {
+			return err
+		}
+	} else if err := json.Unmarshal(raw, &f.header); err != nil {
+		return fmt.Errorf("%s: %s", f.path, err)
+	}
+	if err := f.header.check(); err != nil {
+		return fmt.Errorf("%s: %s", f.path, err)
+	}
+
+	passphrase, err := f.passphrase(create)
+	if err != nil {
+		return err
+	}
+	key, err := scrypt.Key(
+		[]byte(passphrase),
+		f.header.Salt,
+		f.header.N,
+		f.header.R,
+		f.header.P,
+		32,
+	)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	if f.aead, err = cipher.NewGCM(block); err != nil {
+		return err
+	}
+
+	f.secrets = make(map[string]string)
+	if !create {
+		if len(f.header.Nonce) != f.aead.NonceSize() {
+			return fmt.Errorf(
+				"%s: the nonce is %d bytes long, rather than %d",
+				f.path,
+				len(f.header.Nonce),
+				f.aead.NonceSize(),
+			)
+		}
+		plain, err := f.aead.Open(nil, f.header.Nonce, f.header.Data, nil)
+		if err != nil {
+			return ErrBadPassphrase
+		}
+		if err := json.Unmarshal(plain, &f.secrets); err != nil {
+			return fmt.Errorf("%s: %s", f.path, err)
+		}
+	}
+
+	f.loaded = true
+	return nil
+}
+
+// check makes sure the key derivation of a file is one we know, with
+// parameters that are within bounds
+func (c fileContents) check() error {
+	if c.KDF != "scrypt" {
+		return fmt.Errorf("unknown key derivation '%s'", c.KDF)
+	}
+	if (c.N < minScryptN) || (c.N > maxScryptN) || (c.N&(c.N-1) != 0) {
+		return fmt.Errorf(
+			"scrypt N must be a power of two from %d to %d, got %d",
+			minScryptN,
+			maxScryptN,
+			c.N,
+		)
+	}
+	if (c.R < 1) || (c.R > maxScryptR) {
+		return fmt.Errorf("scrypt r must be from 1 to %d, got %d", maxScryptR, c.R)
+	}
+	if (c.P < 1) || (c.P > maxScryptP) {
+		return fmt.Errorf("scrypt p must be from 1 to %d, got %d", maxScryptP, c.P)
+	}
+	if len(c.Salt) < minSaltLen {
+		return fmt.Errorf("the salt must be at least %d bytes long, got %d", minSaltLen, len(c.Salt))
+	}
+	return nil
+}
+
+// save encrypts the secrets under a fresh nonce and writes the file
+func (f *encryptedFile) save() error {
+	plain, err := json.Marshal(f.secrets)
+	if err != nil {
+		return err
+	}
+
+	contents := f.header
+	contents.Nonce = make([]byte, f.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, contents.Nonce); err != nil {
+		return err
+	}
+	contents.Data = f.aead.Seal(nil, contents.Nonce, plain, nil)
+
+	j, err := json.MarshalIndent(contents, "", "	")
+	if err != nil {
+		return err
+	}
+	return WriteFile(f.path, j)
+}
@@ -0,0 +1,98 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package secrets_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/config/secrets"
+	"github.com/nbio/st"
+)
+
+func openFile(t *testing.T, path string, passphrase string) secrets.Store {
+	s := &secrets.Stores{
+		FilePath:   path,
+		Passphrase: func(bool) (string, error) { return passphrase, nil },
+	}
+	store, err := s.Open(secrets.File)
+	st.Expect(t, err, nil)
+	return store
+}
+
+func TestEncryptedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "conch-secrets")
+	st.Expect(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "secrets")
+	store := openFile(t, path, "hunter2")
+	st.Expect(t, store.Set("prod", "token1"), nil)
+	st.Expect(t, store.Set("staging", "token2"), nil)
+	st.Expect(t, store.Delete("staging"), nil)
+
+	raw, err := ioutil.ReadFile(path)
+	st.Expect(t, err, nil)
+	st.Expect(t, strings.Contains(string(raw), "token1"), false)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		store := openFile(t, path, "hunter2")
+
+		secret, err := store.Get("prod")
+		st.Expect(t, err, nil)
+		st.Expect(t, secret, "token1")
+
+		_, err = store.Get("staging")
+		st.Expect(t, err, secrets.ErrNotFound)
+	})
+
+	t.Run("WrongPassphrase", func(t *testing.T) {
+		_, err := openFile(t, path, "hunter3").Get("prod")
+		st.Expect(t, err, secrets.ErrBadPassphrase)
+	})
+
+	t.Run("CorruptHeader", func(t *testing.T) {
+		tests := map[string]func(h map[string]interface{}){
+			"kdf":       func(h map[string]interface{}) { h["kdf"] = "rot13" },
+			"short n":   func(h map[string]interface{}) { h["n"] = 2 },
+			"huge n":    func(h map[string]interface{}) { h["n"] = 1 << 30 },
+			"uneven n":  func(h map[string]interface{}) { h["n"] = 30000 },
+			"zero r":    func(h map[string]interface{}) { h["r"] = 0 },
+			"huge r":    func(h map[string]interface{}) { h["r"] = 1 << 20 },
+			"zero p":    func(h map[string]interface{}) { h["p"] = 0 },
+			"huge p":    func(h map[string]interface{}) { h["p"] = 1 << 20 },
+			"salt":      func(h map[string]interface{}) { h["salt"] = "" },
+			"nonce":     func(h map[string]interface{}) { h["nonce"] = "AAAA" },
+			"no nonce":  func(h map[string]interface{}) { delete(h, "nonce") },
+			"truncated": func(h map[string]interface{}) { h["data"] = "AAAA" },
+		}
+
+		for name, corrupt := range tests {
+			t.Run(name, func(t *testing.T) {
+				header := make(map[string]interface{})
+				st.Expect(t, json.Unmarshal(raw, &header), nil)
+				corrupt(header)
+
+				j, err := json.Marshal(header)
+				st.Expect(t, err, nil)
+				bad := filepath.Join(dir, "bad")
+				st.Expect(t, ioutil.WriteFile(bad, j, 0600), nil)
+
+				_, err = openFile(t, bad, "hunter2").Get("prod")
+				st.Reject(t, err, nil)
+			})
+		}
+
+		st.Expect(t, ioutil.WriteFile(filepath.Join(dir, "garbage"), []byte("{"), 0600), nil)
+		_, err := openFile(t, filepath.Join(dir, "garbage"), "hunter2").Get("prod")
+		st.Reject(t, err, nil)
+	})
+}
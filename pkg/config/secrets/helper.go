@@ -0,0 +1,151 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Actions a credential helper is asked to perform
+const (
+	HelperGet   = "get"
+	HelperStore = "store"
+	HelperErase = "erase"
+)
+
+// HelperActionEnv names the environment variable that tells a credential
+// helper which action to perform, for helpers that don't read stdin
+const HelperActionEnv = "CONCH_CREDENTIAL_ACTION"
+
+// HelperUnsupported is what a credential helper prints on stderr, before
+// exiting non-zero, when it can't perform an action
+const HelperUnsupported = "unsupported"
+
+// Credentials are what a credential helper keeps for a profile. Either may
+// be empty.
+type Credentials struct {
+	// Token is an API token
+	Token string `json:"token,omitempty"`
+
+	// JWT is the token of a login, in its compact form
+	JWT string `json:"jwt,omitempty"`
+}
+
+// IsZero reports whether there are no credentials
+func (c Credentials) IsZero() bool {
+	return c == Credentials{}
+}
+
+// HelperRequest is written to a credential helper's stdin as JSON. For
+// store, it carries the credentials to keep.
+type HelperRequest struct {
+	Action  string `json:"action"`
+	Profile string `json:"profile"`
+	URL     string `json:"url"`
+	User    string `json:"user,omitempty"`
+
+	Credentials
+}
+
+// Helper is an external command that keeps credentials on the shell's
+// behalf, like git's credential helpers. The command line is run by sh,
+// with a HelperRequest on stdin and the action in HelperActionEnv as well.
+//
+// For get, the helper prints the credentials as JSON. Output that isn't
+// JSON is taken to be a bare API token, so that "pass show conch/prod"
+// works as it is. Printing nothing means there are no credentials. Store
+// and erase need not print anything.
+//
+// Failures are signaled with a non-zero exit and a message on stderr. A
+// helper that can't perform an action, like one that can only read,
+// prints HelperUnsupported, which is reported as ErrUnsupported. Exiting 0
+// without storing is not caught here, so callers that need a store to
+// stick read the credentials back.
+type Helper struct {
+	Command string
+}
+
+// Get asks the helper for credentials
+func (h Helper) Get(req HelperRequest) (Credentials, error) {
+	req.Action = HelperGet
+	req.Credentials = Credentials{}
+
+	out, err := h.run(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return Credentials{}, ErrNotFound
+	}
+
+	var creds Credentials
+	if strings.HasPrefix(out, "{") {
+		if err := json.Unmarshal([]byte(out), &creds); err != nil {
+			return creds, fmt.Errorf("credential helper '%s': %s", h.Command, err)
+		}
+		if creds.IsZero() {
+			return creds, ErrNotFound
+		}
+		return creds, nil
+	}
+
+	// Password managers tend to print the secret on the first line and
+	// notes after it
+	creds.Token = strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+	return creds, nil
+}
+
+// Store hands credentials to the helper to keep
+func (h Helper) Store(req HelperRequest, creds Credentials) error {
+	req.Action = HelperStore
+	req.Credentials = creds
+	_, err := h.run(req)
+	return err
+}
+
+// Erase asks the helper to forget the credentials
+func (h Helper) Erase(req HelperRequest) error {
+	req.Action = HelperErase
+	req.Credentials = Credentials{}
+	_, err := h.run(req)
+	return err
+}
+
+func (h Helper) run(req HelperRequest) (string, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("sh", "-c", h.Command)
+	cmd.Env = append(os.Environ(), HelperActionEnv+"="+req.Action)
+	cmd.Stdin = bytes.NewReader(append(in, '\n'))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == HelperUnsupported {
+			return "", fmt.Errorf("credential helper '%s' can't %s: %w", h.Command, req.Action, ErrUnsupported)
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("credential helper '%s' failed to %s: %s", h.Command, req.Action, msg)
+	}
+
+	return stdout.String(), nil
+}
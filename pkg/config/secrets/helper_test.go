@@ -0,0 +1,40 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package secrets_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/config/secrets"
+	"github.com/nbio/st"
+)
+
+func TestHelperUnsupported(t *testing.T) {
+	// Like a wrapped "pass show", which can only read
+	h := secrets.Helper{Command: `
+		[ "$CONCH_CREDENTIAL_ACTION" = get ] || { echo unsupported >&2; exit 1; }
+		echo token1
+	`}
+	req := secrets.HelperRequest{Profile: "prod"}
+
+	creds, err := h.Get(req)
+	st.Expect(t, err, nil)
+	st.Expect(t, creds, secrets.Credentials{Token: "token1"})
+
+	err = h.Store(req, secrets.Credentials{Token: "token2"})
+	st.Expect(t, errors.Is(err, secrets.ErrUnsupported), true)
+
+	err = h.Erase(req)
+	st.Expect(t, errors.Is(err, secrets.ErrUnsupported), true)
+
+	// Other failures are just failures
+	err = secrets.Helper{Command: "echo broken >&2; exit 1"}.Store(req, creds)
+	st.Reject(t, err, nil)
+	st.Expect(t, errors.Is(err, secrets.ErrUnsupported), false)
+	st.Expect(t, err.Error(), "credential helper 'echo broken >&2; exit 1' failed to store: broken")
+}
@@ -0,0 +1,151 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package secrets keeps API tokens and JWTs somewhere safer than the
+// shell's config file: the OS secret store, a file encrypted with a
+// passphrase, or an external credential helper.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Names of the stores known to Stores.Open
+const (
+	SecretService = "secret-service"
+	File          = "file"
+
+	// Auto picks SecretService if it is available, and File otherwise
+	Auto = "auto"
+)
+
+var (
+	// ErrNotFound is returned by Store.Get for a key that holds no secret
+	ErrNotFound = errors.New("secret not found")
+
+	// ErrUnavailable is returned when a store can't be used on this
+	// machine, like the Secret Service outside of a desktop session
+	ErrUnavailable = errors.New("secret store is not available")
+
+	// ErrBadPassphrase is returned when the passphrase does not open the
+	// encrypted file
+	ErrBadPassphrase = errors.New("wrong passphrase for the secrets file")
+
+	// ErrUnsupported is returned when a credential helper says it can't
+	// perform an action, like storing for a helper that can only read
+	ErrUnsupported = errors.New("not supported by the credential helper")
+)
+
+// Store keeps secrets by key
+type Store interface {
+	// Name is what Stores.Open knows the store by
+	Name() string
+
+	Get(key string) (string, error)
+	Set(key string, secret string) error
+	Delete(key string) error
+}
+
+// Stores opens the stores by name and keeps them open, so that the
+// passphrase of the encrypted file is only asked for once
+type Stores struct {
+	// FilePath is where the File store lives
+	FilePath string
+
+	// Passphrase provides the passphrase of the File store. It is told
+	// whether the file is about to be created, in which case it ought to
+	// have the user confirm what they typed.
+	Passphrase func(create bool) (string, error)
+
+	mu   sync.Mutex
+	open map[string]Store
+}
+
+// Open returns the store of the given name
+func (s *Stores) Open(name string) (Store, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name == Auto {
+		name = s.auto()
+	}
+
+	if store, ok := s.open[name]; ok {
+		return store, nil
+	}
+
+	var store Store
+	switch name {
+	case SecretService:
+		if !secretServiceAvailable() {
+			return nil, fmt.Errorf("%s: %w", name, ErrUnavailable)
+		}
+		store = &secretService{}
+	case File:
+		if s.FilePath == "" {
+			return nil, fmt.Errorf("%s: %w", name, ErrUnavailable)
+		}
+		store = &encryptedFile{path: s.FilePath, passphrase: s.Passphrase}
+	default:
+		return nil, fmt.Errorf("unknown secret store '%s'", name)
+	}
+
+	if s.open == nil {
+		s.open = make(map[string]Store)
+	}
+	s.open[name] = store
+	return store, nil
+}
+
+// Resolve turns Auto into the name of the store it stands for on this
+// machine. Other names come back as they are.
+func (s *Stores) Resolve(name string) string {
+	if name != Auto {
+		return name
+	}
+	return s.auto()
+}
+
+func (s *Stores) auto() string {
+	if secretServiceAvailable() {
+		return SecretService
+	}
+	return File
+}
+
+// WriteFile writes data to path so that only the user can read it. The
+// data goes to a temporary file first, which then replaces path, so a
+// failed write never leaves a truncated file behind.
+func WriteFile(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
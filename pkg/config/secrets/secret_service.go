@@ -0,0 +1,88 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// service is the attribute every secret of ours is filed under
+const service = "conch-shell"
+
+// secretService talks to the freedesktop.org Secret Service, as provided by
+// GNOME Keyring or KWallet, through libsecret's secret-tool
+type secretService struct{}
+
+// secretServiceAvailable reports whether secret-tool is installed and there
+// is a session bus for it to reach the service on
+func secretServiceAvailable() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (s *secretService) Name() string {
+	return SecretService
+}
+
+func (s *secretService) Get(key string) (string, error) {
+	out, err := s.run("", "lookup", "service", service, "key", key)
+	if err != nil {
+		return "", err
+	}
+	// secret-tool says nothing, and fails, when there is no such secret
+	if out == "" {
+		return "", ErrNotFound
+	}
+	return out, nil
+}
+
+func (s *secretService) Set(key string, secret string) error {
+	_, err := s.run(
+		secret,
+		"store",
+		"--label", "Conch Shell: "+key,
+		"service", service,
+		"key", key,
+	)
+	return err
+}
+
+func (s *secretService) Delete(key string) error {
+	_, err := s.run("", "clear", "service", service, "key", key)
+	return err
+}
+
+// run runs secret-tool, with the secret, if any, on stdin so that it never
+// shows up in the process list
+func (s *secretService) run(stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if (args[0] == "lookup") && (stdout.Len() == 0) && (stderr.Len() == 0) {
+			return "", nil
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("secret-tool %s: %s", args[0], msg)
+	}
+
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
@@ -0,0 +1,258 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joyent/conch-shell/pkg/config"
+	"github.com/joyent/conch-shell/pkg/config/secrets"
+	"github.com/nbio/st"
+)
+
+const testToken = "token1"
+
+// newConfig returns a config in dir with a profile named prod, which keeps
+// its token in the config file
+func newConfig(t *testing.T, dir string) (*config.ConchConfig, *config.ConchProfile) {
+	c := config.New()
+	c.Path = filepath.Join(dir, "conch.json")
+	c.Secrets = stores(dir)
+
+	p := &config.ConchProfile{Name: "prod", BaseURL: "https://conch.test", Token: testToken}
+	c.Profiles[p.Name] = p
+	st.Expect(t, c.SerializeToFile(c.Path), nil)
+	return c, p
+}
+
+// stores opens the secret stores afresh, so that the file is read again
+func stores(dir string) *secrets.Stores {
+	return &secrets.Stores{
+		FilePath:   filepath.Join(dir, "secrets.json"),
+		Passphrase: func(bool) (string, error) { return "hunter2", nil },
+	}
+}
+
+// reload reads the config back from disk
+func reload(t *testing.T, c *config.ConchConfig) *config.ConchConfig {
+	saved, err := config.NewFromJSONFile(c.Path)
+	st.Expect(t, err, nil)
+	saved.Secrets = stores(filepath.Dir(c.Path))
+	return saved
+}
+
+// inFile reports whether the File store has a secret for the key
+func inFile(t *testing.T, dir string, key string) bool {
+	store, err := stores(dir).Open(secrets.File)
+	st.Expect(t, err, nil)
+
+	_, err = store.Get(key)
+	if err == secrets.ErrNotFound {
+		return false
+	}
+	st.Expect(t, err, nil)
+	return true
+}
+
+// helper returns the command line of a credential helper that keeps
+// credentials in dir
+func helper(t *testing.T, dir string) string {
+	script, err := filepath.Abs(filepath.Join("testdata", "helper.sh"))
+	st.Expect(t, err, nil)
+	return "sh '" + script + "' '" + dir + "'"
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "conch-config")
+	st.Expect(t, err, nil)
+	return dir
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestMoveSecrets(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []string
+	}{
+		{"IntoStore", []string{secrets.File}},
+		{"BackOut", []string{secrets.File, ""}},
+		{"InAgain", []string{secrets.File, "", secrets.File}},
+		{"Nowhere", []string{""}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer os.RemoveAll(dir)
+			c, p := newConfig(t, dir)
+
+			for _, store := range test.moves {
+				st.Expect(t, c.MoveSecrets(p, store), nil)
+				st.Expect(t, c.SerializeToFile(c.Path), nil)
+
+				saved := reload(t, c)
+				sp := saved.Profiles["prod"]
+				if store == "" {
+					st.Expect(t, sp.TokenRef, "")
+					st.Expect(t, string(sp.Token), testToken)
+				} else {
+					st.Expect(t, sp.TokenRef, "file:prod")
+					st.Expect(t, string(sp.Token), "")
+				}
+
+				// The copy left behind is gone
+				st.Expect(t, inFile(t, dir, "prod"), store != "")
+
+				st.Expect(t, saved.LoadSecrets(sp), nil)
+				st.Expect(t, string(sp.Token), testToken)
+			}
+		})
+	}
+}
+
+func TestUseCredentialHelper(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a", "b"} {
+		st.Expect(t, os.Mkdir(filepath.Join(dir, name), 0700), nil)
+	}
+
+	// Each step switches to a helper, or back to the config file, and
+	// only the one in use ends up with the credentials
+	tests := []struct {
+		name   string
+		helper string
+	}{
+		{"First", "a"},
+		{"Same", "a"},
+		{"Other", "b"},
+		{"ConfigFile", ""},
+		{"Again", "a"},
+	}
+
+	c, p := newConfig(t, dir)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command := ""
+			if test.helper != "" {
+				command = helper(t, filepath.Join(dir, test.helper))
+			}
+
+			st.Expect(t, c.UseCredentialHelper(p, command), nil)
+			st.Expect(t, c.SerializeToFile(c.Path), nil)
+
+			for _, name := range []string{"a", "b"} {
+				st.Expect(t, exists(filepath.Join(dir, name, "prod")), name == test.helper)
+			}
+
+			saved := reload(t, c)
+			sp := saved.Profiles["prod"]
+			st.Expect(t, sp.CredentialHelper, command)
+			st.Expect(t, saved.LoadSecrets(sp), nil)
+			st.Expect(t, string(sp.Token), testToken)
+		})
+	}
+
+	t.Run("HelperHasCredentials", func(t *testing.T) {
+		// What the helper has replaces the profile's own
+		other := filepath.Join(dir, "b", "prod")
+		st.Expect(t, ioutil.WriteFile(other, []byte(`{"token":"token2"}`), 0600), nil)
+
+		st.Expect(t, c.UseCredentialHelper(p, helper(t, filepath.Join(dir, "b"))), nil)
+		st.Expect(t, string(p.Token), "token2")
+		st.Expect(t, c.SerializeToFile(c.Path), nil)
+		st.Expect(t, exists(filepath.Join(dir, "a", "prod")), false)
+	})
+}
+
+func TestDeleteProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		keep func(c *config.ConchConfig, p *config.ConchProfile, dir string) error
+	}{
+		{
+			name: "ConfigFile",
+			keep: func(*config.ConchConfig, *config.ConchProfile, string) error { return nil },
+		},
+		{
+			name: "Store",
+			keep: func(c *config.ConchConfig, p *config.ConchProfile, _ string) error {
+				return c.MoveSecrets(p, secrets.File)
+			},
+		},
+		{
+			name: "Helper",
+			keep: func(c *config.ConchConfig, p *config.ConchProfile, dir string) error {
+				return c.UseCredentialHelper(p, helper(t, dir))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer os.RemoveAll(dir)
+			c, p := newConfig(t, dir)
+
+			st.Expect(t, test.keep(c, p, dir), nil)
+			st.Expect(t, c.SerializeToFile(c.Path), nil)
+
+			c.DeleteProfile("prod")
+			st.Expect(t, c.SerializeToFile(c.Path), nil)
+
+			_, ok := reload(t, c).Profiles["prod"]
+			st.Expect(t, ok, false)
+			st.Expect(t, inFile(t, dir, "prod"), false)
+			st.Expect(t, exists(filepath.Join(dir, "prod")), false)
+		})
+	}
+}
+
+func TestHelperOutput(t *testing.T) {
+	script, err := filepath.Abs(filepath.Join("testdata", "broken-helper.sh"))
+	st.Expect(t, err, nil)
+
+	tests := []struct {
+		mode  string
+		token string
+		err   string
+	}{
+		{mode: "nothing", err: "profile 'prod': no secrets found. Please log in again"},
+		{mode: "bad-json", err: "unexpected end of JSON input"},
+		{mode: "text", token: "token1"},
+		{mode: "fail", err: "failed to get: vault is sealed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			c := config.New()
+			p := &config.ConchProfile{
+				Name:             "prod",
+				CredentialHelper: "sh '" + script + "' " + test.mode,
+			}
+			c.Profiles[p.Name] = p
+
+			err := c.LoadSecrets(p)
+			if test.err == "" {
+				st.Expect(t, err, nil)
+			} else {
+				st.Reject(t, err, nil)
+				st.Expect(t, strings.Contains(err.Error(), test.err), true)
+			}
+			st.Expect(t, string(p.Token), test.token)
+		})
+	}
+}
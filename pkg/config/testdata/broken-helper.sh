@@ -0,0 +1,10 @@
+#!/bin/sh
+# A credential helper for the tests, which answers get in the way it is told
+cat > /dev/null
+
+case "$1" in
+nothing) ;;
+bad-json) echo '{"token":' ;;
+text) printf 'token1\nnotes about it\n' ;;
+fail) echo 'vault is sealed' >&2; exit 2 ;;
+esac
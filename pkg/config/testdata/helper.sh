@@ -0,0 +1,12 @@
+#!/bin/sh
+# A credential helper for the tests, which keeps each profile's request in
+# a file of that name, in the directory it is given
+dir=$1
+req=$(cat)
+profile=$(echo "$req" | sed -n 's/.*"profile":"\([^"]*\)".*/\1/p')
+
+case "$CONCH_CREDENTIAL_ACTION" in
+get) [ ! -f "$dir/$profile" ] || cat "$dir/$profile" ;;
+store) echo "$req" > "$dir/$profile" ;;
+erase) rm -f "$dir/$profile" ;;
+esac
@@ -0,0 +1,623 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a JSONPath template in the style of kubectl: text, with
+// expressions like {.[*].asset_tag} in braces. {range EXPR}...{end} repeats
+// what is in between for each result of EXPR, and {"\n"} is a literal.
+//
+// Expressions start at the data, or with $ at the top of it from within a
+// range. They support .name, ['name'], .*, [*], [n], [start:end],
+// ..name for any depth, and filters like [?(@.health=="FAIL")].
+type jsonPath struct {
+	nodes []jpNode
+}
+
+// jpNode is a piece of a template. Exactly one of its fields is set.
+type jpNode struct {
+	text  *string
+	path  *jpPath
+	rng   *jpPath
+	nodes []jpNode // the body of a range
+}
+
+// parseJSONPath parses a template
+func parseJSONPath(tmpl string) (*jsonPath, error) {
+	stack := [][]jpNode{nil}
+	ranges := []*jpPath{}
+
+	for len(tmpl) > 0 {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			start = len(tmpl)
+		}
+		if start > 0 {
+			text := tmpl[:start]
+			stack[len(stack)-1] = append(stack[len(stack)-1], jpNode{text: &text})
+			tmpl = tmpl[start:]
+			continue
+		}
+
+		end := closingBrace(tmpl)
+		if end < 0 {
+			return nil, errors.New("jsonpath: unclosed '{'")
+		}
+		expr := strings.TrimSpace(tmpl[1:end])
+		tmpl = tmpl[end+1:]
+
+		switch {
+		case expr == "end":
+			if len(ranges) == 0 {
+				return nil, errors.New("jsonpath: {end} without {range}")
+			}
+			body := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			node := jpNode{rng: ranges[len(ranges)-1], nodes: body}
+			ranges = ranges[:len(ranges)-1]
+			stack[len(stack)-1] = append(stack[len(stack)-1], node)
+
+		case strings.HasPrefix(expr, "range "):
+			p, err := parsePath(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, p)
+			stack = append(stack, nil)
+
+		case strings.HasPrefix(expr, `"`) || strings.HasPrefix(expr, "'"):
+			text, err := unquote(expr)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath: bad literal %s", expr)
+			}
+			stack[len(stack)-1] = append(stack[len(stack)-1], jpNode{text: &text})
+
+		default:
+			p, err := parsePath(expr)
+			if err != nil {
+				return nil, err
+			}
+			stack[len(stack)-1] = append(stack[len(stack)-1], jpNode{path: p})
+		}
+	}
+
+	if len(ranges) > 0 {
+		return nil, errors.New("jsonpath: {range} without {end}")
+	}
+	return &jsonPath{nodes: stack[0]}, nil
+}
+
+// closingBrace finds the brace closing the one s starts with, skipping any
+// in quotes
+func closingBrace(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"') || (c == '\''):
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote reads a string literal in double quotes, as Go has them, or in
+// single quotes, where \' is a quote
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && (len(s) >= 2) {
+		var b strings.Builder
+		b.WriteByte('"')
+		for i := 1; i < len(s)-1; i++ {
+			switch c := s[i]; {
+			case (c == '\\') && (i+1 < len(s)-1):
+				i++
+				if s[i] != '\'' {
+					b.WriteByte('\\')
+				}
+				b.WriteByte(s[i])
+			case c == '"':
+				b.WriteString(`\"`)
+			default:
+				b.WriteByte(c)
+			}
+		}
+		b.WriteByte('"')
+		s = b.String()
+	}
+	return strconv.Unquote(s)
+}
+
+// execute writes the template out for the data, which must be as decoded
+// by encoding/json, with numbers as json.Number
+func (p *jsonPath) execute(w io.Writer, data interface{}) error {
+	return executeNodes(w, p.nodes, data, data)
+}
+
+func executeNodes(w io.Writer, nodes []jpNode, root interface{}, current interface{}) error {
+	for _, node := range nodes {
+		switch {
+		case node.text != nil:
+			if _, err := io.WriteString(w, *node.text); err != nil {
+				return err
+			}
+
+		case node.path != nil:
+			results := node.path.eval(root, current)
+			strs := make([]string, 0, len(results))
+			for _, r := range results {
+				strs = append(strs, jpString(r))
+			}
+			if _, err := io.WriteString(w, strings.Join(strs, " ")); err != nil {
+				return err
+			}
+
+		case node.rng != nil:
+			results := node.rng.eval(root, current)
+			if len(results) == 1 {
+				if list, ok := results[0].([]interface{}); ok {
+					results = list
+				}
+			}
+			for _, r := range results {
+				if err := executeNodes(w, node.nodes, root, r); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// jpString formats a result. Strings come out as they are, anything else as
+// JSON.
+func jpString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	j, _ := json.Marshal(v)
+	return string(j)
+}
+
+// jpPath is a parsed expression
+type jpPath struct {
+	fromRoot bool
+	steps    []jpStep
+}
+
+// jpStep turns each of the values found so far into any number of others
+type jpStep func(v interface{}) []interface{}
+
+func parsePath(expr string) (*jpPath, error) {
+	p := &jpPath{}
+	s := expr
+
+	switch {
+	case strings.HasPrefix(s, "$"):
+		p.fromRoot = true
+		s = s[1:]
+	case strings.HasPrefix(s, "@"):
+		s = s[1:]
+	}
+
+	for len(s) > 0 {
+		if name, _ := splitName(strings.TrimLeft(s, ".")); strings.Contains(name, "]") {
+			return nil, fmt.Errorf("jsonpath: unexpected ']' in '%s'", expr)
+		}
+
+		switch {
+		case strings.HasPrefix(s, ".."):
+			name, rest := splitName(s[2:])
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath: '..' needs a name in '%s'", expr)
+			}
+			p.steps = append(p.steps, descendants(name))
+			s = rest
+
+		case strings.HasPrefix(s, "."):
+			name, rest := splitName(s[1:])
+			switch name {
+			case "":
+				// '.' on its own is the value itself, as in '.[*]'
+			case "*":
+				p.steps = append(p.steps, children)
+			default:
+				p.steps = append(p.steps, field(name))
+			}
+			s = rest
+
+		case strings.HasPrefix(s, "["):
+			end := closingBracket(s)
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath: unclosed '[' in '%s'", expr)
+			}
+			step, err := parseSubscript(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return nil, err
+			}
+			p.steps = append(p.steps, step)
+			s = s[end+1:]
+
+		default:
+			// A bare name, as in 'id' for '.id'
+			name, rest := splitName(s)
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath: unexpected '%s' in '%s'", s, expr)
+			}
+			p.steps = append(p.steps, field(name))
+			s = rest
+		}
+	}
+
+	return p, nil
+}
+
+// splitName splits off the name s starts with. A stray ']' ends up in the
+// name, where parsePath catches it.
+func splitName(s string) (string, string) {
+	i := strings.IndexAny(s, ".[")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// closingBracket finds the bracket closing the one s starts with, allowing
+// for nesting and quotes, as in filters
+func closingBracket(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"') || (c == '\''):
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseSubscript(sub string) (jpStep, error) {
+	switch {
+	case sub == "*":
+		return children, nil
+
+	case strings.HasPrefix(sub, "?(") && strings.HasSuffix(sub, ")"):
+		return parseFilter(strings.TrimSpace(sub[2 : len(sub)-1]))
+
+	case strings.HasPrefix(sub, `"`) || strings.HasPrefix(sub, "'"):
+		name, err := unquote(sub)
+		if err != nil {
+			return nil, fmt.Errorf("jsonpath: bad name [%s]", sub)
+		}
+		return field(name), nil
+
+	case strings.Contains(sub, ":"):
+		bits := strings.SplitN(sub, ":", 2)
+		start, end, err := parseBounds(bits[0], bits[1])
+		if err != nil {
+			return nil, fmt.Errorf("jsonpath: bad slice [%s]", sub)
+		}
+		return slice(start, end), nil
+	}
+
+	i, err := strconv.Atoi(sub)
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath: bad subscript [%s]", sub)
+	}
+	return index(i), nil
+}
+
+func parseBounds(start string, end string) (*int, *int, error) {
+	var s, e *int
+	if start = strings.TrimSpace(start); start != "" {
+		i, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, nil, err
+		}
+		s = &i
+	}
+	if end = strings.TrimSpace(end); end != "" {
+		i, err := strconv.Atoi(end)
+		if err != nil {
+			return nil, nil, err
+		}
+		e = &i
+	}
+	return s, e, nil
+}
+
+func field(name string) jpStep {
+	return func(v interface{}) []interface{} {
+		if obj, ok := v.(map[string]interface{}); ok {
+			if val, ok := obj[name]; ok {
+				return []interface{}{val}
+			}
+		}
+		return nil
+	}
+}
+
+func children(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		ret := make([]interface{}, 0, len(v))
+		for _, k := range keys {
+			ret = append(ret, v[k])
+		}
+		return ret
+	}
+	return nil
+}
+
+func descendants(name string) jpStep {
+	get := field(name)
+	if name == "*" {
+		get = children
+	}
+
+	var walk func(v interface{}) []interface{}
+	walk = func(v interface{}) []interface{} {
+		ret := get(v)
+		for _, child := range children(v) {
+			ret = append(ret, walk(child)...)
+		}
+		return ret
+	}
+	return walk
+}
+
+func index(i int) jpStep {
+	return func(v interface{}) []interface{} {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil
+		}
+		if i < 0 {
+			i += len(list)
+		}
+		if (i < 0) || (i >= len(list)) {
+			return nil
+		}
+		return []interface{}{list[i]}
+	}
+}
+
+func slice(start *int, end *int) jpStep {
+	return func(v interface{}) []interface{} {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		bound := func(b *int, def int) int {
+			if b == nil {
+				return def
+			}
+			i := *b
+			if i < 0 {
+				i += len(list)
+			}
+			if i < 0 {
+				return 0
+			}
+			if i > len(list) {
+				return len(list)
+			}
+			return i
+		}
+
+		s, e := bound(start, 0), bound(end, len(list))
+		if s >= e {
+			return nil
+		}
+		return list[s:e]
+	}
+}
+
+var filterOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseFilter parses the inside of [?(...)]: a path on its own, true if it
+// finds anything, or a path compared to a literal or another path
+func parseFilter(expr string) (jpStep, error) {
+	var left, op, right string
+	var quote byte
+	for i := 0; (i < len(expr)) && (op == ""); i++ {
+		switch c := expr[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		case (c == '"') || (c == '\''):
+			quote = c
+			continue
+		}
+		for _, o := range filterOps {
+			if strings.HasPrefix(expr[i:], o) {
+				left, op, right = expr[:i], o, expr[i+len(o):]
+				break
+			}
+		}
+	}
+	if op == "" {
+		left = expr
+	}
+
+	lp, err := parsePath(strings.TrimSpace(left))
+	if err != nil {
+		return nil, err
+	}
+
+	var value func(root interface{}, current interface{}) []interface{}
+	if op != "" {
+		right = strings.TrimSpace(right)
+		if right == "" {
+			return nil, fmt.Errorf("jsonpath: nothing to compare with in '%s'", expr)
+		}
+		lit, isLit, err := parseLiteral(right)
+		if err != nil {
+			return nil, err
+		}
+		if isLit {
+			value = func(interface{}, interface{}) []interface{} { return []interface{}{lit} }
+		} else {
+			rp, err := parsePath(right)
+			if err != nil {
+				return nil, err
+			}
+			value = rp.eval
+		}
+	}
+
+	return func(v interface{}) []interface{} {
+		ret := make([]interface{}, 0)
+		for _, item := range children(v) {
+			found := lp.eval(item, item)
+			if op == "" {
+				if len(found) > 0 {
+					ret = append(ret, item)
+				}
+				continue
+			}
+			if (len(found) == 1) && compare(found[0], op, value(item, item)) {
+				ret = append(ret, item)
+			}
+		}
+		return ret
+	}, nil
+}
+
+func parseLiteral(s string) (interface{}, bool, error) {
+	switch {
+	case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'"):
+		str, err := unquote(s)
+		return str, true, err
+	case s == "true":
+		return true, true, nil
+	case s == "false":
+		return false, true, nil
+	case s == "null":
+		return nil, true, nil
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return json.Number(s), true, nil
+	}
+	return nil, false, nil
+}
+
+func compare(left interface{}, op string, rights []interface{}) bool {
+	if len(rights) != 1 {
+		return false
+	}
+	right := rights[0]
+
+	ln, lok := jpNumber(left)
+	rn, rok := jpNumber(right)
+	if lok && rok {
+		switch op {
+		case "==":
+			return ln == rn
+		case "!=":
+			return ln != rn
+		case "<":
+			return ln < rn
+		case ">":
+			return ln > rn
+		case "<=":
+			return ln <= rn
+		case ">=":
+			return ln >= rn
+		}
+	}
+
+	ls, rs := jpString(left), jpString(right)
+	switch op {
+	case "==":
+		return ls == rs
+	case "!=":
+		return ls != rs
+	case "<":
+		return ls < rs
+	case ">":
+		return ls > rs
+	case "<=":
+		return ls <= rs
+	case ">=":
+		return ls >= rs
+	}
+	return false
+}
+
+func jpNumber(v interface{}) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+// eval finds the values the path leads to
+func (p *jpPath) eval(root interface{}, current interface{}) []interface{} {
+	start := current
+	if p.fromRoot {
+		start = root
+	}
+
+	values := []interface{}{start}
+	for _, step := range p.steps {
+		next := make([]interface{}, 0)
+		for _, v := range values {
+			next = append(next, step(v)...)
+		}
+		values = next
+	}
+	return values
+}
@@ -0,0 +1,126 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+const jsonPathData = `{
+	"workspace": "GLOBAL",
+	"devices": [
+		{"id": "SERIAL1", "health": "pass", "uptime": 10, "tags": {"role": "storage"}, "location": {"rack": "A01"}},
+		{"id": "SERIAL2", "health": "FAIL", "uptime": 200, "tags": {"role": "compute", "a.b": "dotted", "it's": "quoted"}},
+		{"id": "SERIAL3", "health": "FAIL", "uptime": 3000, "phase": "production", "location": {"rack": "B02"}}
+	]
+}`
+
+func decodeJSONPathData(t *testing.T) interface{} {
+	dec := json.NewDecoder(strings.NewReader(jsonPathData))
+	dec.UseNumber()
+
+	var data interface{}
+	st.Expect(t, dec.Decode(&data), nil)
+	return data
+}
+
+func TestJSONPath(t *testing.T) {
+	data := decodeJSONPathData(t)
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"Text", "just text", "just text"},
+		{"Field", "{.workspace}", "GLOBAL"},
+		{"BareField", "{workspace}", "GLOBAL"},
+		{"Nested", "{.devices[0].tags.role}", "storage"},
+		{"Index", "{.devices[1].id}", "SERIAL2"},
+		{"NegativeIndex", "{.devices[-1].id}", "SERIAL3"},
+		{"IndexOutOfRange", "{.devices[7].id}", ""},
+		{"Slice", "{.devices[0:2].id}", "SERIAL1 SERIAL2"},
+		{"OpenSlice", "{.devices[1:].id}", "SERIAL2 SERIAL3"},
+		{"NegativeSlice", "{.devices[-2:].id}", "SERIAL2 SERIAL3"},
+		{"WildcardList", "{.devices[*].id}", "SERIAL1 SERIAL2 SERIAL3"},
+		{"WildcardObject", "{.devices[0].location.*}", "A01"},
+		{"WildcardSorted", "{.devices[1].tags.*}", "dotted quoted compute"},
+		{"Descendants", "{..rack}", "A01 B02"},
+		{"QuotedKey", "{.devices[1].tags['a.b']}", "dotted"},
+		{"DoubleQuotedKey", `{.devices[1].tags["a.b"]}`, "dotted"},
+		{"EscapedQuote", `{.devices[1].tags['it\'s']}`, "quoted"},
+		{"Number", "{.devices[2].uptime}", "3000"},
+		{"Object", "{.devices[0].location}", `{"rack":"A01"}`},
+		{"MissingKey", "{.nope}", ""},
+		{"MissingNestedKey", "{.devices[0].phase.name}", ""},
+		{"FieldOfString", "{.workspace.name}", ""},
+		{"Literal", `{.workspace}{"\t"}{'x'}{"\n"}`, "GLOBAL\tx\n"},
+		{"BraceInLiteral", `{"}"}`, "}"},
+
+		{"FilterEquals", `{.devices[?(@.health=="FAIL")].id}`, "SERIAL2 SERIAL3"},
+		{"FilterSingleQuotes", `{.devices[?(@.health=='pass')].id}`, "SERIAL1"},
+		{"FilterNotEquals", `{.devices[?(@.health!="FAIL")].id}`, "SERIAL1"},
+		{"FilterNumber", `{.devices[?(@.uptime>100)].id}`, "SERIAL2 SERIAL3"},
+		{"FilterNumberNotText", `{.devices[?(@.uptime<30)].id}`, "SERIAL1"},
+		{"FilterAtMost", `{.devices[?(@.uptime<=200)].id}`, "SERIAL1 SERIAL2"},
+		{"FilterExists", `{.devices[?(@.phase)].id}`, "SERIAL3"},
+		{"FilterMissing", `{.devices[?(@.phase=="production")].id}`, "SERIAL3"},
+		{"FilterPath", `{.devices[?(@.id==@.id)].health}`, "pass FAIL FAIL"},
+		{"FilterQuotedKey", `{.devices[?(@.tags['a.b']=="dotted")].id}`, "SERIAL2"},
+		{"FilterOperatorInString", `{.devices[?(@.id!="a<b")].id}`, "SERIAL1 SERIAL2 SERIAL3"},
+
+		{"Range", `{range .devices[*]}{.id}:{.health};{end}`, "SERIAL1:pass;SERIAL2:FAIL;SERIAL3:FAIL;"},
+		{"RangeOverList", `{range .devices}{.id},{end}`, "SERIAL1,SERIAL2,SERIAL3,"},
+		{"RangeRoot", `{range .devices[0:2]}{$.workspace}/{.id} {end}`, "GLOBAL/SERIAL1 GLOBAL/SERIAL2 "},
+		{"RangeFilter", `{range .devices[?(@.health=="FAIL")]}{.id}{"\n"}{end}`, "SERIAL2\nSERIAL3\n"},
+		{"NestedRange", `{range .devices[1:]}{range .location.*}{.}{end};{end}`, ";B02;"},
+		{"RangeOverNothing", `{range .nope}x{end}`, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := parseJSONPath(test.tmpl)
+			st.Expect(t, err, nil)
+
+			var out bytes.Buffer
+			st.Expect(t, p.execute(&out, data), nil)
+			st.Expect(t, out.String(), test.want)
+		})
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	tests := map[string]string{
+		"UnclosedBrace":     "{.id",
+		"UnclosedQuote":     `{"abc}`,
+		"UnclosedBracket":   "{.devices[0}",
+		"EndWithoutRange":   "{end}",
+		"RangeWithoutEnd":   "{range .devices[*]}{.id}",
+		"TooManyEnds":       "{range .a}{end}{end}",
+		"BadSubscript":      "{.devices[one]}",
+		"BadSlice":          "{.devices[a:b]}",
+		"EmptyDescendant":   "{..}",
+		"BadLiteral":        `{"\q"}`,
+		"BadQuotedKey":      `{.a["\q"]}`,
+		"BadFilter":         `{.devices[?(@.id=="\q")]}`,
+		"BadFilterPath":     `{.devices[?(@.id==)]}`,
+		"BadPathInRange":    "{range .a[}{end}",
+		"UnexpectedBracket": "{]}",
+	}
+
+	for name, tmpl := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseJSONPath(tmpl)
+			st.Reject(t, err, nil)
+		})
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ListOptions pick, order, and cut down the lists that commands print.
+//
+// Names are those of a table's columns in snake case, like "asset_tag" for
+// "Asset Tag", or else JSON fields of the items the rows were made from,
+// with dots for nested ones, like "location.rack.name". That way, every
+// field of a device or a rack is a column, without the command having to
+// know about it.
+type ListOptions struct {
+	// Columns to show, in order. Empty means those of the command.
+	Columns []string
+
+	// SortBy names what to sort by. Empty means the command's own order.
+	SortBy string
+
+	// Desc reverses the order
+	Desc bool
+
+	// Where has the conditions that every item listed must meet
+	Where []Condition
+}
+
+// List holds --columns, --sort-by, --desc, and --where
+var List ListOptions
+
+// SetListOptions sets List from the command line
+func SetListOptions(columns string, sortBy string, desc bool, where []string) error {
+	l := ListOptions{SortBy: strings.TrimSpace(sortBy), Desc: desc}
+
+	for _, c := range strings.Split(columns, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			l.Columns = append(l.Columns, c)
+		}
+	}
+
+	for _, w := range where {
+		cond, err := ParseCondition(w)
+		if err != nil {
+			return err
+		}
+		l.Where = append(l.Where, cond)
+	}
+
+	List = l
+	return nil
+}
+
+func (l ListOptions) active() bool {
+	return (len(l.Columns) > 0) || (l.SortBy != "") || l.Desc || (len(l.Where) > 0)
+}
+
+// Condition is a --where, like "health=FAIL"
+type Condition struct {
+	Name  string
+	Op    string
+	Value string
+
+	re *regexp.Regexp
+}
+
+// conditionOps are the operators of a Condition, longest first so that
+// "<=" isn't taken for "<", nor "==" for "=" with a value of "=..."
+var conditionOps = []string{"==", "!=", "<=", ">=", "=", "<", ">", "~"}
+
+// ParseCondition parses a condition like "health=FAIL". "=", or "==", and
+// "!=" ignore case. "<", "<=", ">", and ">=" compare numbers as numbers, and
+// anything else as text. "~" matches a regular expression.
+func ParseCondition(s string) (Condition, error) {
+	for i := 0; i < len(s); i++ {
+		for _, op := range conditionOps {
+			if !strings.HasPrefix(s[i:], op) {
+				continue
+			}
+
+			c := Condition{
+				Name:  strings.TrimSpace(s[:i]),
+				Op:    op,
+				Value: strings.TrimSpace(s[i+len(op):]),
+			}
+			if op == "==" {
+				c.Op = "="
+			}
+			if c.Name == "" {
+				return c, fmt.Errorf("condition '%s' does not name a column", s)
+			}
+			if op == "~" {
+				re, err := regexp.Compile(c.Value)
+				if err != nil {
+					return c, fmt.Errorf("condition '%s': %s", s, err)
+				}
+				c.re = re
+			}
+			return c, nil
+		}
+	}
+
+	return Condition{}, fmt.Errorf(
+		"condition '%s' has no operator. Try something like 'health=FAIL'",
+		s,
+	)
+}
+
+func (c Condition) matches(value string) bool {
+	switch c.Op {
+	case "=":
+		return equalValues(value, c.Value)
+	case "!=":
+		return !equalValues(value, c.Value)
+	case "<":
+		return compareValues(value, c.Value) < 0
+	case "<=":
+		return compareValues(value, c.Value) <= 0
+	case ">":
+		return compareValues(value, c.Value) > 0
+	case ">=":
+		return compareValues(value, c.Value) >= 0
+	case "~":
+		return c.re.MatchString(value)
+	}
+	return false
+}
+
+func equalValues(a string, b string) bool {
+	if an, bn, ok := numbers(a, b); ok {
+		return an == bn
+	}
+	return strings.EqualFold(a, b)
+}
+
+func compareValues(a string, b string) int {
+	if an, bn, ok := numbers(a, b); ok {
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+func numbers(a string, b string) (float64, float64, bool) {
+	an, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	bn, err := strconv.ParseFloat(b, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return an, bn, true
+}
+
+// listRow is a row of a Table on its way through ListOptions
+type listRow struct {
+	cells []string
+	item  interface{}
+
+	data    interface{} // item, as decoded from its JSON
+	decoded bool
+}
+
+// lister looks up values in the rows of a table
+type lister struct {
+	keys  []string
+	index map[string]int
+	paths map[string]*jpPath
+
+	found  map[string]bool     // names that led to something in any row
+	fields map[string]struct{} // of the items, to suggest
+}
+
+func newLister(keys []string) *lister {
+	l := &lister{
+		keys:   keys,
+		index:  make(map[string]int),
+		paths:  make(map[string]*jpPath),
+		found:  make(map[string]bool),
+		fields: make(map[string]struct{}),
+	}
+	for i, k := range keys {
+		l.index[k] = i
+	}
+	return l
+}
+
+// value finds the named column of the row, or else the field of its item
+func (l *lister) value(row *listRow, name string) (string, error) {
+	if i, ok := l.index[name]; ok {
+		l.found[name] = true
+		if i < len(row.cells) {
+			return row.cells[i], nil
+		}
+		return "", nil
+	}
+
+	if row.item == nil {
+		return "", l.unknown(name)
+	}
+
+	p, ok := l.paths[name]
+	if !ok {
+		var err error
+		if p, err = parsePath(name); err != nil {
+			return "", err
+		}
+		l.paths[name] = p
+	}
+
+	if !row.decoded {
+		j, err := json.Marshal(row.item)
+		if err != nil {
+			return "", err
+		}
+		dec := json.NewDecoder(bytes.NewReader(j))
+		dec.UseNumber()
+		if err := dec.Decode(&row.data); err != nil {
+			return "", err
+		}
+		row.decoded = true
+
+		if obj, ok := row.data.(map[string]interface{}); ok {
+			for k := range obj {
+				l.fields[k] = struct{}{}
+			}
+		}
+	}
+
+	results := p.eval(row.data, row.data)
+	if len(results) > 0 {
+		l.found[name] = true
+	}
+	strs := make([]string, 0, len(results))
+	for _, r := range results {
+		strs = append(strs, jpString(r))
+	}
+	return strings.Join(strs, " "), nil
+}
+
+// check fails if a name led nowhere in any of the rows, which is more
+// likely a typo than a field that is never set
+func (l *lister) check(names ...string) error {
+	for _, name := range names {
+		if !l.found[name] {
+			return l.unknown(name)
+		}
+	}
+	return nil
+}
+
+func (l *lister) unknown(name string) error {
+	known := append([]string{}, l.keys...)
+	for f := range l.fields {
+		if _, ok := l.index[f]; !ok {
+			known = append(known, f)
+		}
+	}
+	sort.Strings(known[len(l.keys):])
+
+	return fmt.Errorf(
+		"unknown column '%s'. Columns are: %s",
+		name,
+		strings.Join(known, ", "),
+	)
+}
+
+// apply returns the table with its rows filtered and sorted, and its columns
+// picked, according to the options
+func (t *Table) apply(opts ListOptions) (*Table, error) {
+	if !opts.active() {
+		return t, nil
+	}
+
+	l := newLister(t.columnKeys())
+
+	rows := make([]*listRow, 0, len(t.rows))
+	for i, cells := range t.rows {
+		row := &listRow{cells: cells}
+		if i < len(t.items) {
+			row.item = t.items[i]
+		}
+
+		keep := true
+		for _, cond := range opts.Where {
+			v, err := l.value(row, cond.Name)
+			if err != nil {
+				return nil, err
+			}
+			if !cond.matches(v) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			rows = append(rows, row)
+		}
+	}
+
+	if opts.SortBy != "" {
+		values := make(map[*listRow]string, len(rows))
+		for _, row := range rows {
+			v, err := l.value(row, opts.SortBy)
+			if err != nil {
+				return nil, err
+			}
+			values[row] = v
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			c := compareValues(values[rows[i]], values[rows[j]])
+			if opts.Desc {
+				return c > 0
+			}
+			return c < 0
+		})
+	} else if opts.Desc {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(t.rows) > 0 {
+		names := make([]string, 0)
+		for _, cond := range opts.Where {
+			names = append(names, cond.Name)
+		}
+		if opts.SortBy != "" {
+			names = append(names, opts.SortBy)
+		}
+		if err := l.check(names...); err != nil {
+			return nil, err
+		}
+	}
+
+	out := &Table{header: t.header, keys: t.keys}
+	if len(opts.Columns) > 0 {
+		out.header = make([]string, 0, len(opts.Columns))
+		for _, name := range opts.Columns {
+			if i, ok := l.index[name]; ok && (i < len(t.header)) {
+				out.header = append(out.header, t.header[i])
+			} else {
+				out.header = append(out.header, name)
+			}
+		}
+		out.keys = opts.Columns
+	}
+
+	for _, row := range rows {
+		cells := row.cells
+		if len(opts.Columns) > 0 {
+			cells = make([]string, 0, len(opts.Columns))
+			for _, name := range opts.Columns {
+				v, err := l.value(row, name)
+				if err != nil {
+					return nil, err
+				}
+				cells = append(cells, v)
+			}
+		}
+		out.AppendItem(row.item, cells)
+	}
+	if len(rows) > 0 {
+		if err := l.check(opts.Columns...); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// applyList runs a list of data, like the []conch.Device a command prints,
+// through List. Filtered and sorted, it comes back as the same type. With
+// columns to pick, there is a Table of them instead.
+func applyList(thingy interface{}) (interface{}, *Table, error) {
+	v := reflect.ValueOf(thingy)
+	if !List.active() || ((v.Kind() != reflect.Slice) && (v.Kind() != reflect.Array)) {
+		return thingy, nil, nil
+	}
+
+	t := NewTable()
+	for i := 0; i < v.Len(); i++ {
+		t.AppendItem(v.Index(i).Interface(), nil)
+	}
+	if len(List.Columns) > 0 {
+		return nil, t, nil
+	}
+
+	picked, err := t.apply(List)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	elem := v.Type().Elem()
+	out := reflect.MakeSlice(reflect.SliceOf(elem), 0, len(picked.items))
+	for _, item := range picked.items {
+		if item == nil {
+			out = reflect.Append(out, reflect.Zero(elem))
+		} else {
+			out = reflect.Append(out, reflect.ValueOf(item))
+		}
+	}
+	return out.Interface(), nil, nil
+}
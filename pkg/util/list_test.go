@@ -0,0 +1,289 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+type listItem struct {
+	ID       string            `json:"id"`
+	Health   string            `json:"health"`
+	Uptime   int               `json:"uptime"`
+	Location map[string]string `json:"location"`
+}
+
+// listTable is a table of devices, with a column of its own for the
+// hostname that the items don't have
+func listTable() *Table {
+	items := []listItem{
+		{"SERIAL1", "pass", 10, map[string]string{"rack": "A01"}},
+		{"SERIAL2", "FAIL", 200, map[string]string{"rack": "B02"}},
+		{"SERIAL3", "fail", 3000, map[string]string{"rack": "A01"}},
+		{"SERIAL4", "pass", 9, map[string]string{"rack": "C03"}},
+	}
+	hostnames := []string{"web1", "db1", "web2", "=web3"}
+
+	t := NewTable()
+	t.SetHeader([]string{"ID", "Health", "Host Name"})
+	for i, item := range items {
+		t.AppendItem(item, []string{item.ID, item.Health, hostnames[i]})
+	}
+	return t
+}
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		in    string
+		name  string
+		op    string
+		value string
+	}{
+		{"health=FAIL", "health", "=", "FAIL"},
+		{"health==FAIL", "health", "=", "FAIL"},
+		{" health == FAIL ", "health", "=", "FAIL"},
+		{"health!=FAIL", "health", "!=", "FAIL"},
+		{"uptime<=10", "uptime", "<=", "10"},
+		{"uptime>=10", "uptime", ">=", "10"},
+		{"uptime<10", "uptime", "<", "10"},
+		{"uptime>10", "uptime", ">", "10"},
+		{"hostname~^web", "hostname", "~", "^web"},
+		{"hostname===web", "hostname", "=", "=web"},
+		{"location.rack=A01", "location.rack", "=", "A01"},
+		{"note=a=b", "note", "=", "a=b"},
+		{"note=", "note", "=", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			c, err := ParseCondition(test.in)
+			st.Expect(t, err, nil)
+			st.Expect(t, c.Name, test.name)
+			st.Expect(t, c.Op, test.op)
+			st.Expect(t, c.Value, test.value)
+		})
+	}
+
+	for _, bad := range []string{"health", "", "=FAIL", "==FAIL", "hostname~[web"} {
+		t.Run("Bad "+bad, func(t *testing.T) {
+			_, err := ParseCondition(bad)
+			st.Reject(t, err, nil)
+		})
+	}
+}
+
+func TestListOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		sortBy  string
+		desc    bool
+		where   []string
+
+		header []string
+		rows   [][]string
+	}{
+		{
+			name:   "Nothing",
+			header: []string{"ID", "Health", "Host Name"},
+			rows: [][]string{
+				{"SERIAL1", "pass", "web1"},
+				{"SERIAL2", "FAIL", "db1"},
+				{"SERIAL3", "fail", "web2"},
+				{"SERIAL4", "pass", "=web3"},
+			},
+		},
+		{
+			name:    "Columns",
+			columns: "host_name, id",
+			header:  []string{"Host Name", "ID"},
+			rows: [][]string{
+				{"web1", "SERIAL1"},
+				{"db1", "SERIAL2"},
+				{"web2", "SERIAL3"},
+				{"=web3", "SERIAL4"},
+			},
+		},
+		{
+			name:    "ColumnsFromItems",
+			columns: "id,location.rack,uptime",
+			header:  []string{"ID", "location.rack", "uptime"},
+			rows: [][]string{
+				{"SERIAL1", "A01", "10"},
+				{"SERIAL2", "B02", "200"},
+				{"SERIAL3", "A01", "3000"},
+				{"SERIAL4", "C03", "9"},
+			},
+		},
+		{
+			name:    "SortByText",
+			columns: "host_name",
+			sortBy:  "host_name",
+			header:  []string{"Host Name"},
+			rows:    [][]string{{"=web3"}, {"db1"}, {"web1"}, {"web2"}},
+		},
+		{
+			name:    "SortByNumber",
+			columns: "id",
+			sortBy:  "uptime",
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL4"}, {"SERIAL1"}, {"SERIAL2"}, {"SERIAL3"}},
+		},
+		{
+			name:    "SortIsStable",
+			columns: "id",
+			sortBy:  "location.rack",
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL1"}, {"SERIAL3"}, {"SERIAL2"}, {"SERIAL4"}},
+		},
+		{
+			name:    "Desc",
+			columns: "id",
+			sortBy:  "uptime",
+			desc:    true,
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL3"}, {"SERIAL2"}, {"SERIAL1"}, {"SERIAL4"}},
+		},
+		{
+			name:    "DescWithoutSort",
+			columns: "id",
+			desc:    true,
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL4"}, {"SERIAL3"}, {"SERIAL2"}, {"SERIAL1"}},
+		},
+		{
+			name:    "WhereIgnoresCase",
+			columns: "id",
+			where:   []string{"health=fail"},
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL2"}, {"SERIAL3"}},
+		},
+		{
+			name:    "WhereDoubleEquals",
+			columns: "id",
+			where:   []string{"health==FAIL"},
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL2"}, {"SERIAL3"}},
+		},
+		{
+			name:    "WhereValueWithEquals",
+			columns: "id",
+			where:   []string{"host_name===web3"},
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL4"}},
+		},
+		{
+			name:    "WhereNotEquals",
+			columns: "id",
+			where:   []string{"health!=FAIL"},
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL1"}, {"SERIAL4"}},
+		},
+		{
+			name:    "WhereNumbers",
+			columns: "id",
+			where:   []string{"uptime>9", "uptime<=200"},
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL1"}, {"SERIAL2"}},
+		},
+		{
+			name:    "WhereRegexp",
+			columns: "id",
+			where:   []string{"host_name~^web"},
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL1"}, {"SERIAL3"}},
+		},
+		{
+			name:    "WhereNested",
+			columns: "id",
+			where:   []string{"location.rack=a01"},
+			header:  []string{"ID"},
+			rows:    [][]string{{"SERIAL1"}, {"SERIAL3"}},
+		},
+		{
+			name:   "Everything",
+			sortBy: "uptime",
+			desc:   true,
+			where:  []string{"location.rack=A01"},
+			header: []string{"ID", "Health", "Host Name"},
+			rows: [][]string{
+				{"SERIAL3", "fail", "web2"},
+				{"SERIAL1", "pass", "web1"},
+			},
+		},
+	}
+
+	defer func() { List = ListOptions{} }()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st.Expect(t, SetListOptions(test.columns, test.sortBy, test.desc, test.where), nil)
+
+			out, err := listTable().apply(List)
+			st.Expect(t, err, nil)
+			st.Expect(t, out.header, test.header)
+			st.Expect(t, out.rows, test.rows)
+		})
+	}
+}
+
+func TestListOptionsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		sortBy  string
+		where   []string
+	}{
+		{name: "UnknownColumn", columns: "id,nope"},
+		{name: "UnknownSort", sortBy: "nope"},
+		{name: "UnknownWhere", where: []string{"nope=1"}},
+		{name: "BadPath", columns: "location["},
+	}
+
+	defer func() { List = ListOptions{} }()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st.Expect(t, SetListOptions(test.columns, test.sortBy, false, test.where), nil)
+
+			_, err := listTable().apply(List)
+			st.Reject(t, err, nil)
+		})
+	}
+
+	st.Reject(t, SetListOptions("", "", false, []string{"health"}), nil)
+}
+
+func TestApplyList(t *testing.T) {
+	items := []listItem{
+		{ID: "SERIAL1", Uptime: 10},
+		{ID: "SERIAL2", Uptime: 200},
+		{ID: "SERIAL3", Uptime: 3000},
+	}
+
+	defer func() { List = ListOptions{} }()
+
+	st.Expect(t, SetListOptions("", "uptime", true, []string{"uptime>=200"}), nil)
+	out, table, err := applyList(items)
+	st.Expect(t, err, nil)
+	st.Expect(t, table == nil, true)
+	st.Expect(t, out, []listItem{items[2], items[1]})
+
+	st.Expect(t, SetListOptions("id", "", false, nil), nil)
+	_, table, err = applyList(items)
+	st.Expect(t, err, nil)
+	st.Reject(t, table == nil, true)
+
+	// Anything but a list is left alone
+	st.Expect(t, SetListOptions("", "uptime", false, nil), nil)
+	out, table, err = applyList(items[0])
+	st.Expect(t, err, nil)
+	st.Expect(t, table == nil, true)
+	st.Expect(t, out, items[0])
+}
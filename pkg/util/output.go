@@ -0,0 +1,414 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Formats understood by --output
+const (
+	OutputTable  = "table"
+	OutputWide   = "wide"
+	OutputJSON   = "json"
+	OutputYAML   = "yaml"
+	OutputCSV    = "csv"
+	OutputTSV    = "tsv"
+	OutputNDJSON = "ndjson"
+
+	// These take an argument, as in "template={{.ID}}"
+	OutputTemplate = "template"
+	OutputJSONPath = "jsonpath"
+)
+
+// OutputFormats lists the formats understood by --output
+var OutputFormats = []string{
+	OutputTable,
+	OutputWide,
+	OutputJSON,
+	OutputYAML,
+	OutputCSV,
+	OutputTSV,
+	OutputNDJSON,
+}
+
+// Output is the format picked with --output. Use SetOutput to change it.
+var Output = OutputTable
+
+var (
+	outputTemplate *template.Template
+	outputJSONPath *jsonPath
+)
+
+// templateFuncs are available to --output template, on top of the ones
+// text/template provides
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		j, err := json.Marshal(v)
+		return string(j), err
+	},
+}
+
+// SetOutput picks the output format. The structured formats, JSON, YAML,
+// NDJSON, and the template and JSONPath ones, also set JSON, which keeps
+// commands from printing anything but their data.
+//
+// Templates use text/template, on the same values that JSON output is made
+// from. JSONPath expressions work on the JSON itself, in the style of
+// kubectl's.
+func SetOutput(format string) error {
+	if format == "" {
+		format = OutputTable
+	}
+
+	if bits := strings.SplitN(format, "=", 2); len(bits) == 2 {
+		var err error
+
+		switch bits[0] {
+		case OutputTemplate:
+			outputTemplate, err = template.New("output").Funcs(templateFuncs).Parse(bits[1])
+		case OutputJSONPath:
+			outputJSONPath, err = parseJSONPath(bits[1])
+		default:
+			return fmt.Errorf("unknown output format '%s'", bits[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		Output = bits[0]
+		JSON = true
+		return nil
+	}
+
+	for _, f := range OutputFormats {
+		if f == format {
+			Output = format
+			JSON = Structured()
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"unknown output format '%s'. Known formats are: %s, template=TEMPLATE, and jsonpath=EXPRESSION",
+		format,
+		strings.Join(OutputFormats, ", "),
+	)
+}
+
+// Structured reports whether the output format is data, rather than rows
+// and columns
+func Structured() bool {
+	switch Output {
+	case OutputJSON, OutputYAML, OutputNDJSON, OutputTemplate, OutputJSONPath:
+		return true
+	}
+	return false
+}
+
+// Wide reports whether tables ought to show everything they can, like with
+// --full
+func Wide() bool {
+	return Output == OutputWide
+}
+
+// Print writes out the data of a command in the structured output format.
+// For any other format, it writes JSON, like JSONOut. Lists go through
+// List first.
+func Print(thingy interface{}) {
+	thingy, table, err := applyList(thingy)
+	if err != nil {
+		Bail(err)
+	}
+	if table != nil {
+		table.Render()
+		return
+	}
+
+	if err := writeData(os.Stdout, thingy); err != nil {
+		Bail(err)
+	}
+}
+
+func writeData(w io.Writer, thingy interface{}) error {
+	if Output == OutputTemplate {
+		return outputTemplate.Execute(w, thingy)
+	}
+
+	j, err := json.Marshal(thingy)
+	if err != nil {
+		return err
+	}
+
+	switch Output {
+	case OutputYAML:
+		return writeYAML(w, j)
+	case OutputNDJSON:
+		return writeNDJSON(w, j)
+	case OutputJSONPath:
+		dec := json.NewDecoder(bytes.NewReader(j))
+		dec.UseNumber()
+
+		var data interface{}
+		if err := dec.Decode(&data); err != nil {
+			return err
+		}
+		return outputJSONPath.execute(w, data)
+	}
+
+	_, err = fmt.Fprintln(w, string(j))
+	return err
+}
+
+// writeNDJSON writes each item of a JSON array on a line of its own.
+// Anything but an array is a single line.
+func writeNDJSON(w io.Writer, j []byte) error {
+	if !bytes.HasPrefix(bytes.TrimSpace(j), []byte("[")) {
+		_, err := fmt.Fprintln(w, string(j))
+		return err
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(j, &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if _, err := fmt.Fprintln(w, string(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAML converts JSON to YAML. Going by way of JSON keeps the field
+// names and formats the API uses, and the order of the fields.
+func writeYAML(w io.Writer, j []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+
+	y, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(y)
+	return err
+}
+
+// decodeOrdered decodes the next JSON value, with objects as yaml.MapSlice
+// so they keep their order
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			obj := yaml.MapSlice{}
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				val, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj = append(obj, yaml.MapItem{Key: key, Value: val})
+			}
+			_, err := dec.Token()
+			return obj, err
+
+		case '[':
+			arr := make([]interface{}, 0)
+			for dec.More() {
+				val, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, val)
+			}
+			_, err := dec.Token()
+			return arr, err
+		}
+
+	case json.Number:
+		if i, err := tok.Int64(); err == nil {
+			return i, nil
+		}
+		return tok.Float64()
+	}
+
+	return tok, nil
+}
+
+// Table collects rows of output. It renders them as a markdown table, as
+// CSV or TSV, or as a list of objects keyed by the header in snake case for
+// the structured formats, depending on Output. Its methods mirror those of the
+// tablewriter GetMarkdownTable returns.
+//
+// Rows added with AppendItem remember what they were made from, which lets
+// --columns, --sort-by, and --where use any of its fields, not just the
+// columns of the table. See ListOptions.
+type Table struct {
+	header []string
+	keys   []string // of the columns, if not the header in snake case
+	rows   [][]string
+	items  []interface{}
+}
+
+// NewTable returns an empty Table
+func NewTable() *Table {
+	return &Table{}
+}
+
+// SetHeader names the columns
+func (t *Table) SetHeader(header []string) {
+	t.header = header
+}
+
+// Append adds a row
+func (t *Table) Append(row []string) {
+	t.AppendItem(nil, row)
+}
+
+// AppendItem adds a row made from item, usually a struct from the API
+func (t *Table) AppendItem(item interface{}, row []string) {
+	t.rows = append(t.rows, row)
+	t.items = append(t.items, item)
+}
+
+// Render writes out the table in the output format, with its rows picked,
+// sorted, and cut down according to List
+func (t *Table) Render() {
+	t, err := t.apply(List)
+	if err != nil {
+		Bail(err)
+	}
+
+	switch Output {
+	case OutputCSV:
+		err = t.writeDelimited(os.Stdout, ',')
+	case OutputTSV:
+		err = t.writeDelimited(os.Stdout, '\t')
+	case OutputTemplate:
+		err = writeData(os.Stdout, t.maps())
+	case OutputJSON, OutputYAML, OutputNDJSON, OutputJSONPath:
+		err = writeData(os.Stdout, t.records())
+	default:
+		table := GetMarkdownTable()
+		table.SetHeader(t.header)
+		table.AppendBulk(t.rows)
+		table.Render()
+	}
+
+	if err != nil {
+		Bail(err)
+	}
+}
+
+func (t *Table) writeDelimited(w io.Writer, comma rune) error {
+	out := csv.NewWriter(w)
+	out.Comma = comma
+
+	if len(t.header) > 0 {
+		if err := out.Write(t.header); err != nil {
+			return err
+		}
+	}
+	if err := out.WriteAll(t.rows); err != nil {
+		return err
+	}
+	return out.Error()
+}
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// columnKeys names the columns for --columns and the structured formats:
+// the header in snake case, like "asset_tag" for "Asset Tag"
+func (t *Table) columnKeys() []string {
+	if t.keys != nil {
+		return t.keys
+	}
+
+	keys := make([]string, len(t.header))
+	for i, h := range t.header {
+		keys[i] = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(h), "_"), "_")
+	}
+	return keys
+}
+
+// maps turns the rows into maps keyed by the header, for templates
+func (t *Table) maps() []map[string]string {
+	keys := t.columnKeys()
+	maps := make([]map[string]string, 0, len(t.rows))
+	for _, row := range t.rows {
+		m := make(map[string]string, len(keys))
+		for i, key := range keys {
+			if i < len(row) {
+				m[key] = row[i]
+			}
+		}
+		maps = append(maps, m)
+	}
+	return maps
+}
+
+// records turns the rows into objects keyed by the header, in snake case
+func (t *Table) records() []tableRecord {
+	keys := t.columnKeys()
+	records := make([]tableRecord, 0, len(t.rows))
+	for _, row := range t.rows {
+		records = append(records, tableRecord{keys: keys, values: row})
+	}
+	return records
+}
+
+// tableRecord is a row of a Table as a JSON object, in column order
+type tableRecord struct {
+	keys   []string
+	values []string
+}
+
+func (r tableRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		value := ""
+		if i < len(r.values) {
+			value = r.values[i]
+		}
+		v, _ := json.Marshal(value)
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
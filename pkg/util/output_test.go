@@ -0,0 +1,188 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joyent/conch-shell/pkg/conch"
+	"github.com/nbio/st"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/output")
+
+// captureStdout returns what f writes to os.Stdout
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	st.Expect(t, err, nil)
+
+	out := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	f()
+	w.Close()
+	return <-out
+}
+
+// withOutput runs f with the given output format and list options, and puts
+// them back afterwards
+func withOutput(t *testing.T, format string, list ListOptions, f func()) {
+	defer func() {
+		Output, JSON, List = OutputTable, false, ListOptions{}
+		outputTemplate, outputJSONPath = nil, nil
+	}()
+
+	st.Expect(t, SetOutput(format), nil)
+	List = list
+	f()
+}
+
+// golden compares got with the contents of testdata/output/name.golden, or
+// writes them with -update
+func golden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "output", name+".golden")
+
+	if *update {
+		st.Expect(t, os.MkdirAll(filepath.Dir(path), 0755), nil)
+		st.Expect(t, ioutil.WriteFile(path, []byte(got), 0644), nil)
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	st.Expect(t, err, nil)
+	if got != string(want) {
+		t.Errorf("%s does not match:\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// awkwardTable has cells that need quoting as CSV or TSV
+func awkwardTable() *Table {
+	t := NewTable()
+	t.SetHeader([]string{"ID", "Asset Tag", "Note"})
+	t.Append([]string{"1", "plain", "nothing to see"})
+	t.Append([]string{"2", "a,b", `say "hi"`})
+	t.Append([]string{"3", "tab\there", "two\nlines"})
+	return t
+}
+
+func testDevices() []conch.Device {
+	created := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	return []conch.Device{
+		{
+			ID:       "SERIAL1",
+			AssetTag: "tag,1",
+			Created:  created,
+			LastSeen: created.Add(time.Hour),
+			Health:   "pass",
+			Phase:    "integration",
+			Location: conch.DeviceLocation{
+				Rack: conch.Rack{Name: "A01"},
+			},
+		},
+		{
+			ID:        "SERIAL2",
+			Created:   created,
+			Health:    "fail",
+			Validated: created.Add(2 * time.Hour),
+			Phase:     "production",
+			Location: conch.DeviceLocation{
+				Rack: conch.Rack{Name: "A01"},
+			},
+		},
+	}
+}
+
+func TestTableOutput(t *testing.T) {
+	formats := []string{
+		OutputTable,
+		OutputCSV,
+		OutputTSV,
+		OutputJSON,
+		OutputYAML,
+		OutputNDJSON,
+		"template={{range .}}{{.id}}: {{.note}};{{end}}",
+		"jsonpath={range .[*]}{.asset_tag}|{end}",
+	}
+	names := []string{"table", "csv", "tsv", "json", "yaml", "ndjson", "template", "jsonpath"}
+
+	for i, format := range formats {
+		t.Run(names[i], func(t *testing.T) {
+			withOutput(t, format, ListOptions{}, func() {
+				golden(t, "table-"+names[i], captureStdout(t, awkwardTable().Render))
+			})
+		})
+	}
+}
+
+func TestDataOutput(t *testing.T) {
+	data := map[string]interface{}{
+		"id":    "SERIAL1",
+		"tags":  []string{"a", "b"},
+		"count": 3,
+		"note":  `say "hi"`,
+	}
+
+	for _, format := range []string{OutputJSON, OutputYAML, OutputNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			withOutput(t, format, ListOptions{}, func() {
+				golden(t, "data-list-"+format, captureStdout(t, func() {
+					Print([]interface{}{data, data})
+				}))
+				golden(t, "data-item-"+format, captureStdout(t, func() {
+					Print(data)
+				}))
+			})
+		})
+	}
+}
+
+func TestDisplayDevices(t *testing.T) {
+	for _, format := range []string{OutputTable, OutputCSV, OutputJSON, OutputNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			withOutput(t, format, ListOptions{}, func() {
+				golden(t, "devices-"+format, captureStdout(t, func() {
+					st.Expect(t, DisplayDevices(testDevices(), false), nil)
+				}))
+			})
+		})
+	}
+
+	// The JSON keeps its shape, but the list options see the whole of the
+	// devices
+	t.Run("ListOptions", func(t *testing.T) {
+		list := ListOptions{SortBy: "health"}
+		cond, err := ParseCondition("location.rack.name=a01")
+		st.Expect(t, err, nil)
+		list.Where = []Condition{cond}
+
+		withOutput(t, OutputJSON, list, func() {
+			golden(t, "devices-json-sorted", captureStdout(t, func() {
+				st.Expect(t, DisplayDevices(testDevices(), false), nil)
+			}))
+		})
+
+		list.Columns = []string{"id", "location.rack.name"}
+		withOutput(t, OutputCSV, list, func() {
+			golden(t, "devices-csv-columns", captureStdout(t, func() {
+				st.Expect(t, DisplayDevices(testDevices(), false), nil)
+			}))
+		})
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bowery/prompt"
+	"github.com/joyent/conch-shell/pkg/config/secrets"
+)
+
+// SecretsPassphraseEnv names the environment variable that, if set,
+// provides the passphrase of the encrypted secrets file instead of a prompt
+const SecretsPassphraseEnv = "CONCH_SECRETS_PASSPHRASE"
+
+// SecretStores returns the secret stores for the config at the given path.
+// The encrypted file lives next to the config, so ~/.conch.json keeps its
+// secrets in ~/.conch-secrets.json.
+func SecretStores(configPath string) *secrets.Stores {
+	return &secrets.Stores{
+		FilePath:   strings.TrimSuffix(configPath, filepath.Ext(configPath)) + "-secrets.json",
+		Passphrase: secretsPassphrase,
+	}
+}
+
+func secretsPassphrase(create bool) (string, error) {
+	if p := os.Getenv(SecretsPassphraseEnv); p != "" {
+		return p, nil
+	}
+
+	// A prompt would hang the shell waiting on the completion
+	if Completing {
+		return "", errors.New("cannot ask for the secrets passphrase while completing")
+	}
+
+	if create {
+		fmt.Fprintln(os.Stderr, "Creating an encrypted file for API tokens. Choose a passphrase to protect it.")
+	}
+	p, err := prompt.Password("Secrets passphrase:")
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("a passphrase is required")
+	}
+
+	if create {
+		again, err := prompt.Password("Again:")
+		if err != nil {
+			return "", err
+		}
+		if again != p {
+			return "", errors.New("the passphrases do not match")
+		}
+	}
+	return p, nil
+}
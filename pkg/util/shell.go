@@ -0,0 +1,65 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	cli "github.com/jawher/mow.cli"
+	"github.com/joyent/conch-shell/pkg/config"
+)
+
+var (
+	// Interactive is set while the interactive shell runs commands. Exit
+	// then ends the command rather than the shell, and the connection to
+	// the API is kept from one command to the next.
+	Interactive bool
+
+	// Completing is set while conch completes a command line for the shell
+	// it was started from. Nothing may prompt then, and Exit ends the
+	// lookup rather than the process.
+	Completing bool
+
+	// ProfileOverride is the name of the profile picked with --profile, if
+	// any
+	ProfileOverride string
+)
+
+var (
+	// apiProfile is the profile API was built for. apiReady is set once
+	// its version was checked, and loggedIn once its login was verified.
+	apiProfile *config.ConchProfile
+	apiReady   bool
+	loggedIn   bool
+)
+
+// ExitCode is what Exit panics with in the interactive shell, for the shell
+// to recover from
+type ExitCode int
+
+// Exit ends the command with the given exit code, giving the After hooks of
+// mow.cli a chance to run. Outside of the shell, or completion, that ends
+// the process.
+func Exit(code int) {
+	if Interactive || Completing {
+		panic(ExitCode(code))
+	}
+	cli.Exit(code)
+}
+
+// ForgetAPI drops the connection to the API and picks the profile to use
+// again. The shell does this after commands that may have changed profiles
+// or credentials, so that the next command starts afresh.
+func ForgetAPI() {
+	API = nil
+	apiProfile = nil
+	apiReady = false
+	loggedIn = false
+
+	if !IgnoreConfig {
+		Token = ""
+		SelectProfile(ProfileOverride)
+	}
+}
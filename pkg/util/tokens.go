@@ -0,0 +1,76 @@
+// Copyright Joyent, Inc.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package util
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// DefaultTokenExpiryWarning is how many days ahead of its expiry the shell
+// starts warning about the active profile's API token
+const DefaultTokenExpiryWarning = 14
+
+// TokenExpiryWarning is how many days ahead of its expiry to warn about the
+// active profile's API token. Zero turns the warning off.
+var TokenExpiryWarning = DefaultTokenExpiryWarning
+
+// NewTokenName returns a name for an API token created by the shell, unique
+// to this user, machine, and moment
+func NewTokenName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return fmt.Sprintf("%d@%s || %d", os.Getuid(), hostname, time.Now().UnixNano())
+}
+
+// WarnTokenExpiry complains on stderr if the active profile's API token has
+// expired, or will within TokenExpiryWarning days. Only tokens the shell
+// created, and so knows the name of, are checked. Their expiry is looked up
+// once and then remembered in the profile.
+func WarnTokenExpiry() {
+	if (TokenExpiryWarning <= 0) || IgnoreConfig || (ActiveProfile == nil) {
+		return
+	}
+	p := ActiveProfile
+	if (p.Token == "") || (p.TokenName == "") {
+		return
+	}
+
+	if p.TokenExpires.IsZero() {
+		// Older servers can't tell us, which is no reason to fail
+		token, err := API.GetMyToken(p.TokenName)
+		if (err != nil) || token.Expires.IsZero() {
+			return
+		}
+		p.TokenExpires = token.Expires
+		WriteConfig()
+	}
+
+	left := time.Until(p.TokenExpires)
+	if left > time.Duration(TokenExpiryWarning)*24*time.Hour {
+		return
+	}
+
+	when := "expired"
+	if left > 0 {
+		when = fmt.Sprintf("expires in %d day(s)", int(math.Ceil(left.Hours()/24)))
+	}
+	fmt.Fprintf(
+		os.Stderr,
+		"WARNING: the API token '%s' of profile '%s' %s, on %s. "+
+			"Run 'conch profile rotate-token' to replace it.\n",
+		p.TokenName,
+		p.Name,
+		when,
+		TimeStr(p.TokenExpires),
+	)
+}
@@ -11,7 +11,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -19,7 +22,6 @@ import (
 	"github.com/Bowery/prompt"
 	"github.com/blang/semver"
 	"github.com/davecgh/go-spew/spew"
-	cli "github.com/jawher/mow.cli"
 	"github.com/joyent/conch-shell/pkg/conch"
 	"github.com/joyent/conch-shell/pkg/config"
 	"github.com/olekukonko/tablewriter"
@@ -29,7 +31,9 @@ var (
 	// UserAgent will be used as the http user agent when making API calls
 	UserAgent string
 
-	// JSON tells us if we should output JSON
+	// JSON tells us if we should output data rather than tables and
+	// chatter. It is set by --json, and by SetOutput for the structured
+	// formats.
 	JSON bool
 
 	IgnoreConfig bool
@@ -54,6 +58,19 @@ var (
 	// Trace decides if we should trace the HTTP transactions
 	// Yes, this is a bit of a kludge
 	Trace bool
+
+	// NoCache turns off the API response cache
+	NoCache bool
+
+	// RecordPath, if set, is where API requests and responses get recorded
+	RecordPath string
+
+	// ReplayPath, if set, is a recording to answer API requests from
+	ReplayPath string
+
+	// TransportFlags are connection settings from the command line. They
+	// win over the profile's.
+	TransportFlags config.TransportSettings
 )
 
 // These variables are provided by the build environment
@@ -81,7 +98,10 @@ func NoAdmin() bool {
 }
 
 func init() {
-	SemVersion = CleanVersion(Version)
+	// Tests, and builds outside of the Makefile, have no version
+	if Version != "" {
+		SemVersion = CleanVersion(Version)
+	}
 }
 
 // DateFormat should be used in date formatting calls to ensure uniformity of
@@ -100,13 +120,19 @@ func TimeStr(t time.Time) string {
 // BuildAPIAndVerifyLogin builds a Conch object using the Config data and calls
 // VerifyLogin
 func BuildAPIAndVerifyLogin() {
+	if Interactive && loggedIn && (apiProfile == ActiveProfile) {
+		return
+	}
+
 	BuildAPI()
 
 	if Token != "" {
 		ok, err := API.VerifyToken()
+		WarnTokenExpiry()
 		if !ok {
 			Bail(err)
 		}
+		loggedIn = true
 		return
 	}
 
@@ -114,8 +140,9 @@ func BuildAPIAndVerifyLogin() {
 		Bail(err)
 	}
 
-	ActiveProfile.JWT = API.JWT
+	ActiveProfile.JWT = API.CurrentJWT()
 	WriteConfig()
+	loggedIn = true
 }
 
 // WriteConfig serializes the Config struct to disk
@@ -135,34 +162,115 @@ func WriteConfigForce() {
 	}
 }
 
+// SelectProfile sets ActiveProfile to the named profile, or to the one marked
+// active if name is empty, and loads its secrets. ActiveProfile is left nil
+// if there is no such profile.
+func SelectProfile(name string) {
+	ActiveProfile = nil
+	for _, prof := range Config.Profiles {
+		if name != "" {
+			if prof.Name == name {
+				ActiveProfile = prof
+				break
+			}
+		} else if prof.Active {
+			ActiveProfile = prof
+			break
+		}
+	}
+
+	if ActiveProfile == nil {
+		return
+	}
+
+	// Without its secrets, the profile can still be logged into again, so
+	// this is no reason to stop
+	if err := Config.LoadSecrets(ActiveProfile); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", err)
+	}
+	if ActiveProfile.Token != "" {
+		Token = string(ActiveProfile.Token)
+	}
+}
+
 // BuildAPI builds a Conch object
 func BuildAPI() {
+	// The shell keeps its connection for as long as the profile stays the
+	// same
+	if Interactive && apiReady && (apiProfile == ActiveProfile) {
+		return
+	}
+	apiReady = false
+	loggedIn = false
+
+	opts := []conch.Option{
+		conch.WithDebug(Debug),
+		conch.WithTrace(Trace),
+	}
+
 	if IgnoreConfig {
-		API = &conch.Conch{
-			BaseURL: BaseURL,
-			Debug:   Debug,
-			Trace:   Trace,
-			Token:   Token,
-		}
+		opts = append(opts,
+			conch.WithBaseURL(BaseURL),
+			conch.WithToken(Token),
+		)
+		opts = append(opts, ConnectionOptions(nil)...)
 
 	} else {
 		if ActiveProfile == nil {
 			Bail(errors.New("no active profile. Please use 'conch profile' to create or set an active profile"))
 		}
 
-		API = &conch.Conch{
-			BaseURL: ActiveProfile.BaseURL,
-			JWT:     ActiveProfile.JWT,
-			Token:   string(ActiveProfile.Token),
-			Debug:   Debug,
-			Trace:   Trace,
-		}
+		opts = append(opts,
+			conch.WithBaseURL(ActiveProfile.BaseURL),
+			conch.WithJWT(ActiveProfile.JWT),
+			conch.WithToken(string(ActiveProfile.Token)),
+		)
+		opts = append(opts, ConnectionOptions(ActiveProfile)...)
 	}
 
 	if UserAgent != "" {
-		API.UA = UserAgent
+		opts = append(opts, conch.WithUserAgent(UserAgent))
 	}
 
+	// A cache hit would leave a hole in the recording, or answer where the
+	// recording should
+	if (RecordPath != "") || (ReplayPath != "") {
+		NoCache = true
+	}
+
+	if !NoCache {
+		opts = append(opts, conch.WithCache(buildCache()))
+		if Completing {
+			opts = append(opts, conch.WithCacheTTLs(completionCacheTTLs()))
+		}
+	}
+
+	if RecordPath != "" {
+		f, err := os.Create(RecordPath)
+		if err != nil {
+			Bail(err)
+		}
+		opts = append(opts, conch.WithRecorder(conch.NewRecorder(f)))
+	}
+
+	if ReplayPath != "" {
+		cassette, err := conch.LoadCassette(ReplayPath)
+		if err != nil {
+			Bail(err)
+		}
+		opts = append(opts, conch.WithCassette(cassette))
+	}
+
+	API = conch.New(opts...)
+	checkAPIVersion()
+
+	apiProfile = ActiveProfile
+	apiReady = true
+}
+
+// checkAPIVersion makes sure the API server speaks a version of the API we
+// understand
+func checkAPIVersion() {
 	version, err := API.GetVersion()
 	if err != nil {
 		Bail(err)
@@ -172,29 +280,137 @@ func BuildAPI() {
 		return
 	}
 
-	sem := CleanVersion(version)
-	minSem := CleanVersion(conch.MinimumAPIVersion)
-	maxSem := CleanVersion(conch.BreakingAPIVersion)
-
-	if sem.Major != minSem.Major {
+	sem, err := conch.ParseAPIVersion(version)
+	if err != nil {
 		Bail(fmt.Errorf(
-			"cannot continue. the major version of API server '%s' is '%d' and we require '%d'",
+			"cannot continue. the API server '%s' reports an unknown version '%s'",
 			API.BaseURL,
-			sem.Major,
-			minSem.Major,
+			version,
 		))
 	}
+	oldestSem := CleanVersion(conch.OldestAPIVersion)
+	maxSem := CleanVersion(conch.BreakingAPIVersion)
 
-	if sem.LT(minSem) || sem.GTE(maxSem) {
+	if sem.LT(oldestSem) || sem.GTE(maxSem) {
 		Bail(fmt.Errorf(
 			"cannot continue. the API server version '%s' is '%s' and we require >= %s and < %s",
 			API.BaseURL,
 			sem,
-			minSem,
+			oldestSem,
 			maxSem,
 		))
+	}
+
+	// Older servers work, minus whatever they lack. Those commands fail
+	// with ErrNotSupported.
+	missing := make([]string, 0)
+	for capability := range conch.Capabilities {
+		if !API.Supports(capability) {
+			missing = append(missing, string(capability))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		fmt.Fprintf(
+			os.Stderr,
+			"WARNING: the API server '%s' runs version %s, which lacks %s. "+
+				"Commands that need them will not be available.\n",
+			API.BaseURL,
+			sem,
+			strings.Join(missing, ", "),
+		)
+	}
+}
+
+// ConnectionOptions returns the client options for reaching the API of the
+// given profile: its TLS and transport settings, with TransportFlags layered
+// on top. The profile may be nil, leaving only the flags.
+func ConnectionOptions(p *config.ConchProfile) []conch.Option {
+	opts := make([]conch.Option, 0)
+
+	settings := TransportFlags
+	if p != nil {
+		settings = p.TransportSettings.Merge(TransportFlags)
+
+		if tlsOpts := p.TLSOptions(); !tlsOpts.IsZero() {
+			cfg, err := tlsOpts.Config()
+			if err != nil {
+				Bail(fmt.Errorf("profile '%s': %s", p.Name, err))
+			}
+			opts = append(opts, conch.WithTLSConfig(cfg))
+		}
+	}
 
+	if !settings.IsZero() {
+		transport, err := settings.TransportOptions()
+		if err != nil {
+			Bail(err)
+		}
+		opts = append(opts, conch.WithTransportOptions(transport))
 	}
+
+	return opts
+}
+
+// buildCache returns the on-disk API cache for the active profile. Without a
+// profile, or if there's nowhere to put the cache, responses are only cached
+// for the life of the process.
+func buildCache() conch.Cache {
+	if IgnoreConfig || (ActiveProfile == nil) {
+		return conch.NewMemoryCache()
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return conch.NewMemoryCache()
+	}
+
+	// Completion keeps lists that commands would rather have fresh, so
+	// they're kept apart
+	name := "conch"
+	if Completing {
+		name = "conch-completion"
+	}
+
+	return conch.DiskCache{
+		Dir: filepath.Join(dir, name, url.PathEscape(ActiveProfile.Name)),
+	}
+}
+
+// CompletionCacheTTL is how long the lists completion offers names and IDs
+// from are kept, so that pressing tab again doesn't ask the API again
+const CompletionCacheTTL = time.Minute
+
+// completionRoutes are the lists completion offers names and IDs from
+var completionRoutes = []string{
+	"/dc",
+	"/hardware_product",
+	"/hardware_vendor",
+	"/rack",
+	"/rack_role",
+	"/relay",
+	"/room",
+	"/validation",
+	"/validation_plan",
+	"/workspace",
+	"/workspace/:id/device",
+	"/workspace/:id/rack",
+	"/workspace/:id/relay",
+}
+
+// completionCacheTTLs are conch.DefaultCacheTTLs, plus CompletionCacheTTL
+// for the lists completion uses that aren't kept for longer already
+func completionCacheTTLs() map[string]time.Duration {
+	ttls := make(map[string]time.Duration, len(conch.DefaultCacheTTLs)+len(completionRoutes))
+	for route, ttl := range conch.DefaultCacheTTLs {
+		ttls[route] = ttl
+	}
+	for _, route := range completionRoutes {
+		if ttls[route] < CompletionCacheTTL {
+			ttls[route] = CompletionCacheTTL
+		}
+	}
+	return ttls
 }
 
 // GetMarkdownTable returns a tablewriter configured to output markdown
@@ -211,27 +427,32 @@ func GetMarkdownTable() (table *tablewriter.Table) {
 func Bail(err error) {
 	var msg string
 
-	switch err {
-	case conch.ErrBadInput:
+	switch {
+	case errors.Is(err, conch.ErrBadInput):
 		msg = err.Error() + " -- Internal Error. Please run with --debug and file a Github Issue"
 
-	case conch.ErrNotAuthorized:
+	case errors.Is(err, conch.ErrNotAuthorized):
 		if len(Token) > 0 {
 			msg = err.Error() + " -- The API token might be incorrect or revoked"
 		} else {
 			msg = err.Error() + " -- Running 'profile relogin' might resolve this"
 		}
 
-	case conch.ErrMalformedJWT:
+	case errors.Is(err, conch.ErrMalformedJWT):
 		msg = "The server sent a malformed auth token. Please contact the Conch team"
 
-	case conch.ErrLoginFailed:
+	case errors.Is(err, conch.ErrLoginFailed):
 		msg = "Something unexpected happened during authentication. Please run with --debug and contact the Conch team"
 
 	default:
 		msg = err.Error()
 	}
 
+	var aerr *conch.APIError
+	if errors.As(err, &aerr) && aerr.StatusCode >= 500 && aerr.RequestID != "" {
+		msg = msg + " (request id: " + aerr.RequestID + ")"
+	}
+
 	if JSON {
 		j, _ := json.Marshal(struct {
 			Error   bool   `json:"error"`
@@ -246,25 +467,37 @@ func Bail(err error) {
 		fmt.Println(msg)
 	}
 
-	cli.Exit(1)
+	Exit(1)
 }
 
 // DisplayDevices is an abstraction to make sure that the output of
 // Devices is uniform, be it tables, json, or full json
 func DisplayDevices(devices []conch.Device, fullOutput bool) (err error) {
+	if Wide() {
+		fullOutput = true
+	}
+
 	if fullOutput {
-		filledIn := make([]conch.Device, 0)
+		// The table renderer only needs the location data so there's no
+		// need to go get a full DetailedDevice with its attendant database
+		// queries.
+		// In my experience, getting the full DetailedDevice doubles this
+		// query time [sungo]
+		ids := make([]string, 0)
 		for _, d := range devices {
 			if d.Location.Rack.Name == "" {
-				// The table renderer only needs the location data so there's no
-				// need to go get a full DetailedDevice with its attendant database
-				// queries.
-				// In my experience, getting the full DetailedDevice doubles this
-				// query time [sungo]
-				loc, err := API.GetDeviceLocation(d.ID)
-				if err != nil {
-					return err
-				}
+				ids = append(ids, d.ID)
+			}
+		}
+
+		locs, err := API.GetDeviceLocations(ids)
+		if err != nil {
+			return err
+		}
+
+		filledIn := make([]conch.Device, 0)
+		for _, d := range devices {
+			if loc, ok := locs[d.ID]; ok {
 				d.Location = loc
 			}
 			filledIn = append(filledIn, d)
@@ -274,7 +507,18 @@ func DisplayDevices(devices []conch.Device, fullOutput bool) (err error) {
 
 	if JSON {
 		if fullOutput {
-			JSONOut(devices)
+			Print(devices)
+			return nil
+		}
+
+		// --columns, --sort-by, and --where go by the devices themselves,
+		// so that all their fields can be used, not just those written out
+		picked, table, err := applyList(devices)
+		if err != nil {
+			Bail(err)
+		}
+		if table != nil {
+			table.Render()
 			return nil
 		}
 
@@ -282,7 +526,7 @@ func DisplayDevices(devices []conch.Device, fullOutput bool) (err error) {
 		// AZ and Rack were not ported over since they are always zero-value
 		// without fullOutput
 		output := make([]interface{}, 0)
-		for _, d := range devices {
+		for _, d := range picked.([]conch.Device) {
 			output = append(output, struct {
 				ID        string    `json:"id"`
 				AssetTag  string    `json:"asset_tag"`
@@ -304,11 +548,13 @@ func DisplayDevices(devices []conch.Device, fullOutput bool) (err error) {
 			})
 		}
 
-		JSONOut(output)
+		if err := writeData(os.Stdout, output); err != nil {
+			Bail(err)
+		}
 		return nil
 	}
 
-	table := GetMarkdownTable()
+	table := NewTable()
 
 	if fullOutput {
 		table.SetHeader([]string{
@@ -352,7 +598,7 @@ func DisplayDevices(devices []conch.Device, fullOutput bool) (err error) {
 		}
 
 		if fullOutput {
-			table.Append([]string{
+			table.AppendItem(d, []string{
 				d.Location.Room.AZ,
 				d.Location.Rack.Name,
 				d.ID,
@@ -365,7 +611,7 @@ func DisplayDevices(devices []conch.Device, fullOutput bool) (err error) {
 				d.Phase,
 			})
 		} else {
-			table.Append([]string{
+			table.AppendItem(d, []string{
 				d.ID,
 				d.AssetTag,
 				TimeStr(d.Created.UTC()),
@@ -452,7 +698,7 @@ func InteractiveForcePasswordChange() {
 		Bail(err)
 	}
 
-	ActiveProfile.JWT = API.JWT
+	ActiveProfile.JWT = API.CurrentJWT()
 
 	WriteConfigForce()
 }